@@ -11,14 +11,165 @@ import (
 	"time"
 
 	"foodcook/internal/app/handlers"
+	"foodcook/internal/app/middleware"
 	"foodcook/internal/app/routes"
+	domainrepos "foodcook/internal/domain/repositories"
 	"foodcook/internal/infrastructure/repositories"
+	"foodcook/internal/pkg/cache"
 	"foodcook/internal/pkg/config"
 	"foodcook/internal/pkg/database"
+	"foodcook/internal/pkg/recipe"
+	"foodcook/internal/pkg/storage"
 
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
+// repositorySet 按数据库驱动创建的一组仓储
+type repositorySet struct {
+	user       domainrepos.UserRepository
+	dish       domainrepos.DishRepository
+	ingredient domainrepos.IngredientRepository
+	mealRecord domainrepos.MealRecordRepository
+	category   domainrepos.CategoryRepository
+	tag        domainrepos.TagRepository
+	preference domainrepos.PreferenceRepository
+
+	refreshToken    domainrepos.RefreshTokenRepository
+	tokenRevocation domainrepos.TokenRevocationStore
+
+	household domainrepos.HouseholdRepository
+	role      domainrepos.RoleRepository
+	upload    domainrepos.UploadRepository
+	mealPlan  domainrepos.MealPlanRepository
+
+	shoppingList domainrepos.ShoppingListRepository
+	pantry       domainrepos.PantryRepository
+	stats        domainrepos.StatsRepository
+	budget       domainrepos.BudgetRepository
+}
+
+func newRepositorySet(driver string, db *gorm.DB) *repositorySet {
+	switch driver {
+	case config.DriverMemory:
+		store := repositories.NewMemoryStore()
+		return &repositorySet{
+			user:       repositories.NewMemoryUserRepository(store),
+			dish:       repositories.NewMemoryDishRepository(store),
+			ingredient: repositories.NewMemoryIngredientRepository(store),
+			mealRecord: repositories.NewMemoryMealRecordRepository(store),
+			category:   repositories.NewMemoryCategoryRepository(store),
+			tag:        repositories.NewMemoryTagRepository(store),
+			preference: repositories.NewMemoryPreferenceRepository(store),
+
+			refreshToken:    repositories.NewMemoryRefreshTokenRepository(store),
+			tokenRevocation: repositories.NewMemoryTokenRevocationStore(store),
+
+			household: repositories.NewMemoryHouseholdRepository(store),
+			role:      repositories.NewMemoryRoleRepository(store),
+			upload:    repositories.NewMemoryUploadRepository(store),
+			mealPlan:  repositories.NewMemoryMealPlanRepository(store),
+
+			shoppingList: repositories.NewMemoryShoppingListRepository(store),
+			pantry:       repositories.NewMemoryPantryRepository(store),
+			stats:        repositories.NewMemoryStatsRepository(store),
+			budget:       repositories.NewMemoryBudgetRepository(store),
+		}
+	case config.DriverSQLite:
+		return &repositorySet{
+			user:       repositories.NewSQLiteUserRepository(db),
+			dish:       repositories.NewSQLiteDishRepository(db),
+			ingredient: repositories.NewSQLiteIngredientRepository(db),
+			mealRecord: repositories.NewSQLiteMealRecordRepository(db),
+			category:   repositories.NewSQLiteCategoryRepository(db),
+			tag:        repositories.NewSQLiteTagRepository(db),
+			preference: repositories.NewSQLitePreferenceRepository(db),
+
+			refreshToken:    repositories.NewSQLiteRefreshTokenRepository(db),
+			tokenRevocation: repositories.NewSQLiteTokenRevocationStore(db),
+
+			household: repositories.NewSQLiteHouseholdRepository(db),
+			role:      repositories.NewSQLiteRoleRepository(db),
+			upload:    repositories.NewSQLiteUploadRepository(db),
+			mealPlan:  repositories.NewSQLiteMealPlanRepository(db),
+
+			shoppingList: repositories.NewSQLiteShoppingListRepository(db),
+			pantry:       repositories.NewSQLitePantryRepository(db),
+			stats:        repositories.NewSQLiteStatsRepository(db),
+			budget:       repositories.NewSQLiteBudgetRepository(db),
+		}
+	default:
+		return &repositorySet{
+			user:       repositories.NewMySQLUserRepository(db),
+			dish:       repositories.NewMySQLDishRepository(db),
+			ingredient: repositories.NewMySQLIngredientRepository(db),
+			mealRecord: repositories.NewMySQLMealRecordRepository(db),
+			category:   repositories.NewMySQLCategoryRepository(db),
+			tag:        repositories.NewMySQLTagRepository(db),
+			preference: repositories.NewMySQLPreferenceRepository(db),
+
+			refreshToken:    repositories.NewMySQLRefreshTokenRepository(db),
+			tokenRevocation: repositories.NewMySQLTokenRevocationStore(db),
+
+			household: repositories.NewMySQLHouseholdRepository(db),
+			role:      repositories.NewMySQLRoleRepository(db),
+			upload:    repositories.NewMySQLUploadRepository(db),
+			mealPlan:  repositories.NewMySQLMealPlanRepository(db),
+
+			shoppingList: repositories.NewMySQLShoppingListRepository(db),
+			pantry:       repositories.NewMySQLPantryRepository(db),
+			stats:        repositories.NewMySQLStatsRepository(db),
+			budget:       repositories.NewMySQLBudgetRepository(db),
+		}
+	}
+}
+
+// newCache 优先使用 Redis，未启用或不可用时退化为进程内 LRU
+func newCache(cfg config.CacheConfig) cache.Cache {
+	if client := database.GetRedisClient(); client != nil {
+		logrus.Info("Using redis cache")
+		return cache.NewRedisCache(client)
+	}
+	logrus.Infof("Using local LRU cache (capacity %d)", cfg.LocalSize)
+	return cache.NewLRUCache(cfg.LocalSize)
+}
+
+// newStorage 按配置创建上传文件存储
+func newStorage(ctx context.Context, cfg config.UploadConfig) (storage.Storage, error) {
+	if cfg.Storage == config.StorageS3 {
+		return storage.NewS3Storage(ctx, storage.S3Options{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			UseSSL:    cfg.S3.UseSSL,
+		})
+	}
+	return storage.NewLocalStorage(cfg.UploadPath)
+}
+
+// collectOrphanUploads 定期清理未被引用的上传文件，直到 ctx 取消
+func collectOrphanUploads(ctx context.Context, uploadHandler *handlers.UploadHandler, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := uploadHandler.CollectOrphans(ctx)
+			if err != nil {
+				logrus.Warnf("Failed to collect orphan uploads: %v", err)
+			}
+			if removed > 0 {
+				logrus.Infof("Removed %d orphan uploads", removed)
+			}
+		}
+	}
+}
+
 func main() {
 	// 加载配置
 	if err := config.LoadConfig(); err != nil {
@@ -49,31 +200,98 @@ func main() {
 		log.Printf("Warning: Failed to seed data: %v", err)
 	}
 
-	// 初始化Redis
-	if err := database.InitRedis(); err != nil {
-		log.Fatalf("Failed to initialize redis: %v", err)
+	// 初始化Redis（可选，连接失败时使用本地缓存）
+	if cfg.Redis.Enabled {
+		if err := database.InitRedis(); err != nil {
+			logrus.Warnf("Redis unavailable, falling back to local cache: %v", err)
+		}
+		defer database.CloseRedis()
 	}
-	defer database.CloseRedis()
-
-	// 获取数据库连接
-	db := database.GetDB()
 
 	// 创建仓储层
-	userRepo := repositories.NewMySQLUserRepository(db)
-	dishRepo := repositories.NewMySQLDishRepository(db)
-	ingredientRepo := repositories.NewMySQLIngredientRepository(db)
-	mealRecordRepo := repositories.NewMySQLMealRecordRepository(db)
-	categoryRepo := repositories.NewMySQLCategoryRepository(db)
+	repos := newRepositorySet(cfg.Database.Driver, database.GetDB())
+	userRepo := repos.user
+	dishRepo := repos.dish
+	ingredientRepo := repos.ingredient
+	mealRecordRepo := repos.mealRecord
+	categoryRepo := repos.category
+	tagRepo := repos.tag
+	householdRepo := repos.household
+	roleRepo := repos.role
+
+	// 令牌吊销记录优先保存在 Redis
+	tokenRevocation := repos.tokenRevocation
+	if client := database.GetRedisClient(); client != nil {
+		tokenRevocation = repositories.NewRedisTokenRevocationStore(client)
+	}
+
+	// 缓存层
+	var appCache cache.Cache
+	if cfg.Cache.Enabled {
+		appCache = newCache(cfg.Cache)
+		ttl := time.Duration(cfg.Cache.TTL) * time.Second
+		dishRepo = repositories.NewCachedDishRepository(dishRepo, appCache, ttl)
+		categoryRepo = repositories.NewCachedCategoryRepository(categoryRepo, appCache, ttl)
+		ingredientRepo = repositories.NewCachedIngredientRepository(ingredientRepo, appCache)
+		tagRepo = repositories.NewCachedTagRepository(tagRepo, appCache)
+	}
+
+	// 内存存储不经过 GORM，需要通过仓储插入初始数据
+	if cfg.Database.Driver == config.DriverMemory {
+		if err := database.SeedRepositories(context.Background(), userRepo, categoryRepo, dishRepo, ingredientRepo); err != nil {
+			log.Printf("Warning: Failed to seed data: %v", err)
+		}
+	}
+
+	if err := database.SeedRoles(context.Background(), roleRepo); err != nil {
+		log.Printf("Warning: Failed to seed roles: %v", err)
+	}
+
+	// 为旧用户创建个人家庭并迁移其用餐记录
+	if err := database.MigratePersonalHouseholds(context.Background(), userRepo, householdRepo); err != nil {
+		log.Printf("Warning: Failed to migrate households: %v", err)
+	}
+	if err := database.MigrateMealRecordPhotos(context.Background(), mealRecordRepo); err != nil {
+		log.Printf("Warning: Failed to migrate meal record photos: %v", err)
+	}
+
+	// 权限按访问令牌缓存，使用独立的缓存实例，命中统计不计入 /api/cache/stats；
+	// 启用 Redis 缓存时同样保存在 Redis 中，多个实例之间的失效保持同步
+	var permissionCache cache.Cache = cache.NewLRUCache(cfg.Cache.LocalSize)
+	if client := database.GetRedisClient(); cfg.Cache.Enabled && client != nil {
+		permissionCache = cache.NewRedisCache(client)
+	}
+	permissionResolver := middleware.NewPermissionResolver(userRepo, roleRepo, permissionCache)
+
+	// 上传文件存储
+	fileStorage, err := newStorage(context.Background(), cfg.Upload)
+	if err != nil {
+		log.Fatalf("Failed to initialize upload storage: %v", err)
+	}
+	logrus.Infof("Using %s upload storage", fileStorage.Backend())
 
 	// 创建处理器
-	authHandler := handlers.NewAuthHandler(userRepo)
-	dishHandler := handlers.NewDishHandler(dishRepo)
-	ingredientHandler := handlers.NewIngredientHandler(ingredientRepo)
-	mealRecordHandler := handlers.NewMealRecordHandler(mealRecordRepo, dishRepo)
+	authHandler := handlers.NewAuthHandler(userRepo, repos.refreshToken, tokenRevocation, householdRepo)
+	dishHandler := handlers.NewDishHandler(dishRepo, ingredientRepo, tagRepo, repos.preference, recipe.NewFetcher(nil))
+	ingredientHandler := handlers.NewIngredientHandler(ingredientRepo, dishRepo)
+	mealRecordHandler := handlers.NewMealRecordHandler(mealRecordRepo, dishRepo, householdRepo, repos.budget, repos.stats, categoryRepo)
 	categoryHandler := handlers.NewCategoryHandler(categoryRepo)
+	tagHandler := handlers.NewTagHandler(tagRepo)
+	preferenceHandler := handlers.NewPreferenceHandler(repos.preference, dishRepo, mealRecordRepo, householdRepo)
+	cacheHandler := handlers.NewCacheHandler(appCache)
+	householdHandler := handlers.NewHouseholdHandler(householdRepo)
+	adminHandler := handlers.NewAdminHandler(roleRepo, userRepo, permissionResolver)
+	uploadHandler := handlers.NewUploadHandler(repos.upload, fileStorage, cfg.Upload)
+	mealPlanHandler := handlers.NewMealPlanHandler(repos.mealPlan, mealRecordRepo, dishRepo, householdRepo)
+	shoppingListHandler := handlers.NewShoppingListHandler(repos.shoppingList, dishRepo, repos.mealPlan, repos.pantry, householdRepo)
+	pantryHandler := handlers.NewPantryHandler(repos.pantry, ingredientRepo, householdRepo)
+	recommendationHandler := handlers.NewRecommendationHandler(dishRepo, repos.preference, mealRecordRepo, repos.pantry, householdRepo)
+	statsHandler := handlers.NewStatsHandler(repos.stats, householdRepo)
+	budgetHandler := handlers.NewBudgetHandler(repos.budget, repos.stats, categoryRepo, householdRepo)
+	menuHandler := handlers.NewMenuHandler(dishRepo, categoryRepo, tagRepo, mealRecordRepo, repos.mealPlan, householdRepo)
 
 	// 设置路由
-	r := routes.SetupRoutes(authHandler, dishHandler, ingredientHandler, mealRecordHandler, categoryHandler)
+	r := routes.SetupRoutes(authHandler, dishHandler, ingredientHandler, mealRecordHandler, categoryHandler, tagHandler, preferenceHandler, cacheHandler, householdHandler, adminHandler, uploadHandler, mealPlanHandler, shoppingListHandler, pantryHandler, recommendationHandler, menuHandler, statsHandler, budgetHandler, permissionResolver, tokenRevocation)
 
 	// 创建HTTP服务器
 	srv := &http.Server{
@@ -81,6 +299,13 @@ func main() {
 		Handler: r,
 	}
 
+	// 后台清理未被引用的上传文件
+	gcCtx, stopGC := context.WithCancel(context.Background())
+	defer stopGC()
+	if cfg.Upload.GCIntervalMinutes > 0 {
+		go collectOrphanUploads(gcCtx, uploadHandler, time.Duration(cfg.Upload.GCIntervalMinutes)*time.Minute)
+	}
+
 	// 启动服务器
 	go func() {
 		logrus.Infof("Server starting on port %d", cfg.App.Port)
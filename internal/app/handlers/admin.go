@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"foodcook/internal/app/middleware"
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler 角色与用户权限管理
+type AdminHandler struct {
+	roleRepo           repositories.RoleRepository
+	userRepo           repositories.UserRepository
+	permissionResolver *middleware.PermissionResolver
+}
+
+func NewAdminHandler(roleRepo repositories.RoleRepository, userRepo repositories.UserRepository, permissionResolver *middleware.PermissionResolver) *AdminHandler {
+	return &AdminHandler{
+		roleRepo:           roleRepo,
+		userRepo:           userRepo,
+		permissionResolver: permissionResolver,
+	}
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type UpdateRoleRequest struct {
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type PermissionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// validatePermissions 校验权限列表，通配权限只允许内置 root 角色使用
+func validatePermissions(permissions []string) (string, bool) {
+	for _, permission := range permissions {
+		if permission == models.PermissionAll || !models.IsValidPermission(permission) {
+			return permission, false
+		}
+	}
+	return "", true
+}
+
+func (h *AdminHandler) ListPermissions(c *gin.Context) {
+	permissions := make([]PermissionInfo, 0, len(models.Permissions))
+	for name, description := range models.Permissions {
+		permissions = append(permissions, PermissionInfo{Name: name, Description: description})
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": permissions,
+	})
+}
+
+func (h *AdminHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleRepo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取角色列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": roles,
+	})
+}
+
+func (h *AdminHandler) CreateRole(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if invalid, ok := validatePermissions(req.Permissions); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的权限: " + invalid})
+		return
+	}
+
+	if _, err := h.roleRepo.GetByName(c.Request.Context(), req.Name); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "角色已存在"})
+		return
+	}
+
+	role := &models.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}
+	if role.Permissions == nil {
+		role.Permissions = []string{}
+	}
+
+	if err := h.roleRepo.Create(c.Request.Context(), role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建角色失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+func (h *AdminHandler) UpdateRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的角色ID"})
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.roleRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "角色不存在"})
+		return
+	}
+
+	// 更新字段
+	if req.Description != "" {
+		role.Description = req.Description
+	}
+	if req.Permissions != nil {
+		if role.Name == models.RoleRoot {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "root 角色的权限不能修改"})
+			return
+		}
+		if invalid, ok := validatePermissions(req.Permissions); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的权限: " + invalid})
+			return
+		}
+		role.Permissions = req.Permissions
+	}
+
+	if err := h.roleRepo.Update(c.Request.Context(), role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新角色失败"})
+		return
+	}
+
+	// 权限集合变化后已缓存的令牌权限全部失效
+	h.permissionResolver.InvalidateAll(c.Request.Context())
+
+	c.JSON(http.StatusOK, role)
+}
+
+func (h *AdminHandler) DeleteRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的角色ID"})
+		return
+	}
+
+	role, err := h.roleRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "角色不存在"})
+		return
+	}
+	if role.IsBuiltin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "内置角色不能删除"})
+		return
+	}
+
+	count, err := h.roleRepo.CountUsers(c.Request.Context(), role.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除角色失败"})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "仍有用户使用该角色，无法删除"})
+		return
+	}
+
+	if err := h.roleRepo.Delete(c.Request.Context(), role.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除角色失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "角色删除成功"})
+}
+
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	users, total, err := h.userRepo.List(c.Request.Context(), offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   users,
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
+	})
+}
+
+func (h *AdminHandler) AssignRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.roleRepo.GetByName(c.Request.Context(), req.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "角色不存在"})
+		return
+	}
+
+	// 防止管理员移除自己的管理权限
+	userID, _ := c.Get("user_id")
+	if uint(id) == userID.(uint) && !role.HasPermission(models.PermissionUserManage) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不能移除自己的用户管理权限"})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return
+	}
+
+	user.Role = role.Name
+	if err := h.userRepo.Update(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "分配角色失败"})
+		return
+	}
+
+	h.permissionResolver.InvalidateUser(c.Request.Context(), user.ID)
+
+	c.JSON(http.StatusOK, user)
+}
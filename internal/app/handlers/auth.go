@@ -1,22 +1,31 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"foodcook/internal/domain/models"
 	"foodcook/internal/domain/repositories"
 	"foodcook/internal/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
 type AuthHandler struct {
-	userRepo repositories.UserRepository
+	userRepo         repositories.UserRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	revocationStore  repositories.TokenRevocationStore
+	householdRepo    repositories.HouseholdRepository
 }
 
-func NewAuthHandler(userRepo repositories.UserRepository) *AuthHandler {
+func NewAuthHandler(userRepo repositories.UserRepository, refreshTokenRepo repositories.RefreshTokenRepository, revocationStore repositories.TokenRevocationStore, householdRepo repositories.HouseholdRepository) *AuthHandler {
 	return &AuthHandler{
-		userRepo: userRepo,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revocationStore:  revocationStore,
+		householdRepo:    householdRepo,
 	}
 }
 
@@ -31,9 +40,21 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type SwitchHouseholdRequest struct {
+	HouseholdID  uint   `json:"household_id" binding:"required"`
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 type AuthResponse struct {
-	Token string       `json:"token"`
-	User  *models.User `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token"`
+	ExpiresIn    int64        `json:"expires_in"` // 访问令牌有效期（秒）
+	HouseholdID  uint         `json:"household_id"`
+	User         *models.User `json:"user"`
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -75,17 +96,21 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	// 生成JWT token
-	token, err := utils.GenerateToken(user.ID, user.Username)
+	// 创建个人家庭
+	personal, err := h.householdRepo.EnsurePersonal(c.Request.Context(), user.ID, models.PersonalHouseholdName(user.Username))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "初始化个人家庭失败"})
+		return
+	}
+
+	// 生成令牌
+	resp, err := h.issueTokens(c.Request.Context(), user, "", personal.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Token生成失败"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	c.JSON(http.StatusCreated, resp)
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -108,17 +133,21 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// 生成JWT token
-	token, err := utils.GenerateToken(user.ID, user.Username)
+	// 登录时默认进入个人家庭，同时迁移旧的个人用餐记录
+	personal, err := h.householdRepo.EnsurePersonal(c.Request.Context(), user.ID, models.PersonalHouseholdName(user.Username))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "初始化个人家庭失败"})
+		return
+	}
+
+	// 生成令牌
+	resp, err := h.issueTokens(c.Request.Context(), user, "", personal.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Token生成失败"})
 		return
 	}
 
-	c.JSON(http.StatusOK, AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	c.JSON(http.StatusOK, resp)
 }
 
 func (h *AuthHandler) GetProfile(c *gin.Context) {
@@ -136,3 +165,247 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 
 	c.JSON(http.StatusOK, user)
 }
+
+// Refresh 使用刷新令牌换取新的访问令牌，旧刷新令牌随即失效
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	token, ok := h.consumeRefreshToken(c, req.RefreshToken)
+	if !ok {
+		return
+	}
+
+	user, err := h.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户不存在"})
+		return
+	}
+
+	// 已被移出原家庭时回到个人家庭
+	householdID := token.HouseholdID
+	if _, err := h.householdRepo.GetMember(ctx, householdID, user.ID); err != nil {
+		personal, err := h.householdRepo.EnsurePersonal(ctx, user.ID, models.PersonalHouseholdName(user.Username))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "初始化个人家庭失败"})
+			return
+		}
+		householdID = personal.ID
+	}
+
+	resp, err := h.issueTokens(ctx, user, token.FamilyID, householdID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Token生成失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SwitchHousehold 切换当前家庭，轮换刷新令牌并签发携带新家庭的访问令牌
+func (h *AuthHandler) SwitchHousehold(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return
+	}
+
+	var req SwitchHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.householdRepo.GetMember(ctx, req.HouseholdID, userID.(uint)); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "不是该家庭成员"})
+		return
+	}
+
+	token, ok := h.consumeRefreshToken(c, req.RefreshToken)
+	if !ok {
+		return
+	}
+	if token.UserID != userID.(uint) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "刷新令牌与当前用户不匹配"})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户不存在"})
+		return
+	}
+
+	resp, err := h.issueTokens(ctx, user, token.FamilyID, req.HouseholdID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Token生成失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// consumeRefreshToken 校验并使用刷新令牌，失败时直接写入错误响应。
+// 已使用过的刷新令牌再次出现说明可能被盗用，整个令牌家族都会被吊销
+func (h *AuthHandler) consumeRefreshToken(c *gin.Context, rawToken string) (*models.RefreshToken, bool) {
+	ctx := c.Request.Context()
+	token, err := h.refreshTokenRepo.GetByHash(ctx, utils.HashRefreshToken(rawToken))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "刷新令牌无效"})
+		return nil, false
+	}
+
+	if token.RevokedAt != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "刷新令牌已失效"})
+		return nil, false
+	}
+
+	if token.UsedAt != nil {
+		h.revokeReusedFamily(ctx, token)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "刷新令牌已被使用，请重新登录"})
+		return nil, false
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "刷新令牌已过期"})
+		return nil, false
+	}
+
+	// 并发刷新时只有一个请求能成功标记
+	marked, err := h.refreshTokenRepo.MarkUsed(ctx, token.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "刷新令牌失败"})
+		return nil, false
+	}
+	if !marked {
+		h.revokeReusedFamily(ctx, token)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "刷新令牌已被使用，请重新登录"})
+		return nil, false
+	}
+
+	return token, true
+}
+
+// Logout 注销当前会话：吊销当前访问令牌所属的令牌家族
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, ok := c.Get("claims")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tokenClaims := claims.(*utils.Claims)
+
+	if err := h.revokeSession(ctx, tokenClaims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "退出登录失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已退出登录"})
+}
+
+// LogoutAll 注销用户在所有设备上的会话
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	claims, ok := c.Get("claims")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tokenClaims := claims.(*utils.Claims)
+
+	families, err := h.refreshTokenRepo.RevokeByUser(ctx, tokenClaims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "退出登录失败"})
+		return
+	}
+
+	// 刷新令牌已全部失效，访问令牌最多存活一个有效期，吊销记录保留同样时长即可
+	expiresAt := time.Now().Add(utils.AccessTokenTTL())
+	for _, familyID := range families {
+		if err := h.revocationStore.Revoke(ctx, utils.FamilyRevocationKey(familyID), expiresAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "退出登录失败"})
+			return
+		}
+	}
+
+	// 当前会话的刷新令牌可能已过期，单独吊销以确保当前访问令牌失效
+	if err := h.revokeSession(ctx, tokenClaims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "退出登录失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已退出所有设备"})
+}
+
+// issueTokens 签发访问令牌和刷新令牌，familyID 为空时开启新的令牌家族
+func (h *AuthHandler) issueTokens(ctx context.Context, user *models.User, familyID string, householdID uint) (*AuthResponse, error) {
+	if familyID == "" {
+		id, err := utils.NewTokenID()
+		if err != nil {
+			return nil, err
+		}
+		familyID = id
+	}
+
+	accessToken, _, err := utils.GenerateAccessToken(user.ID, user.Username, familyID, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshHash, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.refreshTokenRepo.Create(ctx, &models.RefreshToken{
+		UserID:      user.ID,
+		FamilyID:    familyID,
+		HouseholdID: householdID,
+		TokenHash:   refreshHash,
+		ExpiresAt:   time.Now().Add(utils.RefreshTokenTTL()),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(utils.AccessTokenTTL().Seconds()),
+		HouseholdID:  householdID,
+		User:         user,
+	}, nil
+}
+
+// revokeSession 吊销访问令牌所属的会话，旧版令牌没有家族时只吊销其自身
+func (h *AuthHandler) revokeSession(ctx context.Context, claims *utils.Claims) error {
+	if claims.FamilyID != "" {
+		return h.revokeFamily(ctx, claims.FamilyID)
+	}
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		return h.revocationStore.Revoke(ctx, utils.TokenRevocationKey(claims.ID), claims.ExpiresAt.Time)
+	}
+	return nil
+}
+
+// revokeFamily 吊销令牌家族下的刷新令牌及已签发的访问令牌
+func (h *AuthHandler) revokeFamily(ctx context.Context, familyID string) error {
+	if err := h.refreshTokenRepo.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return h.revocationStore.Revoke(ctx, utils.FamilyRevocationKey(familyID), time.Now().Add(utils.AccessTokenTTL()))
+}
+
+func (h *AuthHandler) revokeReusedFamily(ctx context.Context, token *models.RefreshToken) {
+	logrus.Warnf("refresh token reuse detected, user %d family %s", token.UserID, token.FamilyID)
+	if err := h.revokeFamily(ctx, token.FamilyID); err != nil {
+		logrus.Errorf("revoke token family %s failed: %v", token.FamilyID, err)
+	}
+}
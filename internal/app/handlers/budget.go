@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// BudgetHandler 家庭和成员个人的月度用餐预算
+type BudgetHandler struct {
+	budgetRepo    repositories.BudgetRepository
+	statsRepo     repositories.StatsRepository
+	categoryRepo  repositories.CategoryRepository
+	householdRepo repositories.HouseholdRepository
+}
+
+func NewBudgetHandler(budgetRepo repositories.BudgetRepository, statsRepo repositories.StatsRepository, categoryRepo repositories.CategoryRepository, householdRepo repositories.HouseholdRepository) *BudgetHandler {
+	return &BudgetHandler{
+		budgetRepo:    budgetRepo,
+		statsRepo:     statsRepo,
+		categoryRepo:  categoryRepo,
+		householdRepo: householdRepo,
+	}
+}
+
+type SaveBudgetRequest struct {
+	Amount         float64                      `json:"amount" binding:"required,gt=0"`
+	Notes          string                       `json:"notes" binding:"max=255"`
+	CategoryLimits []BudgetCategoryLimitRequest `json:"category_limits" binding:"omitempty,max=50,dive"`
+}
+
+type BudgetCategoryLimitRequest struct {
+	CategoryID uint    `json:"category_id" binding:"required"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// BudgetStatus 预算及当月的实际花费
+type BudgetStatus struct {
+	*models.Budget
+	Spent      float64                `json:"spent"`
+	Remaining  float64                `json:"remaining"`
+	Percent    float64                `json:"percent"`
+	Level      string                 `json:"level"` // ok, warning（达到 80%）, exceeded（达到 100%）
+	Categories []CategoryBudgetStatus `json:"categories,omitempty"`
+}
+
+// CategoryBudgetStatus 分类限额及实际花费，花费包含下级分类的菜品
+type CategoryBudgetStatus struct {
+	CategoryID uint    `json:"category_id"`
+	Name       string  `json:"name"`
+	Amount     float64 `json:"amount"`
+	Spent      float64 `json:"spent"`
+	Remaining  float64 `json:"remaining"`
+	Percent    float64 `json:"percent"`
+	Level      string  `json:"level"`
+
+	categoryIDs map[uint]bool
+}
+
+// BudgetWarning 新的用餐记录使花费超过预算的 80% 或 100%
+type BudgetWarning struct {
+	BudgetID   uint    `json:"budget_id"`
+	Month      string  `json:"month"`
+	Personal   bool    `json:"personal"`
+	CategoryID *uint   `json:"category_id,omitempty"` // 为空时为整体预算
+	Level      string  `json:"level"`
+	Amount     float64 `json:"amount"`
+	Spent      float64 `json:"spent"`
+	Percent    float64 `json:"percent"`
+	Message    string  `json:"message"`
+}
+
+// budgetStatus 按用餐记录统计预算月份的实际花费，个人预算只统计该成员记录的用餐
+func budgetStatus(ctx context.Context, statsRepo repositories.StatsRepository, categoryRepo repositories.CategoryRepository, budget *models.Budget) (*BudgetStatus, error) {
+	from, to, err := budget.MonthRange()
+	if err != nil {
+		return nil, err
+	}
+	filter := repositories.StatsFilter{From: &from, To: &to}
+	if budget.UserID != nil {
+		filter.UserID = *budget.UserID
+	}
+
+	summary, err := statsRepo.Summary(ctx, budget.HouseholdID, filter)
+	if err != nil {
+		return nil, err
+	}
+	status := &BudgetStatus{
+		Budget:    budget,
+		Spent:     summary.TotalSpent,
+		Remaining: models.RoundPrice(budget.Amount - summary.TotalSpent),
+		Percent:   models.BudgetPercent(summary.TotalSpent, budget.Amount),
+		Level:     models.BudgetLevel(summary.TotalSpent, budget.Amount),
+	}
+	if len(budget.CategoryLimits) == 0 {
+		return status, nil
+	}
+
+	frequencies, err := statsRepo.TopCategories(ctx, budget.HouseholdID, filter, -1)
+	if err != nil {
+		return nil, err
+	}
+	spentByCategory := make(map[uint]float64, len(frequencies))
+	for _, frequency := range frequencies {
+		if frequency.CategoryID != nil {
+			spentByCategory[*frequency.CategoryID] = frequency.TotalSpent
+		}
+	}
+	categories, err := categoryRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, limit := range budget.CategoryLimits {
+		category := CategoryBudgetStatus{
+			CategoryID:  limit.CategoryID,
+			Amount:      limit.Amount,
+			categoryIDs: make(map[uint]bool),
+		}
+		if limit.Category != nil {
+			category.Name = limit.Category.Name
+		}
+		for _, id := range models.CategoryDescendantIDs(categories, limit.CategoryID) {
+			category.categoryIDs[id] = true
+			category.Spent += spentByCategory[id]
+		}
+		category.Spent = models.RoundPrice(category.Spent)
+		category.Remaining = models.RoundPrice(limit.Amount - category.Spent)
+		category.Percent = models.BudgetPercent(category.Spent, limit.Amount)
+		category.Level = models.BudgetLevel(category.Spent, limit.Amount)
+		status.Categories = append(status.Categories, category)
+	}
+	return status, nil
+}
+
+// budgetWarnings 检查新记录所在月份的家庭预算和记录人的个人预算，
+// 返回因这条记录而达到 80% 或 100% 的预算和分类限额。查询失败时只记录日志
+func budgetWarnings(ctx context.Context, budgetRepo repositories.BudgetRepository, statsRepo repositories.StatsRepository, categoryRepo repositories.CategoryRepository, mealRecord *models.MealRecord) []BudgetWarning {
+	month := mealRecord.EatenAt.In(time.Local).Format(models.BudgetMonthLayout)
+
+	var warnings []BudgetWarning
+	for _, userID := range []*uint{nil, &mealRecord.UserID} {
+		budget, err := budgetRepo.Find(ctx, mealRecord.HouseholdID, userID, month)
+		if err != nil {
+			continue
+		}
+		status, err := budgetStatus(ctx, statsRepo, categoryRepo, budget)
+		if err != nil {
+			logrus.Warnf("统计预算花费失败: %v", err)
+			continue
+		}
+
+		scope := "家庭预算"
+		if userID != nil {
+			scope = "个人预算"
+		}
+		if warning, ok := crossedBudget(status.Spent, mealRecord.TotalPrice, budget.Amount); ok {
+			warning.BudgetID, warning.Month, warning.Personal = budget.ID, month, userID != nil
+			warning.Message = budgetMessage(month, scope, warning)
+			warnings = append(warnings, warning)
+		}
+
+		for _, category := range status.Categories {
+			var added float64
+			for _, line := range mealRecord.Dishes {
+				if line.Dish != nil && line.Dish.CategoryID != nil && category.categoryIDs[*line.Dish.CategoryID] {
+					added += float64(line.Quantity) * line.UnitPrice
+				}
+			}
+			if warning, ok := crossedBudget(category.Spent, added, category.Amount); ok {
+				categoryID := category.CategoryID
+				warning.BudgetID, warning.Month, warning.Personal = budget.ID, month, userID != nil
+				warning.CategoryID = &categoryID
+				warning.Message = budgetMessage(month, fmt.Sprintf("%s中「%s」的限额", scope, category.Name), warning)
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+	return warnings
+}
+
+// crossedBudget 花费增加 added 后达到了新的提醒级别时返回提醒
+func crossedBudget(spent, added, amount float64) (BudgetWarning, bool) {
+	level := models.BudgetLevel(spent, amount)
+	if added <= 0 || level == models.BudgetLevelOK || models.BudgetLevel(models.RoundPrice(spent-added), amount) == level {
+		return BudgetWarning{}, false
+	}
+	return BudgetWarning{
+		Level:   level,
+		Amount:  amount,
+		Spent:   spent,
+		Percent: models.BudgetPercent(spent, amount),
+	}, true
+}
+
+func budgetMessage(month, scope string, warning BudgetWarning) string {
+	if warning.Level == models.BudgetLevelExceeded {
+		return fmt.Sprintf("%s 的%s已用完：已花费 %.2f，预算 %.2f", month, scope, warning.Spent, warning.Amount)
+	}
+	return fmt.Sprintf("%s 的%s已使用 %.1f%%：已花费 %.2f，预算 %.2f", month, scope, warning.Percent, warning.Spent, warning.Amount)
+}
+
+// budgetScope 返回当前成员和预算所属的成员，personal=true 时为当前用户的个人预算，
+// 否则为家庭预算。失败时直接写入错误响应
+func (h *BudgetHandler) budgetScope(c *gin.Context) (*models.HouseholdMember, *uint, bool) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return nil, nil, false
+	}
+	if c.Query("personal") == "true" {
+		userID := member.UserID
+		return member, &userID, true
+	}
+	return member, nil, true
+}
+
+// parseBudgetMonth 解析路径中的月份，失败时直接写入错误响应
+func parseBudgetMonth(c *gin.Context) (string, bool) {
+	month := c.Param("month")
+	if _, err := time.ParseInLocation(models.BudgetMonthLayout, month, time.Local); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的月份，格式应为 2006-01"})
+		return "", false
+	}
+	return month, true
+}
+
+// Get 某个月的预算和实际花费
+func (h *BudgetHandler) Get(c *gin.Context) {
+	member, userID, ok := h.budgetScope(c)
+	if !ok {
+		return
+	}
+	month, ok := parseBudgetMonth(c)
+	if !ok {
+		return
+	}
+
+	budget, err := h.budgetRepo.Find(c.Request.Context(), member.HouseholdID, userID, month)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "预算不存在"})
+		return
+	}
+	status, err := budgetStatus(c.Request.Context(), h.statsRepo, h.categoryRepo, budget)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "统计预算花费失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Save 设置某个月的预算，已有预算时覆盖。家庭预算只有所有者可以设置
+func (h *BudgetHandler) Save(c *gin.Context) {
+	member, userID, ok := h.budgetScope(c)
+	if !ok {
+		return
+	}
+	if userID == nil && !member.IsOwner() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只有家庭所有者可以设置家庭预算"})
+		return
+	}
+	month, ok := parseBudgetMonth(c)
+	if !ok {
+		return
+	}
+
+	var req SaveBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	budget := &models.Budget{
+		HouseholdID: member.HouseholdID,
+		UserID:      userID,
+		Month:       month,
+		Amount:      models.RoundPrice(req.Amount),
+		Notes:       req.Notes,
+	}
+	seen := make(map[uint]bool, len(req.CategoryLimits))
+	for _, limit := range req.CategoryLimits {
+		if seen[limit.CategoryID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "同一分类只能设置一个限额"})
+			return
+		}
+		seen[limit.CategoryID] = true
+		if _, err := h.categoryRepo.GetByID(c.Request.Context(), limit.CategoryID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "分类不存在"})
+			return
+		}
+		budget.CategoryLimits = append(budget.CategoryLimits, models.BudgetCategoryLimit{
+			CategoryID: limit.CategoryID,
+			Amount:     models.RoundPrice(limit.Amount),
+		})
+	}
+
+	if err := h.budgetRepo.Save(c.Request.Context(), budget); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存预算失败"})
+		return
+	}
+
+	saved, err := h.budgetRepo.Find(c.Request.Context(), member.HouseholdID, userID, month)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取预算失败"})
+		return
+	}
+	status, err := budgetStatus(c.Request.Context(), h.statsRepo, h.categoryRepo, saved)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "统计预算花费失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Delete 删除某个月的预算，家庭预算只有所有者可以删除
+func (h *BudgetHandler) Delete(c *gin.Context) {
+	member, userID, ok := h.budgetScope(c)
+	if !ok {
+		return
+	}
+	if userID == nil && !member.IsOwner() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只有家庭所有者可以删除家庭预算"})
+		return
+	}
+	month, ok := parseBudgetMonth(c)
+	if !ok {
+		return
+	}
+
+	budget, err := h.budgetRepo.Find(c.Request.Context(), member.HouseholdID, userID, month)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "预算不存在"})
+		return
+	}
+	if err := h.budgetRepo.Delete(c.Request.Context(), budget.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除预算失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "预算删除成功"})
+}
+
+// History 按月份倒序返回预算历史及每个月的实际花费
+func (h *BudgetHandler) History(c *gin.Context) {
+	member, userID, ok := h.budgetScope(c)
+	if !ok {
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "12"))
+
+	budgets, total, err := h.budgetRepo.List(c.Request.Context(), member.HouseholdID, userID, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取预算历史失败"})
+		return
+	}
+
+	statuses := make([]*BudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		status, err := budgetStatus(c.Request.Context(), h.statsRepo, h.categoryRepo, budget)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "统计预算花费失败"})
+			return
+		}
+		statuses = append(statuses, status)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   statuses,
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
+	})
+}
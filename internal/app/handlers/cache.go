@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"foodcook/internal/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CacheHandler struct {
+	cache cache.Cache
+}
+
+func NewCacheHandler(c cache.Cache) *CacheHandler {
+	return &CacheHandler{
+		cache: c,
+	}
+}
+
+func (h *CacheHandler) Stats(c *gin.Context) {
+	if h.cache == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	stats := h.cache.Stats()
+	var hitRate float64
+	if total := stats.Hits + stats.Misses; total > 0 {
+		hitRate = float64(stats.Hits) / float64(total)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":  true,
+		"backend":  stats.Backend,
+		"hits":     stats.Hits,
+		"misses":   stats.Misses,
+		"entries":  stats.Entries,
+		"hit_rate": hitRate,
+	})
+}
@@ -23,11 +23,54 @@ func NewCategoryHandler(categoryRepo repositories.CategoryRepository) *CategoryH
 type CreateCategoryRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
+	ParentID    *uint  `json:"parent_id"`
+	SortOrder   int    `json:"sort_order"`
+	Icon        string `json:"icon" binding:"max=255"`
 }
 
 type UpdateCategoryRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	ParentID    *uint   `json:"parent_id"` // 传 0 表示移到顶级
+	SortOrder   *int    `json:"sort_order"`
+	Icon        *string `json:"icon" binding:"omitempty,max=255"`
+}
+
+// checkTarget 校验作为上级或转移目标的分类存在，且不是分类自身或其下级，失败时直接写入错误响应。
+// 新建分类时 id 为 0，label 用于错误信息
+func (h *CategoryHandler) checkTarget(c *gin.Context, id, targetID uint, label string) bool {
+	categories, err := h.categoryRepo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取分类列表失败"})
+		return false
+	}
+	if !containsCategory(categories, targetID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": label + "不存在"})
+		return false
+	}
+	if id != 0 && containsID(models.CategoryDescendantIDs(categories, id), targetID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": label + "不能是该分类自身或其下级分类"})
+		return false
+	}
+	return true
+}
+
+func containsCategory(categories []*models.Category, id uint) bool {
+	for _, category := range categories {
+		if category.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
 }
 
 func (h *CategoryHandler) List(c *gin.Context) {
@@ -42,6 +85,19 @@ func (h *CategoryHandler) List(c *gin.Context) {
 	})
 }
 
+// Tree 按上下级关系返回分类树，同一级按排序值和名称排列
+func (h *CategoryHandler) Tree(c *gin.Context) {
+	categories, err := h.categoryRepo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取分类列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": models.BuildCategoryTree(categories),
+	})
+}
+
 func (h *CategoryHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -65,9 +121,19 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if req.ParentID != nil && *req.ParentID == 0 {
+		req.ParentID = nil
+	}
+	if req.ParentID != nil && !h.checkTarget(c, 0, *req.ParentID, "上级分类") {
+		return
+	}
+
 	category := &models.Category{
 		Name:        req.Name,
 		Description: req.Description,
+		ParentID:    req.ParentID,
+		SortOrder:   req.SortOrder,
+		Icon:        req.Icon,
 	}
 
 	if err := h.categoryRepo.Create(c.Request.Context(), category); err != nil {
@@ -105,6 +171,22 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 	if req.Description != "" {
 		category.Description = req.Description
 	}
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			category.ParentID = nil
+		} else {
+			if !h.checkTarget(c, category.ID, *req.ParentID, "上级分类") {
+				return
+			}
+			category.ParentID = req.ParentID
+		}
+	}
+	if req.SortOrder != nil {
+		category.SortOrder = *req.SortOrder
+	}
+	if req.Icon != nil {
+		category.Icon = *req.Icon
+	}
 
 	if err := h.categoryRepo.Update(c.Request.Context(), category); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新分类失败"})
@@ -121,6 +203,43 @@ func (h *CategoryHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if _, err := h.categoryRepo.GetByID(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "分类不存在"})
+		return
+	}
+
+	// 指定 reassign_to 时先把菜品和下级分类移过去再删除
+	if value := c.Query("reassign_to"); value != "" {
+		targetID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的目标分类ID"})
+			return
+		}
+		if !h.checkTarget(c, uint(id), uint(targetID), "目标分类") {
+			return
+		}
+		if err := h.categoryRepo.DeleteWithReassign(c.Request.Context(), uint(id), uint(targetID)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "删除分类失败"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "分类删除成功"})
+		return
+	}
+
+	dishes, children, err := h.categoryRepo.CountUsage(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "检查分类使用情况失败"})
+		return
+	}
+	if dishes > 0 || children > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "该分类下还有菜品或下级分类，请指定 reassign_to 转移后再删除",
+			"dishes":   dishes,
+			"children": children,
+		})
+		return
+	}
+
 	if err := h.categoryRepo.Delete(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除分类失败"})
 		return
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateDietaryRestrictionsRequest 整体替换当前用户的饮食限制
+type UpdateDietaryRestrictionsRequest struct {
+	Allergens     []string `json:"allergens" binding:"max=20"`
+	Diets         []string `json:"diets" binding:"max=10"`
+	MaxSpicyLevel *int     `json:"max_spicy_level" binding:"omitempty,min=0,max=3"` // 为空时不限制辣度
+}
+
+// DietaryWarning 参与者的饮食限制与菜品冲突，只作提醒，不阻止创建记录
+type DietaryWarning struct {
+	UserID   uint     `json:"user_id"`
+	Username string   `json:"username"`
+	DishID   uint     `json:"dish_id"`
+	DishName string   `json:"dish_name"`
+	Reasons  []string `json:"reasons"`
+}
+
+// DietaryOption 过敏原或饮食标签的标识和名称
+type DietaryOption struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// normalizeAllergens 校验并规范过敏原列表，失败时直接写入错误响应
+func normalizeAllergens(c *gin.Context, allergens []string) ([]string, bool) {
+	normalized, err := models.NormalizeAllergens(allergens)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return normalized, true
+}
+
+// DietaryOptions 返回可用的过敏原、饮食标签和辣度
+func (h *DishHandler) DietaryOptions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"allergens":    sortedOptions(models.Allergens),
+		"diets":        sortedOptions(models.Diets),
+		"spicy_levels": models.SpicyLevels,
+	})
+}
+
+func sortedOptions(labels map[string]string) []DietaryOption {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	options := make([]DietaryOption, 0, len(keys))
+	for _, key := range keys {
+		options = append(options, DietaryOption{Key: key, Name: labels[key]})
+	}
+	return options
+}
+
+// UpdateDietaryRestrictions 修改当前用户的过敏原、饮食标签和辣度限制
+func (h *AuthHandler) UpdateDietaryRestrictions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return
+	}
+
+	var req UpdateDietaryRestrictionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	allergens, ok := normalizeAllergens(c, req.Allergens)
+	if !ok {
+		return
+	}
+	diets, err := models.NormalizeDiets(req.Diets)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return
+	}
+	user.Dietary = models.DietaryRestrictions{
+		Allergens:     allergens,
+		Diets:         diets,
+		MaxSpicyLevel: req.MaxSpicyLevel,
+	}
+	if err := h.userRepo.Update(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新饮食限制失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// dietaryWarnings 检查参与用餐的家庭成员的饮食限制，需要预加载参与者的用户。
+// 菜品查询失败时跳过该菜品，不影响记录本身
+func dietaryWarnings(ctx context.Context, dishRepo repositories.DishRepository, participants []models.MealRecordParticipant, dishes []repositories.MealRecordDishRequest) []DietaryWarning {
+	var restricted []*models.User
+	for _, participant := range participants {
+		if participant.User != nil && !participant.User.Dietary.IsZero() {
+			restricted = append(restricted, participant.User)
+		}
+	}
+	if len(restricted) == 0 {
+		return nil
+	}
+
+	var warnings []DietaryWarning
+	for _, line := range dishes {
+		dish, err := dishRepo.GetByID(ctx, line.DishID)
+		if err != nil {
+			continue
+		}
+		for _, user := range restricted {
+			reasons := user.Dietary.Violations(dish)
+			if len(reasons) == 0 {
+				continue
+			}
+			warnings = append(warnings, DietaryWarning{
+				UserID:   user.ID,
+				Username: user.Username,
+				DishID:   dish.ID,
+				DishName: dish.Name,
+				Reasons:  reasons,
+			})
+		}
+	}
+	return warnings
+}
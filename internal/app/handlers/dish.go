@@ -3,20 +3,30 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"foodcook/internal/domain/models"
 	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/recipe"
 
 	"github.com/gin-gonic/gin"
 )
 
 type DishHandler struct {
-	dishRepo repositories.DishRepository
+	dishRepo       repositories.DishRepository
+	ingredientRepo repositories.IngredientRepository
+	tagRepo        repositories.TagRepository
+	preferenceRepo repositories.PreferenceRepository
+	recipeFetcher  *recipe.Fetcher
 }
 
-func NewDishHandler(dishRepo repositories.DishRepository) *DishHandler {
+func NewDishHandler(dishRepo repositories.DishRepository, ingredientRepo repositories.IngredientRepository, tagRepo repositories.TagRepository, preferenceRepo repositories.PreferenceRepository, recipeFetcher *recipe.Fetcher) *DishHandler {
 	return &DishHandler{
-		dishRepo: dishRepo,
+		dishRepo:       dishRepo,
+		ingredientRepo: ingredientRepo,
+		tagRepo:        tagRepo,
+		preferenceRepo: preferenceRepo,
+		recipeFetcher:  recipeFetcher,
 	}
 }
 
@@ -24,25 +34,149 @@ type CreateDishRequest struct {
 	Name        string                  `json:"name" binding:"required"`
 	Description string                  `json:"description"`
 	ImageURL    string                  `json:"image_url"`
-	Price       float64                 `json:"price" binding:"required,min=0"`
+	Price       float64                 `json:"price" binding:"min=0"` // 开启自动定价时可以省略
 	CookingLink string                  `json:"cooking_link"`
 	CategoryID  *uint                   `json:"category_id"`
 	Ingredients []DishIngredientRequest `json:"ingredients"`
+	AutoPrice   bool                    `json:"auto_price"` // 价格等于食材成本，并随食材价格自动更新
+	PrepMinutes int                     `json:"prep_minutes" binding:"min=0,max=1440"`
+	CookMinutes int                     `json:"cook_minutes" binding:"min=0,max=1440"`
+	Difficulty  string                  `json:"difficulty"`                                // easy/medium/hard
+	Servings    int                     `json:"servings" binding:"omitempty,min=1,max=99"` // 食材用量对应的份数，默认 1
+	Steps       []DishStepRequest       `json:"steps" binding:"omitempty,max=50,dive"`
+	Vegetarian  bool                    `json:"vegetarian"`
+	Halal       bool                    `json:"halal"`
+	SpicyLevel  int                     `json:"spicy_level" binding:"min=0,max=3"` // 0 不辣到 3 特辣
+	TagIDs      []uint                  `json:"tag_ids"`
 }
 
 type UpdateDishRequest struct {
-	Name        string                  `json:"name"`
-	Description string                  `json:"description"`
-	ImageURL    string                  `json:"image_url"`
-	Price       float64                 `json:"price" binding:"min=0"`
-	CookingLink string                  `json:"cooking_link"`
-	CategoryID  *uint                   `json:"category_id"`
-	Ingredients []DishIngredientRequest `json:"ingredients"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	ImageURL    string                   `json:"image_url"`
+	Price       float64                  `json:"price" binding:"min=0"`
+	CookingLink string                   `json:"cooking_link"`
+	CategoryID  *uint                    `json:"category_id"`
+	Ingredients *[]DishIngredientRequest `json:"ingredients"` // 为空时不修改食材，传空数组表示清除
+	AutoPrice   *bool                    `json:"auto_price"`
+	PrepMinutes *int                     `json:"prep_minutes" binding:"omitempty,min=0,max=1440"`
+	CookMinutes *int                     `json:"cook_minutes" binding:"omitempty,min=0,max=1440"`
+	Difficulty  *string                  `json:"difficulty"`
+	Servings    *int                     `json:"servings" binding:"omitempty,min=1,max=99"`
+	Steps       *[]DishStepRequest       `json:"steps" binding:"omitempty,max=50,dive"` // 为空时不修改步骤，传空数组表示清除
+	Vegetarian  *bool                    `json:"vegetarian"`
+	Halal       *bool                    `json:"halal"`
+	SpicyLevel  *int                     `json:"spicy_level" binding:"omitempty,min=0,max=3"`
+	TagIDs      *[]uint                  `json:"tag_ids"` // 为空时不修改标签，传空数组表示清除
 }
 
 type DishIngredientRequest struct {
 	IngredientID uint    `json:"ingredient_id" binding:"required"`
 	Quantity     float64 `json:"quantity" binding:"required,min=0"`
+	Unit         string  `json:"unit"` // 为空时使用食材的单位，否则保存时换算为食材的单位
+}
+
+type DishStepRequest struct {
+	Content      string `json:"content" binding:"required,max=2000"`
+	TimerSeconds int    `json:"timer_seconds" binding:"min=0,max=86400"`
+	ImageURL     string `json:"image_url" binding:"max=255"`
+}
+
+// convertSteps 转换步骤请求为repository类型，保持请求中的顺序
+func convertSteps(reqs []DishStepRequest) []repositories.DishStepRequest {
+	steps := make([]repositories.DishStepRequest, 0, len(reqs))
+	for _, step := range reqs {
+		steps = append(steps, repositories.DishStepRequest{
+			Content:      step.Content,
+			TimerSeconds: step.TimerSeconds,
+			ImageURL:     step.ImageURL,
+		})
+	}
+	return steps
+}
+
+// convertIngredients 转换食材请求为repository类型并计算食材成本，指定了单位的用量换算为食材的单位，
+// 失败时直接写入错误响应
+func (h *DishHandler) convertIngredients(c *gin.Context, reqs []DishIngredientRequest) ([]repositories.DishIngredientRequest, float64, bool) {
+	ingredients := make([]repositories.DishIngredientRequest, 0, len(reqs))
+	var cost float64
+	for _, ing := range reqs {
+		ingredient, err := h.ingredientRepo.GetByID(c.Request.Context(), ing.IngredientID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "食材不存在"})
+			return nil, 0, false
+		}
+		quantity, err := convertToIngredientUnit(ingredient, ing.Quantity, ing.Unit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": conversionError(ingredient, ing.Unit, err)})
+			return nil, 0, false
+		}
+		ingredients = append(ingredients, repositories.DishIngredientRequest{
+			IngredientID: ing.IngredientID,
+			Quantity:     quantity,
+		})
+		cost += quantity * ingredient.Price
+	}
+	return ingredients, models.RoundPrice(cost), true
+}
+
+// parseDishFilter 解析菜品列表和搜索共用的筛选条件，失败时直接写入错误响应。
+// exclude_allergens、diet 和 tag_ids 为逗号分隔的列表，sort 为 newest/rating/popularity
+func parseDishFilter(c *gin.Context) (repositories.DishFilter, bool) {
+	var filter repositories.DishFilter
+
+	allergens, err := models.NormalizeAllergens(splitQuery(c.Query("exclude_allergens")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	diets, err := models.NormalizeDiets(splitQuery(c.Query("diet")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	filter.ExcludeAllergens = allergens
+	filter.Diets = diets
+
+	if value := c.Query("max_spicy_level"); value != "" {
+		level, err := strconv.Atoi(value)
+		if err != nil || !models.IsValidSpicyLevel(level) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的辣度"})
+			return filter, false
+		}
+		filter.MaxSpicyLevel = &level
+	}
+
+	tagIDs, ok := parseIDList(c, c.Query("tag_ids"), "无效的标签ID")
+	if !ok {
+		return filter, false
+	}
+	filter.TagIDs = tagIDs
+	switch c.DefaultQuery("tag_match", "any") {
+	case "any":
+	case "all":
+		filter.MatchAllTags = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tag_match 只能为 any 或 all"})
+		return filter, false
+	}
+
+	switch order := c.DefaultQuery("sort", repositories.DishSortNewest); order {
+	case repositories.DishSortNewest:
+	case repositories.DishSortRating, repositories.DishSortPopularity:
+		filter.Sort = order
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort 只能为 newest、rating 或 popularity"})
+		return filter, false
+	}
+	return filter, true
+}
+
+func splitQuery(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
 }
 
 func (h *DishHandler) List(c *gin.Context) {
@@ -50,19 +184,28 @@ func (h *DishHandler) List(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	categoryIDStr := c.Query("category_id")
 
-	var categoryID *uint
+	filter, ok := parseDishFilter(c)
+	if !ok {
+		return
+	}
 	if categoryIDStr != "" {
 		if id, err := strconv.ParseUint(categoryIDStr, 10, 32); err == nil {
 			catID := uint(id)
-			categoryID = &catID
+			filter.CategoryID = &catID
+			filter.IncludeDescendants = c.Query("include_descendants") == "true"
 		}
 	}
 
-	dishes, total, err := h.dishRepo.List(c.Request.Context(), offset, limit, categoryID)
+	dishes, total, err := h.dishRepo.List(c.Request.Context(), filter, offset, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取菜品列表失败"})
 		return
 	}
+	for _, dish := range dishes {
+		dish.IngredientCost = dish.CalculateIngredientCost()
+		dish.Allergens = dish.CollectAllergens()
+	}
+	attachDishStats(c.Request.Context(), h.preferenceRepo, dishes)
 
 	c.JSON(http.StatusOK, gin.H{
 		"data":   dishes,
@@ -79,11 +222,30 @@ func (h *DishHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	servings := 0
+	if value := c.Query("servings"); value != "" {
+		servings, err = strconv.Atoi(value)
+		if err != nil || servings < 1 || servings > 99 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的份数"})
+			return
+		}
+	}
+
 	dish, err := h.dishRepo.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "菜品不存在"})
 		return
 	}
+	// 按请求的份数换算食材用量，成本和营养成分随之变化
+	dish.ScaleServings(servings)
+	dish.IngredientCost = dish.CalculateIngredientCost()
+	dish.Allergens = dish.CollectAllergens()
+	dish.Nutrition = dish.CalculateNutrition()
+	if dish.Servings > 0 {
+		perServing := dish.Nutrition.Scale(1 / float64(dish.Servings)).Round()
+		dish.NutritionPerServing = &perServing
+	}
+	attachDishStats(c.Request.Context(), h.preferenceRepo, []*models.Dish{dish})
 
 	c.JSON(http.StatusOK, dish)
 }
@@ -95,6 +257,11 @@ func (h *DishHandler) Create(c *gin.Context) {
 		return
 	}
 
+	h.createDish(c, &req)
+}
+
+// createDish 校验并创建菜品，结果直接写入响应
+func (h *DishHandler) createDish(c *gin.Context, req *CreateDishRequest) {
 	dish := &models.Dish{
 		Name:        req.Name,
 		Description: req.Description,
@@ -102,19 +269,42 @@ func (h *DishHandler) Create(c *gin.Context) {
 		Price:       req.Price,
 		CookingLink: req.CookingLink,
 		CategoryID:  req.CategoryID,
+		AutoPrice:   req.AutoPrice,
+		PrepMinutes: req.PrepMinutes,
+		CookMinutes: req.CookMinutes,
+		Difficulty:  req.Difficulty,
+		Servings:    req.Servings,
+		Vegetarian:  req.Vegetarian,
+		Halal:       req.Halal,
+		SpicyLevel:  req.SpicyLevel,
+	}
+	if dish.Servings == 0 {
+		dish.Servings = 1
+	}
+	if !models.IsValidDifficulty(dish.Difficulty) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的难度"})
+		return
+	}
+	tags, ok := loadTags(c, h.tagRepo, req.TagIDs)
+	if !ok {
+		return
 	}
+	dish.Tags = tags
 
-	// 转换食材请求为repository类型
-	var ingredients []repositories.DishIngredientRequest
-	for _, ing := range req.Ingredients {
-		ingredients = append(ingredients, repositories.DishIngredientRequest{
-			IngredientID: ing.IngredientID,
-			Quantity:     ing.Quantity,
-		})
+	ingredients, cost, ok := h.convertIngredients(c, req.Ingredients)
+	if !ok {
+		return
+	}
+	if dish.AutoPrice {
+		dish.Price = cost
+	} else if dish.Price == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请填写菜品价格"})
+		return
 	}
+	dish.IngredientCost = cost
 
-	// 创建菜品和食材关联
-	if err := h.dishRepo.CreateWithIngredients(c.Request.Context(), dish, ingredients); err != nil {
+	// 创建菜品、食材关联和步骤
+	if err := h.dishRepo.CreateWithIngredients(c.Request.Context(), dish, ingredients, convertSteps(req.Steps)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建菜品失败"})
 		return
 	}
@@ -160,18 +350,63 @@ func (h *DishHandler) Update(c *gin.Context) {
 	if req.CategoryID != nil {
 		dish.CategoryID = req.CategoryID
 	}
+	if req.AutoPrice != nil {
+		dish.AutoPrice = *req.AutoPrice
+	}
+	if req.PrepMinutes != nil {
+		dish.PrepMinutes = *req.PrepMinutes
+	}
+	if req.CookMinutes != nil {
+		dish.CookMinutes = *req.CookMinutes
+	}
+	if req.Difficulty != nil {
+		if !models.IsValidDifficulty(*req.Difficulty) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的难度"})
+			return
+		}
+		dish.Difficulty = *req.Difficulty
+	}
+	if req.Servings != nil {
+		dish.Servings = *req.Servings
+	}
+	if req.Vegetarian != nil {
+		dish.Vegetarian = *req.Vegetarian
+	}
+	if req.Halal != nil {
+		dish.Halal = *req.Halal
+	}
+	if req.SpicyLevel != nil {
+		dish.SpicyLevel = *req.SpicyLevel
+	}
+	if req.TagIDs != nil {
+		tags, ok := loadTags(c, h.tagRepo, *req.TagIDs)
+		if !ok {
+			return
+		}
+		dish.Tags = tags
+	}
 
-	// 转换食材请求为repository类型
+	// 没有修改食材时按现有的食材计算成本
 	var ingredients []repositories.DishIngredientRequest
-	for _, ing := range req.Ingredients {
-		ingredients = append(ingredients, repositories.DishIngredientRequest{
-			IngredientID: ing.IngredientID,
-			Quantity:     ing.Quantity,
-		})
+	cost := dish.CalculateIngredientCost()
+	if req.Ingredients != nil {
+		var ok bool
+		if ingredients, cost, ok = h.convertIngredients(c, *req.Ingredients); !ok {
+			return
+		}
+	}
+	if dish.AutoPrice {
+		dish.Price = cost
+	}
+	dish.IngredientCost = cost
+
+	var steps []repositories.DishStepRequest
+	if req.Steps != nil {
+		steps = convertSteps(*req.Steps)
 	}
 
-	// 更新菜品和食材关联
-	if err := h.dishRepo.UpdateWithIngredients(c.Request.Context(), dish, ingredients); err != nil {
+	// 在同一事务中更新菜品、食材关联和步骤
+	if err := h.dishRepo.UpdateWithIngredients(c.Request.Context(), dish, ingredients, steps); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新菜品失败"})
 		return
 	}
@@ -216,11 +451,21 @@ func (h *DishHandler) Search(c *gin.Context) {
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
-	dishes, total, err := h.dishRepo.Search(c.Request.Context(), keyword, offset, limit)
+	filter, ok := parseDishFilter(c)
+	if !ok {
+		return
+	}
+
+	dishes, total, err := h.dishRepo.Search(c.Request.Context(), keyword, filter, offset, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "搜索菜品失败"})
 		return
 	}
+	for _, dish := range dishes {
+		dish.IngredientCost = dish.CalculateIngredientCost()
+		dish.Allergens = dish.CollectAllergens()
+	}
+	attachDishStats(c.Request.Context(), h.preferenceRepo, dishes)
 
 	c.JSON(http.StatusOK, gin.H{
 		"data":   dishes,
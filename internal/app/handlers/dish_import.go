@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"foodcook/internal/app/middleware"
+	"foodcook/internal/domain/models"
+	"foodcook/internal/pkg/recipe"
+	"foodcook/internal/pkg/units"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// 草稿中步骤的数量和长度与创建菜品的校验保持一致
+const (
+	maxDraftSteps      = 50
+	maxDraftStepLength = 2000
+)
+
+type ImportDishRequest struct {
+	URL string `json:"url" binding:"required,url,max=255"`
+}
+
+// ConfirmImportRequest 确认导入的草稿，ingredients 为已有食材，new_ingredients 为需要新建的食材
+type ConfirmImportRequest struct {
+	CreateDishRequest
+	NewIngredients []ImportNewIngredientRequest `json:"new_ingredients" binding:"omitempty,max=50,dive"`
+}
+
+type ImportNewIngredientRequest struct {
+	Name         string  `json:"name" binding:"required,max=100"`
+	Unit         string  `json:"unit" binding:"required"` // 食材的单位
+	Price        float64 `json:"price" binding:"min=0"`   // 食材单价，可以之后在食材管理中修改
+	Quantity     float64 `json:"quantity" binding:"required,min=0"`
+	QuantityUnit string  `json:"quantity_unit"` // 用量的单位，为空时与食材单位相同
+}
+
+// DishDraft 从链接导入的菜品草稿，不会写入数据库。修改后原样提交到确认接口即可创建菜品
+type DishDraft struct {
+	Name           string               `json:"name"`
+	Description    string               `json:"description"`
+	ImageURL       string               `json:"image_url"`
+	CookingLink    string               `json:"cooking_link"`
+	PrepMinutes    int                  `json:"prep_minutes"`
+	CookMinutes    int                  `json:"cook_minutes"`
+	Servings       int                  `json:"servings"`
+	Steps          []DishStepRequest    `json:"steps"`
+	Ingredients    []DraftIngredient    `json:"ingredients"`
+	NewIngredients []DraftNewIngredient `json:"new_ingredients"`
+	Source         recipe.Source        `json:"source"`
+}
+
+// DraftIngredient 匹配到已有食材的一行
+type DraftIngredient struct {
+	DishIngredientRequest
+	Name    string `json:"name"`
+	Raw     string `json:"raw"`
+	Warning string `json:"warning,omitempty"`
+}
+
+// DraftNewIngredient 没有匹配到已有食材、建议新建的一行
+type DraftNewIngredient struct {
+	ImportNewIngredientRequest
+	Raw     string `json:"raw"`
+	Warning string `json:"warning,omitempty"`
+}
+
+// Import 抓取菜谱链接并生成菜品草稿，食材按名称匹配已有食材
+func (h *DishHandler) Import(c *gin.Context) {
+	var req ImportDishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	parsed, err := h.recipeFetcher.Fetch(c.Request.Context(), req.URL)
+	if err != nil {
+		switch {
+		case errors.Is(err, recipe.ErrInvalidURL):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的菜谱链接"})
+		case errors.Is(err, recipe.ErrForbiddenAddress):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "不允许导入内网地址的菜谱"})
+		case errors.Is(err, recipe.ErrNotFound):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "页面中没有识别到菜谱"})
+		default:
+			logrus.WithError(err).WithField("url", req.URL).Warn("获取菜谱页面失败")
+			c.JSON(http.StatusBadGateway, gin.H{"error": "获取菜谱页面失败"})
+		}
+		return
+	}
+
+	existing, _, err := h.ingredientRepo.List(c.Request.Context(), 0, -1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取食材列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildDishDraft(parsed, req.URL, existing))
+}
+
+// ConfirmImport 创建审核后的草稿，先新建缺少的食材再创建菜品。
+// 已经存在同名食材时直接使用，重复提交不会产生重复的食材
+func (h *DishHandler) ConfirmImport(c *gin.Context) {
+	var req ConfirmImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(req.NewIngredients) > 0 && !middleware.HasPermission(c, models.PermissionIngredientWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "缺少权限: " + models.PermissionIngredientWrite})
+		return
+	}
+
+	// 新建食材之前先做不依赖数据库的校验，避免留下用不到的食材
+	if !models.IsValidDifficulty(req.Difficulty) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的难度"})
+		return
+	}
+	if !req.AutoPrice && req.Price == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请填写菜品价格"})
+		return
+	}
+	newIngredients := make([]ImportNewIngredientRequest, 0, len(req.NewIngredients))
+	for _, ing := range req.NewIngredients {
+		unit, ok := normalizeUnit(c, ing.Unit)
+		if !ok {
+			return
+		}
+		ing.Name = strings.TrimSpace(ing.Name)
+		ing.Unit = unit
+		if ing.QuantityUnit != "" {
+			if _, err := units.Convert(ing.Quantity, ing.QuantityUnit, unit, nil); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": conversionError(&models.Ingredient{Name: ing.Name, Unit: unit}, ing.QuantityUnit, err)})
+				return
+			}
+		}
+		newIngredients = append(newIngredients, ing)
+	}
+
+	existing, _, err := h.ingredientRepo.List(c.Request.Context(), 0, -1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取食材列表失败"})
+		return
+	}
+	byName := make(map[string]*models.Ingredient, len(existing))
+	for _, ingredient := range existing {
+		byName[strings.ToLower(ingredient.Name)] = ingredient
+	}
+
+	for _, ing := range newIngredients {
+		key := strings.ToLower(ing.Name)
+		ingredient, ok := byName[key]
+		if !ok {
+			ingredient = &models.Ingredient{
+				Name:  ing.Name,
+				Unit:  ing.Unit,
+				Price: models.RoundPrice(ing.Price),
+			}
+			if err := h.ingredientRepo.Create(c.Request.Context(), ingredient); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "创建食材失败"})
+				return
+			}
+			byName[key] = ingredient
+		}
+
+		quantityUnit := ing.QuantityUnit
+		if quantityUnit == "" {
+			quantityUnit = ing.Unit
+		}
+		req.Ingredients = append(req.Ingredients, DishIngredientRequest{
+			IngredientID: ingredient.ID,
+			Quantity:     ing.Quantity,
+			Unit:         quantityUnit,
+		})
+	}
+
+	h.createDish(c, &req.CreateDishRequest)
+}
+
+// buildDishDraft 把解析结果转换为草稿，份数、时间和步骤限制在创建菜品允许的范围内
+func buildDishDraft(parsed *recipe.Recipe, link string, existing []*models.Ingredient) *DishDraft {
+	draft := &DishDraft{
+		Name:           truncateRunes(parsed.Name, 100),
+		Description:    parsed.Description,
+		CookingLink:    link,
+		PrepMinutes:    clamp(parsed.PrepMinutes, 0, 1440),
+		CookMinutes:    clamp(parsed.CookMinutes, 0, 1440),
+		Servings:       clamp(parsed.Servings, 1, 99),
+		Steps:          []DishStepRequest{},
+		Ingredients:    []DraftIngredient{},
+		NewIngredients: []DraftNewIngredient{},
+		Source:         parsed.Source,
+	}
+	// 截断后的地址没有意义，过长时直接丢弃
+	if len(parsed.ImageURL) <= 255 {
+		draft.ImageURL = parsed.ImageURL
+	}
+
+	for _, step := range parsed.Steps {
+		if len(draft.Steps) == maxDraftSteps {
+			break
+		}
+		draft.Steps = append(draft.Steps, DishStepRequest{Content: truncateRunes(step, maxDraftStepLength)})
+	}
+
+	for _, line := range parsed.Ingredients {
+		name := truncateRunes(line.Name, 100)
+		if ingredient := matchIngredient(name, existing); ingredient != nil {
+			item := DraftIngredient{
+				DishIngredientRequest: DishIngredientRequest{
+					IngredientID: ingredient.ID,
+					Quantity:     line.Quantity,
+					Unit:         line.Unit,
+				},
+				Name: ingredient.Name,
+				Raw:  line.Raw,
+			}
+			switch {
+			case line.Quantity == 0:
+				item.Warning = "用量不确定，请填写用量"
+			case line.Unit == "":
+				item.Warning = "没有识别到单位，默认按食材单位「" + ingredient.Unit + "」计算"
+			default:
+				if _, err := convertToIngredientUnit(ingredient, line.Quantity, line.Unit); err != nil {
+					item.Warning = conversionError(ingredient, line.Unit, err)
+				}
+			}
+			draft.Ingredients = append(draft.Ingredients, item)
+			continue
+		}
+
+		item := DraftNewIngredient{
+			ImportNewIngredientRequest: ImportNewIngredientRequest{
+				Name:         name,
+				Unit:         line.Unit,
+				Quantity:     line.Quantity,
+				QuantityUnit: line.Unit,
+			},
+			Raw: line.Raw,
+		}
+		switch {
+		case line.Unit == "":
+			item.Warning = "没有识别到单位，请填写食材单位"
+		case line.Quantity == 0:
+			item.Warning = "用量不确定，请填写用量"
+		}
+		draft.NewIngredients = append(draft.NewIngredients, item)
+	}
+	return draft
+}
+
+// matchIngredient 按名称匹配已有食材：优先完全相同，其次是以食材名开头或结尾（如 猪肉末、小葱），取最长的
+func matchIngredient(name string, existing []*models.Ingredient) *models.Ingredient {
+	name = strings.ToLower(name)
+	var best *models.Ingredient
+	for _, ingredient := range existing {
+		candidate := strings.ToLower(ingredient.Name)
+		if candidate == "" {
+			continue
+		}
+		if candidate == name {
+			return ingredient
+		}
+		if (strings.HasPrefix(name, candidate) || strings.HasSuffix(name, candidate)) &&
+			(best == nil || len(candidate) > len(best.Name)) {
+			best = ingredient
+		}
+	}
+	return best
+}
+
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
+
+func clamp(value, lower, upper int) int {
+	if value < lower {
+		return lower
+	}
+	if value > upper {
+		return upper
+	}
+	return value
+}
@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HouseholdHandler struct {
+	householdRepo repositories.HouseholdRepository
+}
+
+func NewHouseholdHandler(householdRepo repositories.HouseholdRepository) *HouseholdHandler {
+	return &HouseholdHandler{
+		householdRepo: householdRepo,
+	}
+}
+
+type CreateHouseholdRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type UpdateHouseholdRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type CreateInvitationRequest struct {
+	Role         string `json:"role"`
+	ExpiresHours int    `json:"expires_hours"`
+	MaxUses      int    `json:"max_uses"`
+}
+
+type JoinHouseholdRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// currentMembership 返回当前令牌所选家庭中的成员身份，
+// 旧令牌没有携带家庭时回退到个人家庭
+func currentMembership(c *gin.Context, householdRepo repositories.HouseholdRepository) (*models.HouseholdMember, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return nil, false
+	}
+
+	ctx := c.Request.Context()
+	householdID := c.GetUint("household_id")
+	if householdID == 0 {
+		personal, err := householdRepo.EnsurePersonal(ctx, userID.(uint), models.PersonalHouseholdName(c.GetString("username")))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "初始化个人家庭失败"})
+			return nil, false
+		}
+		householdID = personal.ID
+	}
+
+	member, err := householdRepo.GetMember(ctx, householdID, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "不是该家庭成员，请重新切换家庭"})
+		return nil, false
+	}
+	return member, true
+}
+
+// requireOwner 解析路径中的家庭ID并校验当前用户是否为所有者
+func (h *HouseholdHandler) requireOwner(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的家庭ID"})
+		return 0, false
+	}
+
+	member, err := h.householdRepo.GetMember(c.Request.Context(), uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "不是该家庭成员"})
+		return 0, false
+	}
+	if !member.IsOwner() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只有家庭所有者可以执行此操作"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *HouseholdHandler) List(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return
+	}
+
+	memberships, err := h.householdRepo.ListMemberships(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取家庭列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    memberships,
+		"current": c.GetUint("household_id"),
+	})
+}
+
+func (h *HouseholdHandler) GetByID(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的家庭ID"})
+		return
+	}
+
+	if _, err := h.householdRepo.GetMember(c.Request.Context(), uint(id), userID.(uint)); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "不是该家庭成员"})
+		return
+	}
+
+	household, err := h.householdRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "家庭不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, household)
+}
+
+func (h *HouseholdHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return
+	}
+
+	var req CreateHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	household := &models.Household{
+		Name:    req.Name,
+		OwnerID: userID.(uint),
+	}
+
+	if err := h.householdRepo.Create(c.Request.Context(), household); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建家庭失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, household)
+}
+
+func (h *HouseholdHandler) Update(c *gin.Context) {
+	id, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	household, err := h.householdRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "家庭不存在"})
+		return
+	}
+
+	household.Name = req.Name
+	household.Members = nil
+	if err := h.householdRepo.Update(c.Request.Context(), household); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新家庭失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, household)
+}
+
+func (h *HouseholdHandler) Delete(c *gin.Context) {
+	id, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	household, err := h.householdRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "家庭不存在"})
+		return
+	}
+	if household.IsPersonal {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "个人家庭不能删除"})
+		return
+	}
+
+	if err := h.householdRepo.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除家庭失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "家庭删除成功"})
+}
+
+func (h *HouseholdHandler) CreateInvitation(c *gin.Context) {
+	id, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	var req CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = models.HouseholdRoleMember
+	}
+	// 邀请码不能直接授予所有者身份
+	if !models.IsValidHouseholdRole(req.Role) || req.Role == models.HouseholdRoleOwner {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的成员角色"})
+		return
+	}
+	if req.ExpiresHours <= 0 {
+		req.ExpiresHours = 72
+	}
+	if req.MaxUses <= 0 {
+		req.MaxUses = 1
+	}
+
+	code, err := utils.GenerateInviteCode(8)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成邀请码失败"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	invitation := &models.HouseholdInvitation{
+		HouseholdID: id,
+		Code:        code,
+		Role:        req.Role,
+		CreatedBy:   userID.(uint),
+		MaxUses:     req.MaxUses,
+		ExpiresAt:   time.Now().Add(time.Duration(req.ExpiresHours) * time.Hour),
+	}
+
+	if err := h.householdRepo.CreateInvitation(c.Request.Context(), invitation); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建邀请码失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+func (h *HouseholdHandler) Join(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return
+	}
+
+	var req JoinHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	invitation, err := h.householdRepo.GetInvitationByCode(ctx, req.Code)
+	if err != nil || invitation.Household == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "邀请码不存在"})
+		return
+	}
+	if !invitation.Usable(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "邀请码已过期或已用完"})
+		return
+	}
+	if _, err := h.householdRepo.GetMember(ctx, invitation.HouseholdID, userID.(uint)); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "已是该家庭成员"})
+		return
+	}
+
+	member, err := h.householdRepo.AcceptInvitation(ctx, invitation.ID, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "加入家庭失败"})
+		return
+	}
+	member.Household = invitation.Household
+
+	c.JSON(http.StatusCreated, member)
+}
+
+func (h *HouseholdHandler) UpdateMemberRole(c *gin.Context) {
+	id, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	memberUserID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	var req UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !models.IsValidHouseholdRole(req.Role) || req.Role == models.HouseholdRoleOwner {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的成员角色"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if uint(memberUserID) == userID.(uint) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不能修改所有者自己的角色"})
+		return
+	}
+
+	if err := h.householdRepo.UpdateMemberRole(c.Request.Context(), id, uint(memberUserID), req.Role); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "成员不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "成员角色更新成功"})
+}
+
+// RemoveMember 所有者移除成员，或成员自行退出家庭
+func (h *HouseholdHandler) RemoveMember(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的家庭ID"})
+		return
+	}
+
+	memberUserID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	current, err := h.householdRepo.GetMember(ctx, uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "不是该家庭成员"})
+		return
+	}
+
+	leaving := uint(memberUserID) == userID.(uint)
+	if leaving && current.IsOwner() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "家庭所有者不能退出家庭"})
+		return
+	}
+	if !leaving && !current.IsOwner() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只有家庭所有者可以执行此操作"})
+		return
+	}
+
+	if err := h.householdRepo.RemoveMember(ctx, uint(id), uint(memberUserID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "成员不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "成员移除成功"})
+}
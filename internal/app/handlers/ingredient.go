@@ -1,35 +1,153 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"foodcook/internal/domain/models"
 	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/units"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
 type IngredientHandler struct {
 	ingredientRepo repositories.IngredientRepository
+	dishRepo       repositories.DishRepository
 }
 
-func NewIngredientHandler(ingredientRepo repositories.IngredientRepository) *IngredientHandler {
+func NewIngredientHandler(ingredientRepo repositories.IngredientRepository, dishRepo repositories.DishRepository) *IngredientHandler {
 	return &IngredientHandler{
 		ingredientRepo: ingredientRepo,
+		dishRepo:       dishRepo,
 	}
 }
 
 type CreateIngredientRequest struct {
-	Name  string  `json:"name" binding:"required"`
-	Price float64 `json:"price" binding:"required,min=0"`
-	Unit  string  `json:"unit" binding:"required"`
+	Name        string                        `json:"name" binding:"required"`
+	Price       float64                       `json:"price" binding:"required,min=0"`
+	Unit        string                        `json:"unit" binding:"required"`
+	Conversions []IngredientConversionRequest `json:"conversions" binding:"omitempty,max=20,dive"`
+	Nutrition   *models.Nutrition             `json:"nutrition"` // 每 100 克的营养成分
+	Allergens   []string                      `json:"allergens"` // 过敏原标识，见 GET /api/dishes/dietary-options
 }
 
 type UpdateIngredientRequest struct {
-	Name  string  `json:"name"`
-	Price float64 `json:"price" binding:"min=0"`
-	Unit  string  `json:"unit"`
+	Name        string                         `json:"name"`
+	Price       float64                        `json:"price" binding:"min=0"`
+	Unit        string                         `json:"unit"`
+	Conversions *[]IngredientConversionRequest `json:"conversions" binding:"omitempty,max=20,dive"` // 为空时不修改换算关系
+	Nutrition   *models.Nutrition              `json:"nutrition"`                                   // 为空时不修改营养成分
+	Allergens   *[]string                      `json:"allergens"`                                   // 为空时不修改过敏原，传空数组表示清除
+}
+
+// IngredientConversionRequest 1 unit 约等于 quantity 个 target_unit
+type IngredientConversionRequest struct {
+	Unit       string  `json:"unit" binding:"required"`
+	Quantity   float64 `json:"quantity" binding:"required,gt=0"`
+	TargetUnit string  `json:"target_unit" binding:"required"`
+}
+
+// normalizeUnit 校验并返回单位的规范名称，失败时直接写入错误响应
+func normalizeUnit(c *gin.Context, unit string) (string, bool) {
+	name, err := units.Normalize(unit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的单位: " + unit})
+		return "", false
+	}
+	return name, true
+}
+
+// buildConversions 校验食材的换算关系，失败时直接写入错误响应
+func buildConversions(c *gin.Context, reqs []IngredientConversionRequest) ([]models.IngredientUnitConversion, bool) {
+	seen := make(map[string]bool, len(reqs))
+	conversions := make([]models.IngredientUnitConversion, 0, len(reqs))
+	for _, req := range reqs {
+		unit, ok := normalizeUnit(c, req.Unit)
+		if !ok {
+			return nil, false
+		}
+		target, ok := normalizeUnit(c, req.TargetUnit)
+		if !ok {
+			return nil, false
+		}
+		if unit == target {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "换算关系的两个单位不能相同"})
+			return nil, false
+		}
+		if seen[unit+"/"+target] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "换算关系重复: " + unit + " → " + target})
+			return nil, false
+		}
+		seen[unit+"/"+target] = true
+		conversions = append(conversions, models.IngredientUnitConversion{
+			Unit:       unit,
+			Quantity:   req.Quantity,
+			TargetUnit: target,
+		})
+	}
+	return conversions, true
+}
+
+// convertToIngredientUnit 把数量换算为食材自身的单位，unit 为空表示已经是食材的单位
+func convertToIngredientUnit(ingredient *models.Ingredient, quantity float64, unit string) (float64, error) {
+	if unit == "" || unit == ingredient.Unit {
+		return quantity, nil
+	}
+	converted, err := units.Convert(quantity, unit, ingredient.Unit, ingredient.UnitConversions())
+	if err != nil {
+		return 0, err
+	}
+	return models.RoundQuantity(converted), nil
+}
+
+// unitChangeFactor 返回食材从 oldUnit 改为新单位时数量需要乘的系数。
+// 无法换算时，食材未被菜品或库存使用则返回 1，否则写入错误响应
+func (h *IngredientHandler) unitChangeFactor(c *gin.Context, ingredient *models.Ingredient, oldUnit string) (float64, bool) {
+	factor, err := units.Convert(1, oldUnit, ingredient.Unit, ingredient.UnitConversions())
+	if err == nil {
+		return factor, true
+	}
+
+	usedInDishes, err := h.ingredientRepo.IsUsedInDishes(c.Request.Context(), ingredient.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "检查食材使用情况失败"})
+		return 0, false
+	}
+	usedInPantry, err := h.ingredientRepo.IsUsedInPantry(c.Request.Context(), ingredient.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "检查食材使用情况失败"})
+		return 0, false
+	}
+	if usedInDishes || usedInPantry {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "食材已被菜品或库存使用，无法从 " + oldUnit + " 换算为 " + ingredient.Unit + "，请先为食材添加换算关系"})
+		return 0, false
+	}
+	return 1, true
+}
+
+// conversionError 把换算失败转换为提示信息
+func conversionError(ingredient *models.Ingredient, unit string, err error) string {
+	if errors.Is(err, units.ErrUnknownUnit) {
+		return "不支持的单位: " + unit
+	}
+	return "食材「" + ingredient.Name + "」无法从 " + unit + " 换算为 " + ingredient.Unit + "，请先为食材添加换算关系"
+}
+
+// checkNutrition 校验每 100 克的营养成分，失败时直接写入错误响应
+func checkNutrition(c *gin.Context, nutrition models.Nutrition) bool {
+	if !nutrition.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "营养成分超出合理范围"})
+		return false
+	}
+	return true
+}
+
+// Units 返回支持的单位
+func (h *IngredientHandler) Units(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": units.All()})
 }
 
 func (h *IngredientHandler) List(c *gin.Context) {
@@ -57,10 +175,32 @@ func (h *IngredientHandler) Create(c *gin.Context) {
 		return
 	}
 
+	unit, ok := normalizeUnit(c, req.Unit)
+	if !ok {
+		return
+	}
+	conversions, ok := buildConversions(c, req.Conversions)
+	if !ok {
+		return
+	}
+
+	allergens, ok := normalizeAllergens(c, req.Allergens)
+	if !ok {
+		return
+	}
+
 	ingredient := &models.Ingredient{
-		Name:  req.Name,
-		Price: req.Price,
-		Unit:  req.Unit,
+		Name:        req.Name,
+		Price:       models.RoundPrice(req.Price),
+		Unit:        unit,
+		Allergens:   allergens,
+		Conversions: conversions,
+	}
+	if req.Nutrition != nil {
+		if !checkNutrition(c, *req.Nutrition) {
+			return
+		}
+		ingredient.Nutrition = req.Nutrition.Round()
 	}
 
 	if err := h.ingredientRepo.Create(c.Request.Context(), ingredient); err != nil {
@@ -90,21 +230,76 @@ func (h *IngredientHandler) Update(c *gin.Context) {
 		return
 	}
 
+	oldPrice, oldUnit := ingredient.Price, ingredient.Unit
+
 	// 更新字段
 	if req.Name != "" {
 		ingredient.Name = req.Name
 	}
 	if req.Price > 0 {
-		ingredient.Price = req.Price
+		ingredient.Price = models.RoundPrice(req.Price)
 	}
 	if req.Unit != "" {
-		ingredient.Unit = req.Unit
+		unit, ok := normalizeUnit(c, req.Unit)
+		if !ok {
+			return
+		}
+		ingredient.Unit = unit
+	}
+	if req.Nutrition != nil {
+		if !checkNutrition(c, *req.Nutrition) {
+			return
+		}
+		ingredient.Nutrition = req.Nutrition.Round()
+	}
+	if req.Allergens != nil {
+		allergens, ok := normalizeAllergens(c, *req.Allergens)
+		if !ok {
+			return
+		}
+		ingredient.Allergens = allergens
 	}
 
-	if err := h.ingredientRepo.Update(c.Request.Context(), ingredient); err != nil {
+	var conversions []models.IngredientUnitConversion
+	if req.Conversions != nil {
+		var ok bool
+		if conversions, ok = buildConversions(c, *req.Conversions); !ok {
+			return
+		}
+	}
+
+	if ingredient.Unit != oldUnit {
+		// 按修改后的换算关系把菜品用量和库存换算为新单位
+		target := *ingredient
+		if req.Conversions != nil {
+			target.Conversions = conversions
+		}
+		factor, ok := h.unitChangeFactor(c, &target, oldUnit)
+		if !ok {
+			return
+		}
+		// 没有指定新单价时按同样的比例折算，如 25 元/斤改为 0.05 元/克
+		if req.Price <= 0 {
+			ingredient.Price = models.RoundPrice(oldPrice / factor)
+		}
+		err = h.ingredientRepo.ChangeUnit(c.Request.Context(), ingredient, factor, conversions)
+	} else {
+		err = h.ingredientRepo.Update(c.Request.Context(), ingredient, conversions)
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新食材失败"})
 		return
 	}
+	if req.Conversions != nil {
+		ingredient.Conversions = conversions
+	}
+
+	// 单价变化后更新开启自动定价的菜品，失败不影响食材本身的修改
+	if ingredient.Price != oldPrice || ingredient.Unit != oldUnit {
+		if _, err := h.dishRepo.RecalculateAutoPrices(c.Request.Context(), ingredient.ID); err != nil {
+			logrus.Errorf("recalculate dish prices for ingredient %d failed: %v", ingredient.ID, err)
+		}
+	}
 
 	c.JSON(http.StatusOK, ingredient)
 }
@@ -135,3 +330,55 @@ func (h *IngredientHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "食材删除成功"})
 }
+
+// PriceHistory 食材的价格走势，支持 from/to 筛选时间范围
+func (h *IngredientHandler) PriceHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的食材ID"})
+		return
+	}
+
+	from, to, ok := parseTimeRange(c)
+	if !ok {
+		return
+	}
+
+	ingredient, err := h.ingredientRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "食材不存在"})
+		return
+	}
+
+	history, err := h.ingredientRepo.PriceHistory(c.Request.Context(), ingredient.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取价格记录失败"})
+		return
+	}
+
+	resp := gin.H{
+		"ingredient": ingredient,
+		"data":       history,
+		"current":    ingredient.Price,
+	}
+	if len(history) > 0 {
+		first, last := history[0].Price, history[len(history)-1].Price
+		low, high := first, first
+		for _, record := range history {
+			if record.Price < low {
+				low = record.Price
+			}
+			if record.Price > high {
+				high = record.Price
+			}
+		}
+		resp["min"] = low
+		resp["max"] = high
+		resp["change"] = models.RoundPrice(last - first)
+		if first > 0 {
+			resp["change_percent"] = models.RoundPrice((last - first) / first * 100)
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,622 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type MealPlanHandler struct {
+	mealPlanRepo   repositories.MealPlanRepository
+	mealRecordRepo repositories.MealRecordRepository
+	dishRepo       repositories.DishRepository
+	householdRepo  repositories.HouseholdRepository
+}
+
+func NewMealPlanHandler(mealPlanRepo repositories.MealPlanRepository, mealRecordRepo repositories.MealRecordRepository, dishRepo repositories.DishRepository, householdRepo repositories.HouseholdRepository) *MealPlanHandler {
+	return &MealPlanHandler{
+		mealPlanRepo:   mealPlanRepo,
+		mealRecordRepo: mealRecordRepo,
+		dishRepo:       dishRepo,
+		householdRepo:  householdRepo,
+	}
+}
+
+// maxMealPlanDays 单个计划最多覆盖的天数
+const maxMealPlanDays = 31
+
+// defaultMealHours 标记为吃过时，各餐次默认的用餐时间
+var defaultMealHours = map[string]int{
+	models.MealTypeBreakfast: 8,
+	models.MealTypeLunch:     12,
+	models.MealTypeSnack:     15,
+	models.MealTypeDinner:    18,
+	models.MealTypeCustom:    12,
+}
+
+type CreateMealPlanRequest struct {
+	Name      string                `json:"name" binding:"max=100"`
+	StartDate string                `json:"start_date" binding:"required"`
+	EndDate   string                `json:"end_date" binding:"required"`
+	Notes     string                `json:"notes"`
+	Slots     []MealPlanSlotRequest `json:"slots" binding:"omitempty,max=200,dive"`
+}
+
+type UpdateMealPlanRequest struct {
+	Name      *string `json:"name" binding:"omitempty,max=100"`
+	StartDate *string `json:"start_date"`
+	EndDate   *string `json:"end_date"`
+	Notes     *string `json:"notes"`
+}
+
+type MealPlanSlotRequest struct {
+	Date      string                `json:"date" binding:"required"`
+	MealType  string                `json:"meal_type" binding:"required"`
+	MealLabel string                `json:"meal_label" binding:"max=50"`
+	Servings  int                   `json:"servings" binding:"omitempty,min=1,max=99"`
+	Notes     string                `json:"notes" binding:"max=255"`
+	Dishes    []MealPlanDishRequest `json:"dishes" binding:"omitempty,max=50,dive"`
+}
+
+type MealPlanDishRequest struct {
+	DishID   uint `json:"dish_id" binding:"required"`
+	Quantity int  `json:"quantity" binding:"omitempty,min=1,max=99"`
+}
+
+type EatMealPlanSlotRequest struct {
+	EatenAt      *time.Time               `json:"eaten_at"` // 默认为计划日期中该餐次的常规时间
+	Thoughts     string                   `json:"thoughts"`
+	Participants []MealParticipantRequest `json:"participants" binding:"omitempty,max=50,dive"` // 省略时为记录人自己
+}
+
+// parsePlanDate 解析计划日期，只接受 2006-01-02 格式
+func parsePlanDate(value string) (time.Time, error) {
+	t, err := time.ParseInLocation(models.PlanDateLayout, value, time.Local)
+	if err != nil || t.Format(models.PlanDateLayout) != value {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return t, nil
+}
+
+// checkPlanRange 校验计划的起止日期，失败时直接写入错误响应
+func checkPlanRange(c *gin.Context, startDate, endDate string) bool {
+	start, err := parsePlanDate(startDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的开始日期"})
+		return false
+	}
+	end, err := parsePlanDate(endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的结束日期"})
+		return false
+	}
+	if end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "结束日期不能早于开始日期"})
+		return false
+	}
+	if end.Sub(start) >= maxMealPlanDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("计划最多覆盖 %d 天", maxMealPlanDays)})
+		return false
+	}
+	return true
+}
+
+// shiftPlanDate 把计划日期平移若干天
+func shiftPlanDate(date string, days int) string {
+	t, err := parsePlanDate(date)
+	if err != nil {
+		return date
+	}
+	return t.AddDate(0, 0, days).Format(models.PlanDateLayout)
+}
+
+// loadPlan 加载当前家庭的计划，write 为 true 时要求可写成员，失败时直接写入错误响应
+func (h *MealPlanHandler) loadPlan(c *gin.Context, write bool) (*models.MealPlan, *models.HouseholdMember, bool) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return nil, nil, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的计划ID"})
+		return nil, nil, false
+	}
+
+	plan, err := h.mealPlanRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil || plan.HouseholdID != member.HouseholdID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用餐计划不存在"})
+		return nil, nil, false
+	}
+
+	if write && !member.CanWrite() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只读成员不能修改用餐计划"})
+		return nil, nil, false
+	}
+	return plan, member, true
+}
+
+// loadSlot 加载计划中的餐次，失败时直接写入错误响应
+func (h *MealPlanHandler) loadSlot(c *gin.Context, plan *models.MealPlan) (*models.MealPlanSlot, bool) {
+	id, err := strconv.ParseUint(c.Param("slot_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的餐次ID"})
+		return nil, false
+	}
+
+	slot, err := h.mealPlanRepo.GetSlot(c.Request.Context(), uint(id))
+	if err != nil || slot.MealPlanID != plan.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "计划餐次不存在"})
+		return nil, false
+	}
+	return slot, true
+}
+
+// buildPlanSlot 校验餐次请求并通过菜品仓储检查菜品，失败时直接写入错误响应
+func buildPlanSlot(c *gin.Context, dishRepo repositories.DishRepository, plan *models.MealPlan, req MealPlanSlotRequest) (*models.MealPlanSlot, bool) {
+	if _, err := parsePlanDate(req.Date); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的餐次日期"})
+		return nil, false
+	}
+	if !plan.Contains(req.Date) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "餐次日期不在计划范围内"})
+		return nil, false
+	}
+
+	mealType, mealLabel, ok := resolveMealType(c, req.MealType, req.MealLabel, time.Time{})
+	if !ok {
+		return nil, false
+	}
+
+	slot := &models.MealPlanSlot{
+		MealPlanID: plan.ID,
+		Date:       req.Date,
+		MealType:   mealType,
+		MealLabel:  mealLabel,
+		Servings:   req.Servings,
+		Notes:      req.Notes,
+	}
+	if slot.Servings == 0 {
+		slot.Servings = 1
+	}
+
+	seen := make(map[uint]bool, len(req.Dishes))
+	for _, item := range req.Dishes {
+		if seen[item.DishID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "菜品重复，请合并为一行并填写数量"})
+			return nil, false
+		}
+		seen[item.DishID] = true
+
+		if _, err := dishRepo.GetByID(c.Request.Context(), item.DishID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "菜品不存在"})
+			return nil, false
+		}
+
+		quantity := item.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		slot.Dishes = append(slot.Dishes, models.MealPlanSlotDish{DishID: item.DishID, Quantity: quantity})
+	}
+	return slot, true
+}
+
+// hasSameMeal 计划中是否已有同一天的同一餐，exceptID 为正在修改的餐次
+func hasSameMeal(plan *models.MealPlan, slot *models.MealPlanSlot, exceptID uint) bool {
+	for i := range plan.Slots {
+		if plan.Slots[i].ID != exceptID && plan.Slots[i].SameMeal(slot) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *MealPlanHandler) List(c *gin.Context) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	from, to := c.Query("from"), c.Query("to")
+	for _, date := range []string{from, to} {
+		if date == "" {
+			continue
+		}
+		if _, err := parsePlanDate(date); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的日期"})
+			return
+		}
+	}
+
+	plans, total, err := h.mealPlanRepo.List(c.Request.Context(), member.HouseholdID, from, to, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用餐计划失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   plans,
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
+	})
+}
+
+func (h *MealPlanHandler) GetByID(c *gin.Context) {
+	plan, _, ok := h.loadPlan(c, false)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h *MealPlanHandler) Create(c *gin.Context) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return
+	}
+	if !member.CanWrite() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只读成员不能创建用餐计划"})
+		return
+	}
+
+	var req CreateMealPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkPlanRange(c, req.StartDate, req.EndDate) {
+		return
+	}
+
+	plan := &models.MealPlan{
+		HouseholdID: member.HouseholdID,
+		UserID:      member.UserID,
+		Name:        req.Name,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Notes:       req.Notes,
+	}
+	if plan.Name == "" {
+		plan.Name = fmt.Sprintf("%s 至 %s", plan.StartDate, plan.EndDate)
+	}
+
+	for _, slotReq := range req.Slots {
+		slot, ok := buildPlanSlot(c, h.dishRepo, plan, slotReq)
+		if !ok {
+			return
+		}
+		if hasSameMeal(plan, slot, 0) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "同一天的同一餐只能安排一次"})
+			return
+		}
+		plan.Slots = append(plan.Slots, *slot)
+	}
+
+	if err := h.mealPlanRepo.Create(c.Request.Context(), plan); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建用餐计划失败"})
+		return
+	}
+
+	created, err := h.mealPlanRepo.GetByID(c.Request.Context(), plan.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用餐计划失败"})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (h *MealPlanHandler) Update(c *gin.Context) {
+	plan, _, ok := h.loadPlan(c, true)
+	if !ok {
+		return
+	}
+
+	var req UpdateMealPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != nil && *req.Name != "" {
+		plan.Name = *req.Name
+	}
+	if req.Notes != nil {
+		plan.Notes = *req.Notes
+	}
+	if req.StartDate != nil || req.EndDate != nil {
+		if req.StartDate != nil {
+			plan.StartDate = *req.StartDate
+		}
+		if req.EndDate != nil {
+			plan.EndDate = *req.EndDate
+		}
+		if !checkPlanRange(c, plan.StartDate, plan.EndDate) {
+			return
+		}
+		// 缩小范围时不能把已有餐次留在计划外
+		for i := range plan.Slots {
+			if !plan.Contains(plan.Slots[i].Date) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "计划范围外还有餐次，请先删除"})
+				return
+			}
+		}
+	}
+
+	if err := h.mealPlanRepo.Update(c.Request.Context(), plan); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新用餐计划失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h *MealPlanHandler) Delete(c *gin.Context) {
+	plan, _, ok := h.loadPlan(c, true)
+	if !ok {
+		return
+	}
+
+	if err := h.mealPlanRepo.Delete(c.Request.Context(), plan.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除用餐计划失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "用餐计划删除成功"})
+}
+
+func (h *MealPlanHandler) CreateSlot(c *gin.Context) {
+	plan, _, ok := h.loadPlan(c, true)
+	if !ok {
+		return
+	}
+
+	var req MealPlanSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slot, ok := buildPlanSlot(c, h.dishRepo, plan, req)
+	if !ok {
+		return
+	}
+	if hasSameMeal(plan, slot, 0) {
+		c.JSON(http.StatusConflict, gin.H{"error": "同一天的同一餐只能安排一次"})
+		return
+	}
+
+	if err := h.mealPlanRepo.CreateSlots(c.Request.Context(), []*models.MealPlanSlot{slot}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建计划餐次失败"})
+		return
+	}
+
+	created, err := h.mealPlanRepo.GetSlot(c.Request.Context(), slot.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取计划餐次失败"})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (h *MealPlanHandler) UpdateSlot(c *gin.Context) {
+	plan, _, ok := h.loadPlan(c, true)
+	if !ok {
+		return
+	}
+	existing, ok := h.loadSlot(c, plan)
+	if !ok {
+		return
+	}
+
+	var req MealPlanSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slot, ok := buildPlanSlot(c, h.dishRepo, plan, req)
+	if !ok {
+		return
+	}
+	if hasSameMeal(plan, slot, existing.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "同一天的同一餐只能安排一次"})
+		return
+	}
+	slot.ID = existing.ID
+	slot.MealRecordID = existing.MealRecordID
+	slot.CreatedAt = existing.CreatedAt
+
+	if err := h.mealPlanRepo.UpdateSlot(c.Request.Context(), slot); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新计划餐次失败"})
+		return
+	}
+
+	updated, err := h.mealPlanRepo.GetSlot(c.Request.Context(), slot.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取计划餐次失败"})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *MealPlanHandler) DeleteSlot(c *gin.Context) {
+	plan, _, ok := h.loadPlan(c, true)
+	if !ok {
+		return
+	}
+	slot, ok := h.loadSlot(c, plan)
+	if !ok {
+		return
+	}
+
+	if err := h.mealPlanRepo.DeleteSlot(c.Request.Context(), slot.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除计划餐次失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "计划餐次删除成功"})
+}
+
+// CopyLastWeek 把前一周同一时间段的餐次复制到当前计划，计划中已有的餐次保持不变
+func (h *MealPlanHandler) CopyLastWeek(c *gin.Context) {
+	plan, _, ok := h.loadPlan(c, true)
+	if !ok {
+		return
+	}
+
+	from, to := shiftPlanDate(plan.StartDate, -7), shiftPlanDate(plan.EndDate, -7)
+	sources, err := h.mealPlanRepo.ListSlots(c.Request.Context(), plan.HouseholdID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取上周计划失败"})
+		return
+	}
+
+	var slots []*models.MealPlanSlot
+	skipped := 0
+	for _, source := range sources {
+		slot := &models.MealPlanSlot{
+			MealPlanID: plan.ID,
+			Date:       shiftPlanDate(source.Date, 7),
+			MealType:   source.MealType,
+			MealLabel:  source.MealLabel,
+			Servings:   source.Servings,
+			Notes:      source.Notes,
+		}
+		if hasSameMeal(plan, slot, 0) {
+			skipped++
+			continue
+		}
+		for _, dish := range source.Dishes {
+			// 已删除的菜品不再复制
+			if dish.Dish == nil {
+				continue
+			}
+			slot.Dishes = append(slot.Dishes, models.MealPlanSlotDish{DishID: dish.DishID, Quantity: dish.Quantity})
+		}
+		plan.Slots = append(plan.Slots, *slot)
+		slots = append(slots, slot)
+	}
+
+	if len(slots) > 0 {
+		if err := h.mealPlanRepo.CreateSlots(c.Request.Context(), slots); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "复制上周计划失败"})
+			return
+		}
+	}
+
+	updated, err := h.mealPlanRepo.GetByID(c.Request.Context(), plan.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用餐计划失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"copied":    len(slots),
+		"skipped":   skipped,
+		"meal_plan": updated,
+	})
+}
+
+// MarkEaten 把计划中的一餐转为用餐记录，菜品和数量与计划一致，单价取当前菜品价格
+func (h *MealPlanHandler) MarkEaten(c *gin.Context) {
+	plan, member, ok := h.loadPlan(c, true)
+	if !ok {
+		return
+	}
+	slot, ok := h.loadSlot(c, plan)
+	if !ok {
+		return
+	}
+	if slot.Eaten() {
+		c.JSON(http.StatusConflict, gin.H{"error": "该餐次已标记为吃过"})
+		return
+	}
+
+	var req EatMealPlanSlotRequest
+	// 请求体可以为空
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var lines []MealDishLineRequest
+	for _, dish := range slot.Dishes {
+		lines = append(lines, MealDishLineRequest{DishID: dish.DishID, Quantity: dish.Quantity})
+	}
+	if len(lines) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "该餐次还没有安排菜品"})
+		return
+	}
+	dishes, totalPrice, ok := buildDishLines(c, h.dishRepo, lines, nil)
+	if !ok {
+		return
+	}
+
+	participantReqs := req.Participants
+	if participantReqs == nil {
+		participantReqs = []MealParticipantRequest{{UserID: &member.UserID}}
+	}
+	participants, ok := buildParticipants(c, h.householdRepo, plan.HouseholdID, participantReqs)
+	if !ok {
+		return
+	}
+
+	eatenAt := time.Now()
+	if req.EatenAt != nil {
+		eatenAt = *req.EatenAt
+	} else if date, err := parsePlanDate(slot.Date); err == nil {
+		eatenAt = date.Add(time.Duration(defaultMealHours[slot.MealType]) * time.Hour)
+	}
+
+	mealRecord := &models.MealRecord{
+		UserID:       member.UserID,
+		HouseholdID:  plan.HouseholdID,
+		TotalPrice:   totalPrice,
+		Thoughts:     req.Thoughts,
+		EatenAt:      eatenAt,
+		MealType:     slot.MealType,
+		MealLabel:    slot.MealLabel,
+		Participants: participants,
+	}
+	if err := h.mealRecordRepo.Create(c.Request.Context(), mealRecord, dishes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建用餐记录失败"})
+		return
+	}
+
+	// 并发标记时只保留一条用餐记录
+	if err := h.mealPlanRepo.MarkSlotEaten(c.Request.Context(), slot.ID, mealRecord.ID); err != nil {
+		// 撤销刚创建的用餐记录
+		if delErr := h.mealRecordRepo.Delete(c.Request.Context(), mealRecord.ID); delErr != nil {
+			logrus.Errorf("delete meal record %d for slot %d failed: %v", mealRecord.ID, slot.ID, delErr)
+		}
+		if errors.Is(err, repositories.ErrSlotEaten) {
+			c.JSON(http.StatusConflict, gin.H{"error": "该餐次已标记为吃过"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "标记餐次失败"})
+		return
+	}
+	slot.MealRecordID = &mealRecord.ID
+
+	if created, err := h.mealRecordRepo.GetByID(c.Request.Context(), mealRecord.ID); err == nil {
+		mealRecord = created
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"slot":        slot,
+		"meal_record": mealRecord,
+	})
+}
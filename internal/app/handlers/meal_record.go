@@ -3,7 +3,10 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"foodcook/internal/app/middleware"
 	"foodcook/internal/domain/models"
 	"foodcook/internal/domain/repositories"
 
@@ -13,37 +16,147 @@ import (
 type MealRecordHandler struct {
 	mealRecordRepo repositories.MealRecordRepository
 	dishRepo       repositories.DishRepository
+	householdRepo  repositories.HouseholdRepository
+	budgetRepo     repositories.BudgetRepository
+	statsRepo      repositories.StatsRepository
+	categoryRepo   repositories.CategoryRepository
 }
 
-func NewMealRecordHandler(mealRecordRepo repositories.MealRecordRepository, dishRepo repositories.DishRepository) *MealRecordHandler {
+func NewMealRecordHandler(mealRecordRepo repositories.MealRecordRepository, dishRepo repositories.DishRepository, householdRepo repositories.HouseholdRepository, budgetRepo repositories.BudgetRepository, statsRepo repositories.StatsRepository, categoryRepo repositories.CategoryRepository) *MealRecordHandler {
 	return &MealRecordHandler{
 		mealRecordRepo: mealRecordRepo,
 		dishRepo:       dishRepo,
+		householdRepo:  householdRepo,
+		budgetRepo:     budgetRepo,
+		statsRepo:      statsRepo,
+		categoryRepo:   categoryRepo,
 	}
 }
 
+// maxMealRecordPhotos 每条用餐记录最多的照片数量
+const maxMealRecordPhotos = 20
+
 type CreateMealRecordRequest struct {
-	DishIDs  []uint `json:"dish_ids" binding:"required"`
-	Thoughts string `json:"thoughts"`
-	ImageURL string `json:"image_url"`
+	Dishes       []MealDishLineRequest    `json:"dishes" binding:"omitempty,dive"`
+	DishIDs      []uint                   `json:"dish_ids"` // 兼容旧客户端，每个ID计一份，重复ID累加数量
+	Thoughts     string                   `json:"thoughts"`
+	ImageURL     string                   `json:"image_url"` // 兼容旧客户端，作为第一张照片保存
+	Photos       []CreateMealPhotoRequest `json:"photos" binding:"omitempty,max=20,dive"`
+	EatenAt      *time.Time               `json:"eaten_at"`  // 默认为当前时间
+	MealType     string                   `json:"meal_type"` // 默认根据用餐时间推断
+	MealLabel    string                   `json:"meal_label" binding:"max=50"`
+	Participants []MealParticipantRequest `json:"participants" binding:"omitempty,max=50,dive"` // 省略时为记录人自己
+	DeductPantry bool                     `json:"deduct_pantry"`                                // 按菜品用量扣减库存
+}
+
+// MealRecordResponse 创建用餐记录的响应，在记录之外附带本次操作的结果
+type MealRecordResponse struct {
+	*models.MealRecord
+	PantryUsage []repositories.PantryUsage `json:"pantry_usage,omitempty"`
+	// DietaryWarnings 参与者的过敏原、饮食标签或辣度限制与菜品冲突
+	DietaryWarnings []DietaryWarning `json:"dietary_warnings,omitempty"`
+	// BudgetWarnings 这条记录使当月的家庭或个人预算达到 80% 或 100%
+	BudgetWarnings []BudgetWarning `json:"budget_warnings,omitempty"`
 }
 
 type UpdateMealRecordRequest struct {
-	Thoughts string `json:"thoughts"`
-	ImageURL string `json:"image_url"`
+	Thoughts     *string                   `json:"thoughts"`
+	Dishes       []MealDishLineRequest     `json:"dishes" binding:"omitempty,dive"` // 为空时不修改菜品行
+	EatenAt      *time.Time                `json:"eaten_at"`
+	MealType     *string                   `json:"meal_type"`
+	MealLabel    *string                   `json:"meal_label" binding:"omitempty,max=50"`
+	Participants *[]MealParticipantRequest `json:"participants" binding:"omitempty,max=50,dive"` // 为空时不修改参与者
 }
 
-func (h *MealRecordHandler) List(c *gin.Context) {
+// MealParticipantRequest 用餐参与者，user_id 和 guest_name 只填其一
+type MealParticipantRequest struct {
+	UserID    *uint  `json:"user_id"`
+	GuestName string `json:"guest_name" binding:"max=50"`
+}
+
+// MealDishLineRequest 用餐记录中的一行菜品，UnitPrice 为空时使用菜品当前价格
+type MealDishLineRequest struct {
+	DishID    uint     `json:"dish_id" binding:"required"`
+	Quantity  int      `json:"quantity" binding:"omitempty,min=1,max=999"`
+	UnitPrice *float64 `json:"unit_price" binding:"omitempty,min=0"`
+}
+
+type CreateMealPhotoRequest struct {
+	URL              string `json:"url" binding:"required,max=255"`
+	Caption          string `json:"caption" binding:"max=255"`
+	MealRecordDishID *uint  `json:"meal_record_dish_id"`
+}
+
+type UpdateMealPhotoRequest struct {
+	Caption          string `json:"caption" binding:"max=255"`
+	MealRecordDishID *uint  `json:"meal_record_dish_id"`
+}
+
+type ReorderMealPhotosRequest struct {
+	PhotoIDs []uint `json:"photo_ids" binding:"required"`
+}
+
+type CreateMealRecordCommentRequest struct {
+	Content string `json:"content" binding:"required,max=1000"`
+}
+
+// loadAccessible 加载用餐记录并校验当前用户是否为记录所属家庭的成员，
+// 失败时直接写入错误响应。readAny 为 true 时拥有 meal:read:any 权限的非成员也可访问，
+// 此时返回的成员身份为 nil
+func (h *MealRecordHandler) loadAccessible(c *gin.Context, param string, readAny bool) (*models.MealRecord, *models.HouseholdMember, bool) {
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return nil, nil, false
+	}
+
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用餐记录ID"})
+		return nil, nil, false
+	}
+
+	mealRecord, err := h.mealRecordRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用餐记录不存在"})
+		return nil, nil, false
+	}
+
+	// 检查权限
+	member, err := h.householdRepo.GetMember(c.Request.Context(), mealRecord.HouseholdID, userID.(uint))
+	if err != nil {
+		if readAny && middleware.HasPermission(c, models.PermissionMealReadAny) {
+			return mealRecord, nil, true
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权访问此用餐记录"})
+		return nil, nil, false
+	}
+	return mealRecord, member, true
+}
+
+// canModify 记录作者或家庭所有者可以修改和删除记录
+func canModify(mealRecord *models.MealRecord, member *models.HouseholdMember) bool {
+	if member.IsOwner() {
+		return true
+	}
+	return member.CanWrite() && mealRecord.UserID == member.UserID
+}
+
+func (h *MealRecordHandler) List(c *gin.Context) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
 		return
 	}
 
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
-	mealRecords, total, err := h.mealRecordRepo.List(c.Request.Context(), userID.(uint), offset, limit)
+	filter, ok := parseMealRecordFilter(c)
+	if !ok {
+		return
+	}
+
+	mealRecords, total, err := h.mealRecordRepo.List(c.Request.Context(), member.HouseholdID, filter, offset, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用餐记录失败"})
 		return
@@ -57,28 +170,140 @@ func (h *MealRecordHandler) List(c *gin.Context) {
 	})
 }
 
-func (h *MealRecordHandler) GetByID(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
-		return
+// parseMealRecordFilter 解析列表的筛选参数，失败时直接写入错误响应。
+// from/to 可以是日期（to 包含当天）或 RFC3339 时间
+func parseMealRecordFilter(c *gin.Context) (repositories.MealRecordFilter, bool) {
+	var filter repositories.MealRecordFilter
+
+	var ok bool
+	if filter.From, filter.To, ok = parseTimeRange(c); !ok {
+		return filter, false
 	}
 
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用餐记录ID"})
-		return
+	if mealType := c.Query("meal_type"); mealType != "" {
+		if !models.IsValidMealType(mealType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的餐次"})
+			return filter, false
+		}
+		filter.MealType = mealType
 	}
 
-	mealRecord, err := h.mealRecordRepo.GetByID(c.Request.Context(), uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "用餐记录不存在"})
-		return
+	if participantID := c.Query("participant_id"); participantID != "" {
+		id, err := strconv.ParseUint(participantID, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的参与者ID"})
+			return filter, false
+		}
+		filter.ParticipantID = uint(id)
+	}
+	filter.Guest = strings.TrimSpace(c.Query("guest"))
+
+	switch c.DefaultQuery("order", "desc") {
+	case "asc":
+		filter.Ascending = true
+	case "desc":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的排序方式"})
+		return filter, false
 	}
+	return filter, true
+}
 
-	// 检查权限
-	if mealRecord.UserID != userID.(uint) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "无权访问此用餐记录"})
+// parseTimeRange 解析查询参数 from/to，只有日期的 to 包含当天，失败时直接写入错误响应
+func parseTimeRange(c *gin.Context) (*time.Time, *time.Time, bool) {
+	var from, to *time.Time
+	if value := c.Query("from"); value != "" {
+		t, _, err := parseMealTime(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的开始时间"})
+			return nil, nil, false
+		}
+		from = &t
+	}
+	if value := c.Query("to"); value != "" {
+		t, dateOnly, err := parseMealTime(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的结束时间"})
+			return nil, nil, false
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = &t
+	}
+	return from, to, true
+}
+
+// parseMealTime 解析日期或 RFC3339 时间，日期按服务器本地时区处理
+func parseMealTime(value string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// resolveMealType 校验餐次，未指定时根据用餐时间推断。只有自定义餐次保留名称
+func resolveMealType(c *gin.Context, mealType, label string, eatenAt time.Time) (string, string, bool) {
+	label = strings.TrimSpace(label)
+	if mealType == "" {
+		mealType = models.MealTypeAt(eatenAt)
+		if label != "" {
+			mealType = models.MealTypeCustom
+		}
+	}
+	if !models.IsValidMealType(mealType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的餐次"})
+		return "", "", false
+	}
+	if mealType != models.MealTypeCustom {
+		return mealType, "", true
+	}
+	if label == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "自定义餐次需要填写名称"})
+		return "", "", false
+	}
+	return mealType, label, true
+}
+
+// buildParticipants 校验参与者，家庭成员必须属于记录所在家庭，失败时直接写入错误响应
+func buildParticipants(c *gin.Context, householdRepo repositories.HouseholdRepository, householdID uint, reqs []MealParticipantRequest) ([]models.MealRecordParticipant, bool) {
+	participants := []models.MealRecordParticipant{}
+	seenUsers := make(map[uint]bool)
+	seenGuests := make(map[string]bool)
+	for _, req := range reqs {
+		guestName := strings.TrimSpace(req.GuestName)
+		if (req.UserID == nil) == (guestName == "") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "参与者需要填写 user_id 或 guest_name 其中之一"})
+			return nil, false
+		}
+
+		if req.UserID != nil {
+			userID := *req.UserID
+			if seenUsers[userID] {
+				continue
+			}
+			if _, err := householdRepo.GetMember(c.Request.Context(), householdID, userID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "参与者不是该家庭成员"})
+				return nil, false
+			}
+			seenUsers[userID] = true
+			participants = append(participants, models.MealRecordParticipant{UserID: &userID})
+			continue
+		}
+
+		if seenGuests[guestName] {
+			continue
+		}
+		seenGuests[guestName] = true
+		participants = append(participants, models.MealRecordParticipant{GuestName: guestName})
+	}
+	return participants, true
+}
+
+func (h *MealRecordHandler) GetByID(c *gin.Context) {
+	mealRecord, _, ok := h.loadAccessible(c, "id", true)
+	if !ok {
 		return
 	}
 
@@ -86,9 +311,12 @@ func (h *MealRecordHandler) GetByID(c *gin.Context) {
 }
 
 func (h *MealRecordHandler) Create(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return
+	}
+	if !member.CanWrite() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只读成员不能创建用餐记录"})
 		return
 	}
 
@@ -98,58 +326,162 @@ func (h *MealRecordHandler) Create(c *gin.Context) {
 		return
 	}
 
-	// 计算总价格
-	var totalPrice float64
-	for _, dishID := range req.DishIDs {
-		dish, err := h.dishRepo.GetByID(c.Request.Context(), dishID)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "菜品不存在"})
-			return
+	lines := req.Dishes
+	if len(lines) == 0 {
+		// 旧格式：dish_ids 中重复的菜品合并为一行
+		index := make(map[uint]int)
+		for _, dishID := range req.DishIDs {
+			if i, ok := index[dishID]; ok {
+				lines[i].Quantity++
+				continue
+			}
+			index[dishID] = len(lines)
+			lines = append(lines, MealDishLineRequest{DishID: dishID, Quantity: 1})
 		}
-		totalPrice += dish.Price
+	}
+
+	dishes, totalPrice, ok := buildDishLines(c, h.dishRepo, lines, nil)
+	if !ok {
+		return
+	}
+
+	eatenAt := time.Now()
+	if req.EatenAt != nil {
+		eatenAt = *req.EatenAt
+	}
+	mealType, mealLabel, ok := resolveMealType(c, req.MealType, req.MealLabel, eatenAt)
+	if !ok {
+		return
+	}
+
+	participantReqs := req.Participants
+	if participantReqs == nil {
+		participantReqs = []MealParticipantRequest{{UserID: &member.UserID}}
+	}
+	participants, ok := buildParticipants(c, h.householdRepo, member.HouseholdID, participantReqs)
+	if !ok {
+		return
 	}
 
 	mealRecord := &models.MealRecord{
-		UserID:     userID.(uint),
-		TotalPrice: totalPrice,
-		Thoughts:   req.Thoughts,
-		ImageURL:   req.ImageURL,
+		UserID:       member.UserID,
+		HouseholdID:  member.HouseholdID,
+		TotalPrice:   totalPrice,
+		Thoughts:     req.Thoughts,
+		EatenAt:      eatenAt,
+		MealType:     mealType,
+		MealLabel:    mealLabel,
+		Participants: participants,
 	}
 
-	if err := h.mealRecordRepo.Create(c.Request.Context(), mealRecord, req.DishIDs); err != nil {
+	// 照片与记录一起创建，创建时还没有菜品行，不能关联到具体菜品
+	if req.ImageURL != "" {
+		req.Photos = append([]CreateMealPhotoRequest{{URL: req.ImageURL}}, req.Photos...)
+	}
+	if len(req.Photos) > maxMealRecordPhotos {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "照片数量超过限制"})
+		return
+	}
+	for i, photo := range req.Photos {
+		mealRecord.Photos = append(mealRecord.Photos, models.MealRecordPhoto{
+			UserID:    member.UserID,
+			URL:       photo.URL,
+			Caption:   photo.Caption,
+			SortOrder: i,
+		})
+	}
+
+	var usages []repositories.PantryUsage
+	if req.DeductPantry {
+		// 记录和库存扣减在同一事务中完成
+		if usages, ok = pantryUsages(c, h.dishRepo, dishes); !ok {
+			return
+		}
+		var err error
+		if usages, err = h.mealRecordRepo.CreateWithPantry(c.Request.Context(), mealRecord, dishes, usages); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "创建用餐记录失败"})
+			return
+		}
+	} else if err := h.mealRecordRepo.Create(c.Request.Context(), mealRecord, dishes); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建用餐记录失败"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, mealRecord)
+	if created, err := h.mealRecordRepo.GetByID(c.Request.Context(), mealRecord.ID); err == nil {
+		mealRecord = created
+	}
+	c.JSON(http.StatusCreated, MealRecordResponse{
+		MealRecord:      mealRecord,
+		PantryUsage:     usages,
+		DietaryWarnings: dietaryWarnings(c.Request.Context(), h.dishRepo, mealRecord.Participants, dishes),
+		BudgetWarnings:  budgetWarnings(c.Request.Context(), h.budgetRepo, h.statsRepo, h.categoryRepo, mealRecord),
+	})
 }
 
-func (h *MealRecordHandler) Delete(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
-		return
+// buildDishLines 校验菜品行并计算总价，失败时直接写入错误响应。
+// 未指定单价时，已有记录中的菜品沿用原单价快照，新菜品使用菜品当前价格
+func buildDishLines(c *gin.Context, dishRepo repositories.DishRepository, lines []MealDishLineRequest, existing []models.MealRecordDish) ([]repositories.MealRecordDishRequest, float64, bool) {
+	if len(lines) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "至少需要一道菜品"})
+		return nil, 0, false
 	}
 
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用餐记录ID"})
-		return
+	snapshots := make(map[uint]float64, len(existing))
+	for _, item := range existing {
+		snapshots[item.DishID] = item.UnitPrice
 	}
 
-	mealRecord, err := h.mealRecordRepo.GetByID(c.Request.Context(), uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "用餐记录不存在"})
+	seen := make(map[uint]bool, len(lines))
+	dishes := make([]repositories.MealRecordDishRequest, 0, len(lines))
+	var totalPrice float64
+	for _, line := range lines {
+		if seen[line.DishID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "菜品重复，请合并为一行并填写数量"})
+			return nil, 0, false
+		}
+		seen[line.DishID] = true
+
+		quantity := line.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+
+		// 记录中已有的菜品行即使菜品已删除也可以继续修改，其余菜品都需要存在
+		snapshot, ok := snapshots[line.DishID]
+		if !ok {
+			dish, err := dishRepo.GetByID(c.Request.Context(), line.DishID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "菜品不存在"})
+				return nil, 0, false
+			}
+			snapshot = dish.Price
+		}
+
+		unitPrice := snapshot
+		if line.UnitPrice != nil {
+			unitPrice = models.RoundPrice(*line.UnitPrice)
+		}
+
+		item := repositories.MealRecordDishRequest{DishID: line.DishID, Quantity: quantity, UnitPrice: unitPrice}
+		dishes = append(dishes, item)
+		totalPrice += unitPrice * float64(quantity)
+	}
+	return dishes, models.RoundPrice(totalPrice), true
+}
+
+func (h *MealRecordHandler) Delete(c *gin.Context) {
+	mealRecord, member, ok := h.loadAccessible(c, "id", false)
+	if !ok {
 		return
 	}
 
 	// 检查权限
-	if mealRecord.UserID != userID.(uint) {
+	if !canModify(mealRecord, member) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "无权删除此用餐记录"})
 		return
 	}
 
-	if err := h.mealRecordRepo.Delete(c.Request.Context(), uint(id)); err != nil {
+	if err := h.mealRecordRepo.Delete(c.Request.Context(), mealRecord.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除用餐记录失败"})
 		return
 	}
@@ -158,44 +490,313 @@ func (h *MealRecordHandler) Delete(c *gin.Context) {
 }
 
 func (h *MealRecordHandler) Update(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+	mealRecord, member, ok := h.loadAccessible(c, "id", false)
+	if !ok {
 		return
 	}
 
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	var req UpdateMealRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 检查权限
+	if !canModify(mealRecord, member) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权更新此用餐记录"})
+		return
+	}
+
+	// 更新字段
+	if req.Thoughts != nil {
+		mealRecord.Thoughts = *req.Thoughts
+	}
+	if req.EatenAt != nil {
+		mealRecord.EatenAt = *req.EatenAt
+	}
+	if req.MealType != nil || req.MealLabel != nil {
+		mealType, mealLabel := mealRecord.MealType, mealRecord.MealLabel
+		if req.MealType != nil {
+			mealType = *req.MealType
+		}
+		if req.MealLabel != nil {
+			mealLabel = *req.MealLabel
+		}
+		if mealType, mealLabel, ok = resolveMealType(c, mealType, mealLabel, mealRecord.EatenAt); !ok {
+			return
+		}
+		mealRecord.MealType, mealRecord.MealLabel = mealType, mealLabel
+	}
+
+	var participants []models.MealRecordParticipant
+	if req.Participants != nil {
+		if participants, ok = buildParticipants(c, h.householdRepo, mealRecord.HouseholdID, *req.Participants); !ok {
+			return
+		}
+	}
+
+	var dishes []repositories.MealRecordDishRequest
+	if len(req.Dishes) > 0 {
+		var totalPrice float64
+		if dishes, totalPrice, ok = buildDishLines(c, h.dishRepo, req.Dishes, mealRecord.Dishes); !ok {
+			return
+		}
+		mealRecord.TotalPrice = totalPrice
+	}
+
+	// 记录、菜品行和参与者在同一事务中更新
+	if err := h.mealRecordRepo.UpdateWithDishes(c.Request.Context(), mealRecord, dishes, participants); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新用餐记录失败"})
+		return
+	}
+
+	updated, err := h.mealRecordRepo.GetByID(c.Request.Context(), mealRecord.ID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用餐记录ID"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用餐记录失败"})
 		return
 	}
+	c.JSON(http.StatusOK, updated)
+}
 
-	var req UpdateMealRecordRequest
+func (h *MealRecordHandler) ListComments(c *gin.Context) {
+	mealRecord, _, ok := h.loadAccessible(c, "id", true)
+	if !ok {
+		return
+	}
+
+	comments, err := h.mealRecordRepo.ListComments(c.Request.Context(), mealRecord.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取评论失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": comments})
+}
+
+// CreateComment 家庭中的所有成员（包括只读成员）都可以评论
+func (h *MealRecordHandler) CreateComment(c *gin.Context) {
+	mealRecord, member, ok := h.loadAccessible(c, "id", false)
+	if !ok {
+		return
+	}
+
+	var req CreateMealRecordCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	mealRecord, err := h.mealRecordRepo.GetByID(c.Request.Context(), uint(id))
+	comment := &models.MealRecordComment{
+		MealRecordID: mealRecord.ID,
+		UserID:       member.UserID,
+		Content:      req.Content,
+	}
+
+	if err := h.mealRecordRepo.AddComment(c.Request.Context(), comment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "发表评论失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+func (h *MealRecordHandler) DeleteComment(c *gin.Context) {
+	mealRecord, member, ok := h.loadAccessible(c, "id", false)
+	if !ok {
+		return
+	}
+
+	commentID, err := strconv.ParseUint(c.Param("comment_id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "用餐记录不存在"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的评论ID"})
+		return
+	}
+
+	comment, err := h.mealRecordRepo.GetComment(c.Request.Context(), uint(commentID))
+	if err != nil || comment.MealRecordID != mealRecord.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "评论不存在"})
+		return
+	}
+
+	// 评论作者或家庭所有者可以删除
+	if comment.UserID != member.UserID && !member.IsOwner() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权删除此评论"})
+		return
+	}
+
+	if err := h.mealRecordRepo.DeleteComment(c.Request.Context(), comment.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除评论失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "评论删除成功"})
+}
+
+// hasDish 检查菜品行是否属于该用餐记录
+func hasDish(mealRecord *models.MealRecord, mealRecordDishID *uint) bool {
+	if mealRecordDishID == nil {
+		return true
+	}
+	for _, dish := range mealRecord.Dishes {
+		if dish.ID == *mealRecordDishID {
+			return true
+		}
+	}
+	return false
+}
+
+// loadPhoto 加载属于该用餐记录的照片，失败时直接写入错误响应
+func (h *MealRecordHandler) loadPhoto(c *gin.Context, mealRecord *models.MealRecord) (*models.MealRecordPhoto, bool) {
+	photoID, err := strconv.ParseUint(c.Param("photo_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的照片ID"})
+		return nil, false
+	}
+
+	photo, err := h.mealRecordRepo.GetPhoto(c.Request.Context(), uint(photoID))
+	if err != nil || photo.MealRecordID != mealRecord.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "照片不存在"})
+		return nil, false
+	}
+	return photo, true
+}
+
+// AddPhoto 家庭中可写的成员都可以为记录添加照片
+func (h *MealRecordHandler) AddPhoto(c *gin.Context) {
+	mealRecord, member, ok := h.loadAccessible(c, "id", false)
+	if !ok {
+		return
+	}
+	if !member.CanWrite() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只读成员不能添加照片"})
+		return
+	}
+
+	var req CreateMealPhotoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(mealRecord.Photos) >= maxMealRecordPhotos {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "照片数量超过限制"})
+		return
+	}
+	if !hasDish(mealRecord, req.MealRecordDishID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "关联的菜品不属于该用餐记录"})
+		return
+	}
+
+	photo := &models.MealRecordPhoto{
+		MealRecordID:     mealRecord.ID,
+		MealRecordDishID: req.MealRecordDishID,
+		UserID:           member.UserID,
+		URL:              req.URL,
+		Caption:          req.Caption,
+	}
+
+	if err := h.mealRecordRepo.AddPhoto(c.Request.Context(), photo); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "添加照片失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, photo)
+}
+
+// UpdatePhoto 修改照片说明和关联的菜品，照片上传者、记录作者或家庭所有者可以修改
+func (h *MealRecordHandler) UpdatePhoto(c *gin.Context) {
+	mealRecord, member, ok := h.loadAccessible(c, "id", false)
+	if !ok {
+		return
+	}
+
+	var req UpdateMealPhotoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	photo, ok := h.loadPhoto(c, mealRecord)
+	if !ok {
 		return
 	}
 
 	// 检查权限
-	if mealRecord.UserID != userID.(uint) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "无权更新此用餐记录"})
+	if photo.UserID != member.UserID && !canModify(mealRecord, member) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权修改此照片"})
+		return
+	}
+	if !hasDish(mealRecord, req.MealRecordDishID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "关联的菜品不属于该用餐记录"})
 		return
 	}
 
-	// 更新字段
-	mealRecord.Thoughts = req.Thoughts
-	mealRecord.ImageURL = req.ImageURL
+	photo.Caption = req.Caption
+	photo.MealRecordDishID = req.MealRecordDishID
 
-	if err := h.mealRecordRepo.Update(c.Request.Context(), mealRecord); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新用餐记录失败"})
+	if err := h.mealRecordRepo.UpdatePhoto(c.Request.Context(), photo); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新照片失败"})
 		return
 	}
 
-	c.JSON(http.StatusOK, mealRecord)
+	c.JSON(http.StatusOK, photo)
+}
+
+// DeletePhoto 照片上传者、记录作者或家庭所有者可以删除
+func (h *MealRecordHandler) DeletePhoto(c *gin.Context) {
+	mealRecord, member, ok := h.loadAccessible(c, "id", false)
+	if !ok {
+		return
+	}
+
+	photo, ok := h.loadPhoto(c, mealRecord)
+	if !ok {
+		return
+	}
+
+	// 检查权限
+	if photo.UserID != member.UserID && !canModify(mealRecord, member) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权删除此照片"})
+		return
+	}
+
+	if err := h.mealRecordRepo.DeletePhoto(c.Request.Context(), photo.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除照片失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "照片删除成功"})
+}
+
+// ReorderPhotos 按给定顺序重排全部照片，第一张作为封面
+func (h *MealRecordHandler) ReorderPhotos(c *gin.Context) {
+	mealRecord, member, ok := h.loadAccessible(c, "id", false)
+	if !ok {
+		return
+	}
+
+	// 检查权限
+	if !canModify(mealRecord, member) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权调整照片顺序"})
+		return
+	}
+
+	var req ReorderMealPhotosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.mealRecordRepo.ReorderPhotos(c.Request.Context(), mealRecord.ID, req.PhotoIDs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.mealRecordRepo.GetByID(c.Request.Context(), mealRecord.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用餐记录失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": updated.Photos})
 }
@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/recommend"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxMenuDishes 生成的菜单最多的菜品数
+const maxMenuDishes = 20
+
+// 菜单可以保存到的目标
+const (
+	MenuTargetMealRecord = "meal_record"
+	MenuTargetMealPlan   = "meal_plan"
+)
+
+// MenuHandler 按分类和标签的数量要求随机生成菜单，并保存为用餐记录或计划餐次
+type MenuHandler struct {
+	dishRepo       repositories.DishRepository
+	categoryRepo   repositories.CategoryRepository
+	tagRepo        repositories.TagRepository
+	mealRecordRepo repositories.MealRecordRepository
+	mealPlanRepo   repositories.MealPlanRepository
+	householdRepo  repositories.HouseholdRepository
+}
+
+func NewMenuHandler(dishRepo repositories.DishRepository, categoryRepo repositories.CategoryRepository, tagRepo repositories.TagRepository, mealRecordRepo repositories.MealRecordRepository, mealPlanRepo repositories.MealPlanRepository, householdRepo repositories.HouseholdRepository) *MenuHandler {
+	return &MenuHandler{
+		dishRepo:       dishRepo,
+		categoryRepo:   categoryRepo,
+		tagRepo:        tagRepo,
+		mealRecordRepo: mealRecordRepo,
+		mealPlanRepo:   mealPlanRepo,
+		householdRepo:  householdRepo,
+	}
+}
+
+// MenuSlotRequest 菜单中的一组菜，分类包含其下级分类，同时指定分类和标签时需要同时满足，都不指定时为任意菜品
+type MenuSlotRequest struct {
+	CategoryID *uint `json:"category_id"`
+	TagID      *uint `json:"tag_id"`
+	Count      int   `json:"count" binding:"required,min=1,max=10"`
+}
+
+type GenerateMenuRequest struct {
+	Slots            []MenuSlotRequest `json:"slots" binding:"required,min=1,max=10,dive"`
+	MaxPrice         float64           `json:"max_price" binding:"min=0"` // 菜品价格合计的上限，0 表示不限
+	ExcludeDishIDs   []uint            `json:"exclude_dish_ids"`
+	ExcludeAllergens []string          `json:"exclude_allergens"`
+	Diets            []string          `json:"diets"`
+	MaxSpicyLevel    *int              `json:"max_spicy_level" binding:"omitempty,min=0,max=3"`
+	Alternatives     int               `json:"alternatives" binding:"omitempty,min=1,max=10"` // 默认 3
+	Seed             *int64            `json:"seed"`                                          // 省略时随机生成
+}
+
+type ApplyMenuRequest struct {
+	Target  string `json:"target" binding:"required"` // meal_record 或 meal_plan
+	DishIDs []uint `json:"dish_ids" binding:"required,min=1,max=20"`
+
+	// 保存为用餐记录
+	EatenAt      *time.Time               `json:"eaten_at"` // 默认为当前时间
+	Thoughts     string                   `json:"thoughts"`
+	Participants []MealParticipantRequest `json:"participants" binding:"omitempty,max=50,dive"` // 省略时为记录人自己
+
+	// 保存为计划餐次
+	MealPlanID uint   `json:"meal_plan_id"`
+	Date       string `json:"date"`
+	Servings   int    `json:"servings" binding:"omitempty,min=1,max=99"`
+	Notes      string `json:"notes" binding:"max=255"`
+
+	MealType  string `json:"meal_type"`
+	MealLabel string `json:"meal_label" binding:"max=50"`
+}
+
+// slotLabel 按分类和标签名称生成组的名称，分类或标签不存在时直接写入错误响应
+func (h *MenuHandler) slotLabel(c *gin.Context, req MenuSlotRequest) (string, bool) {
+	label := "任意"
+	if req.CategoryID != nil {
+		category, err := h.categoryRepo.GetByID(c.Request.Context(), *req.CategoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "分类不存在"})
+			return "", false
+		}
+		label = category.Name
+	}
+	if req.TagID != nil {
+		tag, err := h.tagRepo.GetByID(c.Request.Context(), *req.TagID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "标签不存在"})
+			return "", false
+		}
+		if req.CategoryID != nil {
+			label += "·" + tag.Name
+		} else {
+			label = tag.Name
+		}
+	}
+	return label, true
+}
+
+// Generate 按每组的数量随机组合菜品，返回多份互不相同的菜单供选择
+func (h *MenuHandler) Generate(c *gin.Context) {
+	var req GenerateMenuRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	total := 0
+	for _, slot := range req.Slots {
+		total += slot.Count
+	}
+	if total == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "菜单至少需要一道菜"})
+		return
+	}
+	if total > maxMenuDishes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "菜单的菜品数超过限制"})
+		return
+	}
+
+	allergens, ok := normalizeAllergens(c, req.ExcludeAllergens)
+	if !ok {
+		return
+	}
+	diets, err := models.NormalizeDiets(req.Diets)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	excluded := make(map[uint]bool, len(req.ExcludeDishIDs))
+	for _, id := range req.ExcludeDishIDs {
+		excluded[id] = true
+	}
+
+	slots := make([]recommend.Slot, 0, len(req.Slots))
+	for _, slotReq := range req.Slots {
+		label, ok := h.slotLabel(c, slotReq)
+		if !ok {
+			return
+		}
+
+		filter := repositories.DishFilter{
+			CategoryID:         slotReq.CategoryID,
+			IncludeDescendants: true,
+			ExcludeAllergens:   allergens,
+			Diets:              diets,
+			MaxSpicyLevel:      req.MaxSpicyLevel,
+		}
+		if slotReq.TagID != nil {
+			filter.TagIDs = []uint{*slotReq.TagID}
+		}
+		dishes, _, err := h.dishRepo.List(c.Request.Context(), filter, 0, -1)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取菜品列表失败"})
+			return
+		}
+
+		candidates := make([]*models.Dish, 0, len(dishes))
+		for _, dish := range dishes {
+			if !excluded[dish.ID] {
+				candidates = append(candidates, dish)
+			}
+		}
+		slots = append(slots, recommend.Slot{Label: label, Count: slotReq.Count, Candidates: candidates})
+	}
+
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+	alternatives := req.Alternatives
+	if alternatives == 0 {
+		alternatives = 3
+	}
+
+	menus := recommend.Generate(slots, recommend.GenerateOptions{
+		MaxPrice:     req.MaxPrice,
+		Alternatives: alternatives,
+		Seed:         seed,
+	})
+
+	resp := gin.H{
+		"seed":  seed,
+		"menus": menus,
+	}
+	switch {
+	case len(menus) == 0:
+		resp["menus"] = []recommend.GeneratedMenu{}
+		resp["warnings"] = []string{"没有满足条件的菜单，请减少菜品数或放宽价格上限"}
+	case len(menus) < alternatives:
+		resp["warnings"] = []string{fmt.Sprintf("符合条件的组合不足，只生成了 %d 份菜单", len(menus))}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Apply 把选中的菜单保存为新的用餐记录，或添加到用餐计划中的一餐
+func (h *MenuHandler) Apply(c *gin.Context) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return
+	}
+
+	var req ApplyMenuRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch req.Target {
+	case MenuTargetMealRecord:
+		h.applyToMealRecord(c, member, req)
+	case MenuTargetMealPlan:
+		h.applyToMealPlan(c, member, req)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target 只能为 meal_record 或 meal_plan"})
+	}
+}
+
+func (h *MenuHandler) applyToMealRecord(c *gin.Context, member *models.HouseholdMember, req ApplyMenuRequest) {
+	if !member.CanWrite() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只读成员不能创建用餐记录"})
+		return
+	}
+
+	lines := make([]MealDishLineRequest, 0, len(req.DishIDs))
+	for _, dishID := range req.DishIDs {
+		lines = append(lines, MealDishLineRequest{DishID: dishID, Quantity: 1})
+	}
+	dishes, totalPrice, ok := buildDishLines(c, h.dishRepo, lines, nil)
+	if !ok {
+		return
+	}
+
+	eatenAt := time.Now()
+	if req.EatenAt != nil {
+		eatenAt = *req.EatenAt
+	}
+	mealType, mealLabel, ok := resolveMealType(c, req.MealType, req.MealLabel, eatenAt)
+	if !ok {
+		return
+	}
+
+	participantReqs := req.Participants
+	if participantReqs == nil {
+		participantReqs = []MealParticipantRequest{{UserID: &member.UserID}}
+	}
+	participants, ok := buildParticipants(c, h.householdRepo, member.HouseholdID, participantReqs)
+	if !ok {
+		return
+	}
+
+	mealRecord := &models.MealRecord{
+		UserID:       member.UserID,
+		HouseholdID:  member.HouseholdID,
+		TotalPrice:   totalPrice,
+		Thoughts:     req.Thoughts,
+		EatenAt:      eatenAt,
+		MealType:     mealType,
+		MealLabel:    mealLabel,
+		Participants: participants,
+	}
+	if err := h.mealRecordRepo.Create(c.Request.Context(), mealRecord, dishes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建用餐记录失败"})
+		return
+	}
+
+	if created, err := h.mealRecordRepo.GetByID(c.Request.Context(), mealRecord.ID); err == nil {
+		mealRecord = created
+	}
+	c.JSON(http.StatusCreated, MealRecordResponse{
+		MealRecord:      mealRecord,
+		DietaryWarnings: dietaryWarnings(c.Request.Context(), h.dishRepo, mealRecord.Participants, dishes),
+	})
+}
+
+func (h *MenuHandler) applyToMealPlan(c *gin.Context, member *models.HouseholdMember, req ApplyMenuRequest) {
+	if !member.CanWrite() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只读成员不能修改用餐计划"})
+		return
+	}
+
+	plan, err := h.mealPlanRepo.GetByID(c.Request.Context(), req.MealPlanID)
+	if err != nil || plan.HouseholdID != member.HouseholdID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用餐计划不存在"})
+		return
+	}
+
+	slotReq := MealPlanSlotRequest{
+		Date:      req.Date,
+		MealType:  req.MealType,
+		MealLabel: req.MealLabel,
+		Servings:  req.Servings,
+		Notes:     req.Notes,
+	}
+	for _, dishID := range req.DishIDs {
+		slotReq.Dishes = append(slotReq.Dishes, MealPlanDishRequest{DishID: dishID, Quantity: 1})
+	}
+	if slotReq.MealType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请指定餐次"})
+		return
+	}
+
+	slot, ok := buildPlanSlot(c, h.dishRepo, plan, slotReq)
+	if !ok {
+		return
+	}
+	if hasSameMeal(plan, slot, 0) {
+		c.JSON(http.StatusConflict, gin.H{"error": "同一天的同一餐只能安排一次"})
+		return
+	}
+
+	if err := h.mealPlanRepo.CreateSlots(c.Request.Context(), []*models.MealPlanSlot{slot}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建计划餐次失败"})
+		return
+	}
+
+	created, err := h.mealPlanRepo.GetSlot(c.Request.Context(), slot.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取计划餐次失败"})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
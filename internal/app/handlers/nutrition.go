@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/nutrition"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxNutritionCSVSize 营养成分数据集的大小上限
+	maxNutritionCSVSize = 10 << 20
+	// defaultNutritionDays 每日营养汇总默认统计最近几天
+	defaultNutritionDays = 7
+	// maxNutritionDays 每日营养汇总最多统计的天数
+	maxNutritionDays = 366
+)
+
+// MealDishNutrition 用餐记录中一行菜品的营养成分，已乘以数量
+type MealDishNutrition struct {
+	DishID    uint                     `json:"dish_id"`
+	Name      string                   `json:"name"`
+	Quantity  int                      `json:"quantity"`
+	Nutrition *models.NutritionSummary `json:"nutrition"`
+}
+
+// DailyNutrition 一天的营养成分合计
+type DailyNutrition struct {
+	Date      string                   `json:"date"`
+	Meals     int                      `json:"meals"`
+	Nutrition *models.NutritionSummary `json:"nutrition"`
+}
+
+// dishNutritionCache 在一次请求内缓存菜品的营养成分，同一道菜只查询一次
+type dishNutritionCache struct {
+	dishRepo repositories.DishRepository
+	dishes   map[uint]*models.Dish
+}
+
+func newDishNutritionCache(dishRepo repositories.DishRepository) *dishNutritionCache {
+	return &dishNutritionCache{dishRepo: dishRepo, dishes: make(map[uint]*models.Dish)}
+}
+
+// load 返回菜品及其营养成分，菜品已删除时返回 nil
+func (d *dishNutritionCache) load(ctx context.Context, dishID uint) *models.Dish {
+	if dish, ok := d.dishes[dishID]; ok {
+		return dish
+	}
+	dish, err := d.dishRepo.GetByID(ctx, dishID)
+	if err != nil {
+		dish = nil
+	} else {
+		dish.Nutrition = dish.CalculateNutrition()
+	}
+	d.dishes[dishID] = dish
+	return dish
+}
+
+// mealNutrition 汇总一条用餐记录的营养成分，数量为菜品的份数
+func (d *dishNutritionCache) mealNutrition(ctx context.Context, mealRecord *models.MealRecord) (*models.NutritionSummary, []MealDishNutrition) {
+	total := &models.NutritionSummary{}
+	lines := make([]MealDishNutrition, 0, len(mealRecord.Dishes))
+	for _, line := range mealRecord.Dishes {
+		item := MealDishNutrition{DishID: line.DishID, Quantity: line.Quantity, Nutrition: &models.NutritionSummary{}}
+		if dish := d.load(ctx, line.DishID); dish != nil {
+			item.Name = dish.Name
+			item.Nutrition.Add(dish.Nutrition, float64(line.Quantity))
+		} else if line.Dish != nil {
+			item.Name = line.Dish.Name
+		}
+		item.Nutrition.Nutrition = item.Nutrition.Round()
+		total.Add(item.Nutrition, 1)
+		lines = append(lines, item)
+	}
+	total.Nutrition = total.Round()
+	return total, lines
+}
+
+// Nutrition 用餐记录的营养成分，按菜品行汇总
+func (h *MealRecordHandler) Nutrition(c *gin.Context) {
+	mealRecord, _, ok := h.loadAccessible(c, "id", true)
+	if !ok {
+		return
+	}
+
+	total, dishes := newDishNutritionCache(h.dishRepo).mealNutrition(c.Request.Context(), mealRecord)
+	c.JSON(http.StatusOK, gin.H{
+		"meal_record_id": mealRecord.ID,
+		"nutrition":      total,
+		"dishes":         dishes,
+	})
+}
+
+// DailyNutrition 当前家庭每天的营养成分合计，默认为最近 7 天
+func (h *MealRecordHandler) DailyNutrition(c *gin.Context) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseTimeRange(c)
+	if !ok {
+		return
+	}
+	if to == nil {
+		tomorrow := startOfDay(time.Now()).AddDate(0, 0, 1)
+		to = &tomorrow
+	}
+	if from == nil {
+		start := to.AddDate(0, 0, -defaultNutritionDays)
+		from = &start
+	}
+	if !from.Before(*to) || to.Sub(*from) > maxNutritionDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "时间范围无效或超过一年"})
+		return
+	}
+
+	filter := repositories.MealRecordFilter{From: from, To: to, Ascending: true}
+	mealRecords, _, err := h.mealRecordRepo.List(c.Request.Context(), member.HouseholdID, filter, 0, -1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用餐记录失败"})
+		return
+	}
+
+	cache := newDishNutritionCache(h.dishRepo)
+	total := &models.NutritionSummary{}
+	days := []*DailyNutrition{}
+	for _, mealRecord := range mealRecords {
+		date := mealRecord.EatenAt.In(time.Local).Format(models.PlanDateLayout)
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, &DailyNutrition{Date: date, Nutrition: &models.NutritionSummary{}})
+		}
+		day := days[len(days)-1]
+
+		meal, _ := cache.mealNutrition(c.Request.Context(), mealRecord)
+		day.Meals++
+		day.Nutrition.Add(meal, 1)
+		day.Nutrition.Nutrition = day.Nutrition.Round()
+		total.Add(meal, 1)
+	}
+	total.Nutrition = total.Round()
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  days,
+		"total": total,
+		"from":  from,
+		"to":    to,
+	})
+}
+
+// startOfDay 返回本地时区当天零点
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.In(time.Local).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// ImportNutrition 从营养成分数据集（CSV）批量更新食材，按名称匹配已有食材。
+// 可以用 multipart 的 file 字段上传，也可以直接把 CSV 作为请求体
+func (h *IngredientHandler) ImportNutrition(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxNutritionCSVSize)
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "文件大小超过限制"})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请选择要上传的文件"})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	records, rowErrors, err := nutrition.ParseCSV(body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "文件大小超过限制"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ingredients, _, err := h.ingredientRepo.List(c.Request.Context(), 0, -1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取食材列表失败"})
+		return
+	}
+	byName := make(map[string]uint, len(ingredients))
+	for _, ingredient := range ingredients {
+		byName[strings.ToLower(strings.TrimSpace(ingredient.Name))] = ingredient.ID
+	}
+
+	// 同名的行以后出现的为准
+	updates := make(map[uint]models.Nutrition)
+	unmatched := []string{}
+	for _, record := range records {
+		id, ok := byName[strings.ToLower(record.Name)]
+		if !ok {
+			unmatched = append(unmatched, record.Name)
+			continue
+		}
+		updates[id] = record.Nutrition
+	}
+
+	if len(updates) > 0 {
+		if err := h.ingredientRepo.SetNutrition(c.Request.Context(), updates); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "更新食材营养成分失败"})
+			return
+		}
+	}
+
+	if rowErrors == nil {
+		rowErrors = []nutrition.RowError{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"updated":   len(updates),
+		"unmatched": unmatched,
+		"errors":    rowErrors,
+	})
+}
@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PantryHandler struct {
+	pantryRepo     repositories.PantryRepository
+	ingredientRepo repositories.IngredientRepository
+	householdRepo  repositories.HouseholdRepository
+}
+
+func NewPantryHandler(pantryRepo repositories.PantryRepository, ingredientRepo repositories.IngredientRepository, householdRepo repositories.HouseholdRepository) *PantryHandler {
+	return &PantryHandler{
+		pantryRepo:     pantryRepo,
+		ingredientRepo: ingredientRepo,
+		householdRepo:  householdRepo,
+	}
+}
+
+// defaultExpiringDays 临期提醒默认提前的天数
+const defaultExpiringDays = 3
+
+type CreatePantryItemRequest struct {
+	IngredientID uint    `json:"ingredient_id" binding:"required"`
+	Quantity     float64 `json:"quantity" binding:"required,gt=0"`
+	Unit         string  `json:"unit"`          // 默认为食材的单位，其他单位换算后保存
+	PurchaseDate string  `json:"purchase_date"` // 默认为今天
+	ExpiryDate   string  `json:"expiry_date"`
+	Location     string  `json:"location" binding:"max=50"`
+	Notes        string  `json:"notes" binding:"max=255"`
+}
+
+type UpdatePantryItemRequest struct {
+	Quantity     *float64 `json:"quantity" binding:"omitempty,gte=0"`
+	PurchaseDate *string  `json:"purchase_date"`
+	ExpiryDate   *string  `json:"expiry_date"`
+	Location     *string  `json:"location" binding:"omitempty,max=50"`
+	Notes        *string  `json:"notes" binding:"omitempty,max=255"`
+}
+
+type ConsumePantryRequest struct {
+	Items []ConsumePantryItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type ConsumePantryItemRequest struct {
+	IngredientID uint    `json:"ingredient_id" binding:"required"`
+	Quantity     float64 `json:"quantity" binding:"required,gt=0"`
+}
+
+// pantryUsages 按份数汇总菜品行所需的食材用量，失败时直接写入错误响应
+func pantryUsages(c *gin.Context, dishRepo repositories.DishRepository, dishes []repositories.MealRecordDishRequest) ([]repositories.PantryUsage, bool) {
+	index := make(map[uint]int)
+	var usages []repositories.PantryUsage
+	for _, line := range dishes {
+		dish, err := dishRepo.GetByID(c.Request.Context(), line.DishID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "菜品不存在"})
+			return nil, false
+		}
+		// 菜品行的数量是份数，食材用量对应 dish.Servings 份
+		dish.ScaleServings(line.Quantity)
+		for _, di := range dish.Ingredients {
+			required := di.Quantity
+			if i, ok := index[di.IngredientID]; ok {
+				usages[i].Required = models.RoundQuantity(usages[i].Required + required)
+				continue
+			}
+			index[di.IngredientID] = len(usages)
+			usages = append(usages, repositories.PantryUsage{IngredientID: di.IngredientID, Required: models.RoundQuantity(required)})
+		}
+	}
+	return usages, true
+}
+
+// checkPantryDate 校验可以为空的日期，失败时直接写入错误响应
+func checkPantryDate(c *gin.Context, date, message string) bool {
+	if date == "" {
+		return true
+	}
+	if _, err := parsePlanDate(date); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return false
+	}
+	return true
+}
+
+// loadItem 加载当前家庭的库存，失败时直接写入错误响应
+func (h *PantryHandler) loadItem(c *gin.Context) (*models.PantryItem, bool) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return nil, false
+	}
+	if !member.CanWrite() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只读成员不能修改库存"})
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的库存ID"})
+		return nil, false
+	}
+
+	item, err := h.pantryRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil || item.HouseholdID != member.HouseholdID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "库存不存在"})
+		return nil, false
+	}
+	return item, true
+}
+
+func (h *PantryHandler) List(c *gin.Context) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	var ingredientID uint
+	if value := c.Query("ingredient_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的食材ID"})
+			return
+		}
+		ingredientID = uint(id)
+	}
+
+	items, total, err := h.pantryRepo.List(c.Request.Context(), member.HouseholdID, ingredientID, c.Query("location"), offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取库存失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   items,
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
+	})
+}
+
+// Expiring 返回 days 天内（含今天）到期和已经过期的库存
+func (h *PantryHandler) Expiring(c *gin.Context) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(defaultExpiringDays)))
+	if err != nil || days < 0 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的天数"})
+		return
+	}
+
+	today := time.Now().Format(models.PlanDateLayout)
+	until := time.Now().AddDate(0, 0, days).Format(models.PlanDateLayout)
+	items, err := h.pantryRepo.ListExpiring(c.Request.Context(), member.HouseholdID, until)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取临期库存失败"})
+		return
+	}
+
+	expired := 0
+	for _, item := range items {
+		if item.ExpiryDate < today {
+			expired++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    items,
+		"until":   until,
+		"expired": expired,
+	})
+}
+
+// Create 添加一批库存
+func (h *PantryHandler) Create(c *gin.Context) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return
+	}
+	if !member.CanWrite() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只读成员不能修改库存"})
+		return
+	}
+
+	var req CreatePantryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ingredient, err := h.ingredientRepo.GetByID(c.Request.Context(), req.IngredientID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "食材不存在"})
+		return
+	}
+
+	// 库存换算为食材的单位保存，便于和菜品用量比较
+	unit := strings.TrimSpace(req.Unit)
+	quantity, err := convertToIngredientUnit(ingredient, req.Quantity, unit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": conversionError(ingredient, unit, err)})
+		return
+	}
+
+	if req.PurchaseDate == "" {
+		req.PurchaseDate = time.Now().Format(models.PlanDateLayout)
+	}
+	if !checkPantryDate(c, req.PurchaseDate, "无效的购买日期") || !checkPantryDate(c, req.ExpiryDate, "无效的过期日期") {
+		return
+	}
+	if req.ExpiryDate != "" && req.ExpiryDate < req.PurchaseDate {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "过期日期不能早于购买日期"})
+		return
+	}
+
+	item := &models.PantryItem{
+		HouseholdID:  member.HouseholdID,
+		IngredientID: ingredient.ID,
+		Quantity:     models.RoundQuantity(quantity),
+		Unit:         ingredient.Unit,
+		PurchaseDate: req.PurchaseDate,
+		ExpiryDate:   req.ExpiryDate,
+		Location:     strings.TrimSpace(req.Location),
+		Notes:        req.Notes,
+	}
+	if err := h.pantryRepo.Create(c.Request.Context(), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "添加库存失败"})
+		return
+	}
+	item.Ingredient = ingredient
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *PantryHandler) Update(c *gin.Context) {
+	item, ok := h.loadItem(c)
+	if !ok {
+		return
+	}
+
+	var req UpdatePantryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Quantity != nil {
+		item.Quantity = models.RoundQuantity(*req.Quantity)
+	}
+	if req.PurchaseDate != nil {
+		item.PurchaseDate = *req.PurchaseDate
+	}
+	if req.ExpiryDate != nil {
+		item.ExpiryDate = *req.ExpiryDate
+	}
+	if req.Location != nil {
+		item.Location = strings.TrimSpace(*req.Location)
+	}
+	if req.Notes != nil {
+		item.Notes = *req.Notes
+	}
+	if !checkPantryDate(c, item.PurchaseDate, "无效的购买日期") || !checkPantryDate(c, item.ExpiryDate, "无效的过期日期") {
+		return
+	}
+
+	// 数量改为 0 视为用完
+	if item.Quantity == 0 {
+		if err := h.pantryRepo.Delete(c.Request.Context(), item.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "更新库存失败"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "库存已用完"})
+		return
+	}
+
+	if err := h.pantryRepo.Update(c.Request.Context(), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新库存失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *PantryHandler) Delete(c *gin.Context) {
+	item, ok := h.loadItem(c)
+	if !ok {
+		return
+	}
+
+	if err := h.pantryRepo.Delete(c.Request.Context(), item.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除库存失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "库存删除成功"})
+}
+
+// Consume 按食材扣减库存，先用快到期的批次
+func (h *PantryHandler) Consume(c *gin.Context) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return
+	}
+	if !member.CanWrite() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只读成员不能修改库存"})
+		return
+	}
+
+	var req ConsumePantryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	index := make(map[uint]int)
+	var usages []repositories.PantryUsage
+	for _, item := range req.Items {
+		if i, ok := index[item.IngredientID]; ok {
+			usages[i].Required = models.RoundQuantity(usages[i].Required + item.Quantity)
+			continue
+		}
+		index[item.IngredientID] = len(usages)
+		usages = append(usages, repositories.PantryUsage{IngredientID: item.IngredientID, Required: models.RoundQuantity(item.Quantity)})
+	}
+
+	result, err := h.pantryRepo.Consume(c.Request.Context(), member.HouseholdID, usages)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "扣减库存失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PreferenceHandler 用户对菜品的评分和收藏
+type PreferenceHandler struct {
+	preferenceRepo repositories.PreferenceRepository
+	dishRepo       repositories.DishRepository
+	mealRecordRepo repositories.MealRecordRepository
+	householdRepo  repositories.HouseholdRepository
+}
+
+func NewPreferenceHandler(preferenceRepo repositories.PreferenceRepository, dishRepo repositories.DishRepository, mealRecordRepo repositories.MealRecordRepository, householdRepo repositories.HouseholdRepository) *PreferenceHandler {
+	return &PreferenceHandler{
+		preferenceRepo: preferenceRepo,
+		dishRepo:       dishRepo,
+		mealRecordRepo: mealRecordRepo,
+		householdRepo:  householdRepo,
+	}
+}
+
+type RateDishRequest struct {
+	Stars        int    `json:"stars" binding:"required,min=1,max=5"`
+	Comment      string `json:"comment" binding:"max=500"`
+	MealRecordID *uint  `json:"meal_record_id"` // 对某次用餐中的这道菜评分，为空时评价菜品本身
+}
+
+// attachDishStats 填充菜品的评分汇总和被吃过的次数，查询失败时只记录日志
+func attachDishStats(ctx context.Context, preferenceRepo repositories.PreferenceRepository, dishes []*models.Dish) {
+	if len(dishes) == 0 {
+		return
+	}
+	ids := make([]uint, 0, len(dishes))
+	for _, dish := range dishes {
+		ids = append(ids, dish.ID)
+	}
+	stats, err := preferenceRepo.DishStats(ctx, ids)
+	if err != nil {
+		logrus.Warnf("统计菜品评分失败: %v", err)
+		return
+	}
+	for _, dish := range dishes {
+		dish.ApplyStats(stats[dish.ID])
+	}
+}
+
+// loadDish 返回当前用户和路径中的菜品ID，菜品不存在等失败情况直接写入错误响应
+func (h *PreferenceHandler) loadDish(c *gin.Context) (uint, uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的菜品ID"})
+		return 0, 0, false
+	}
+	if _, err := h.dishRepo.GetByID(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "菜品不存在"})
+		return 0, 0, false
+	}
+	return userID.(uint), uint(id), true
+}
+
+// mealRecordDish 查找用餐记录中这道菜所在的行，当前用户需要是记录所属家庭的成员，
+// 失败时直接写入错误响应。mealRecordID 为空时返回 nil
+func (h *PreferenceHandler) mealRecordDish(c *gin.Context, userID, dishID uint, mealRecordID *uint) (*uint, bool) {
+	if mealRecordID == nil {
+		return nil, true
+	}
+
+	mealRecord, err := h.mealRecordRepo.GetByID(c.Request.Context(), *mealRecordID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用餐记录不存在"})
+		return nil, false
+	}
+	if _, err := h.householdRepo.GetMember(c.Request.Context(), mealRecord.HouseholdID, userID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权访问此用餐记录"})
+		return nil, false
+	}
+	for _, line := range mealRecord.Dishes {
+		if line.DishID == dishID {
+			id := line.ID
+			return &id, true
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "该用餐记录中没有这道菜"})
+	return nil, false
+}
+
+// Rate 为菜品评分，同一用户对同一菜品（或同一次用餐中的这道菜）重复评分时覆盖原评分
+func (h *PreferenceHandler) Rate(c *gin.Context) {
+	userID, dishID, ok := h.loadDish(c)
+	if !ok {
+		return
+	}
+
+	var req RateDishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mealRecordDishID, ok := h.mealRecordDish(c, userID, dishID, req.MealRecordID)
+	if !ok {
+		return
+	}
+
+	rating := &models.DishRating{
+		DishID:           dishID,
+		UserID:           userID,
+		MealRecordDishID: mealRecordDishID,
+		Stars:            req.Stars,
+		Comment:          req.Comment,
+	}
+	if err := h.preferenceRepo.SaveRating(c.Request.Context(), rating); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存评分失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rating)
+}
+
+// DeleteRating 删除自己的评分，query 中的 meal_record_id 指定某次用餐的评分
+func (h *PreferenceHandler) DeleteRating(c *gin.Context) {
+	userID, dishID, ok := h.loadDish(c)
+	if !ok {
+		return
+	}
+
+	var mealRecordID *uint
+	if value := c.Query("meal_record_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用餐记录ID"})
+			return
+		}
+		recordID := uint(id)
+		mealRecordID = &recordID
+	}
+	mealRecordDishID, ok := h.mealRecordDish(c, userID, dishID, mealRecordID)
+	if !ok {
+		return
+	}
+
+	rating, err := h.preferenceRepo.GetRating(c.Request.Context(), userID, dishID, mealRecordDishID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "评分不存在"})
+		return
+	}
+	if err := h.preferenceRepo.DeleteRating(c.Request.Context(), rating.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除评分失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "评分删除成功"})
+}
+
+// ListRatings 菜品的全部评分，按更新时间倒序
+func (h *PreferenceHandler) ListRatings(c *gin.Context) {
+	_, dishID, ok := h.loadDish(c)
+	if !ok {
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	ratings, total, err := h.preferenceRepo.ListRatings(c.Request.Context(), dishID, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取评分列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   ratings,
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
+	})
+}
+
+// AddFavorite 收藏菜品，重复收藏不报错
+func (h *PreferenceHandler) AddFavorite(c *gin.Context) {
+	userID, dishID, ok := h.loadDish(c)
+	if !ok {
+		return
+	}
+
+	if err := h.preferenceRepo.AddFavorite(c.Request.Context(), userID, dishID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "收藏菜品失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "收藏成功"})
+}
+
+func (h *PreferenceHandler) RemoveFavorite(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return
+	}
+
+	// 菜品已删除时也允许取消收藏
+	dishID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的菜品ID"})
+		return
+	}
+
+	if err := h.preferenceRepo.RemoveFavorite(c.Request.Context(), userID.(uint), uint(dishID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未收藏该菜品"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已取消收藏"})
+}
+
+// Favorites 当前用户收藏的菜品，按收藏时间倒序
+func (h *PreferenceHandler) Favorites(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return
+	}
+
+	favorites, err := h.preferenceRepo.ListFavorites(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取收藏列表失败"})
+		return
+	}
+
+	dishes := make([]*models.Dish, 0, len(favorites))
+	for _, favorite := range favorites {
+		if favorite.Dish != nil {
+			dishes = append(dishes, favorite.Dish)
+		}
+	}
+	attachDishStats(c.Request.Context(), h.preferenceRepo, dishes)
+
+	c.JSON(http.StatusOK, gin.H{"data": favorites})
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/recommend"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// recommendHistoryDays 推荐时查询的用餐历史天数，用于判断多久没吃过
+const recommendHistoryDays = 30
+
+type RecommendationHandler struct {
+	dishRepo       repositories.DishRepository
+	preferenceRepo repositories.PreferenceRepository
+	mealRecordRepo repositories.MealRecordRepository
+	pantryRepo     repositories.PantryRepository
+	householdRepo  repositories.HouseholdRepository
+}
+
+func NewRecommendationHandler(dishRepo repositories.DishRepository, preferenceRepo repositories.PreferenceRepository, mealRecordRepo repositories.MealRecordRepository, pantryRepo repositories.PantryRepository, householdRepo repositories.HouseholdRepository) *RecommendationHandler {
+	return &RecommendationHandler{
+		dishRepo:       dishRepo,
+		preferenceRepo: preferenceRepo,
+		mealRecordRepo: mealRecordRepo,
+		pantryRepo:     pantryRepo,
+		householdRepo:  householdRepo,
+	}
+}
+
+// RecommendationResponse 推荐结果，seed 可用于重现同一结果
+type RecommendationResponse struct {
+	Seed          int64            `json:"seed"`
+	People        int              `json:"people"`
+	Budget        float64          `json:"budget"`
+	TotalPrice    float64          `json:"total_price"`
+	Dishes        []recommend.Pick `json:"dishes"`
+	SkippedRecent int              `json:"skipped_recent"` // 因最近吃过而排除的菜品数
+	OverBudget    int              `json:"over_budget"`    // 单价超出预算而排除的菜品数
+	Warnings      []string         `json:"warnings,omitempty"`
+}
+
+// queryInt 解析整数查询参数，为空时使用默认值，超出范围时直接写入错误响应
+func queryInt(c *gin.Context, key string, def, lo, hi int, message string) (int, bool) {
+	value := c.Query(key)
+	if value == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < lo || n > hi {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return 0, false
+	}
+	return n, true
+}
+
+// Recommend 为 people 个人推荐一餐的菜品：排除最近 avoid_days 天吃过的菜，
+// 尽量搭配不同分类，优先评分高、收藏的菜，in_stock=true 时优先食材有库存的菜。
+// 相同的 seed 和数据得到相同的结果，省略时随机生成
+func (h *RecommendationHandler) Recommend(c *gin.Context) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return
+	}
+
+	people, ok := queryInt(c, "people", 2, 1, 20, "人数应为 1-20")
+	if !ok {
+		return
+	}
+	count, ok := queryInt(c, "count", min(people, 10), 1, 10, "菜品数应为 1-10")
+	if !ok {
+		return
+	}
+	avoidDays, ok := queryInt(c, "avoid_days", 3, 0, recommendHistoryDays, "avoid_days 应为 0-30")
+	if !ok {
+		return
+	}
+	var budget float64
+	if value := c.Query("budget"); value != "" {
+		var err error
+		budget, err = strconv.ParseFloat(value, 64)
+		if err != nil || budget < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的预算"})
+			return
+		}
+	}
+	seed := time.Now().UnixNano()
+	if value := c.Query("seed"); value != "" {
+		var err error
+		seed, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的随机种子"})
+			return
+		}
+	}
+	filter, ok := parseDishFilter(c)
+	if !ok {
+		return
+	}
+	preferInStock := c.Query("in_stock") == "true"
+
+	candidates, ok := h.candidates(c, member, filter, people, preferInStock)
+	if !ok {
+		return
+	}
+
+	menu := recommend.Recommend(candidates, recommend.Options{
+		Count:         count,
+		Budget:        budget,
+		AvoidDays:     avoidDays,
+		HistoryDays:   recommendHistoryDays,
+		PreferInStock: preferInStock,
+		Seed:          seed,
+	})
+
+	resp := RecommendationResponse{
+		Seed:          seed,
+		People:        people,
+		Budget:        budget,
+		TotalPrice:    menu.TotalPrice,
+		Dishes:        menu.Picks,
+		SkippedRecent: menu.SkippedRecent,
+		OverBudget:    menu.OverBudget,
+	}
+	if resp.Dishes == nil {
+		resp.Dishes = []recommend.Pick{}
+	}
+	if len(menu.Picks) < count {
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("符合条件的菜品不足，只推荐了 %d 道菜", len(menu.Picks)))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// candidates 加载符合筛选条件的菜品，并填充评分、收藏、多久没吃和库存情况，
+// 失败时直接写入错误响应
+func (h *RecommendationHandler) candidates(c *gin.Context, member *models.HouseholdMember, filter repositories.DishFilter, people int, withStock bool) ([]recommend.Candidate, bool) {
+	ctx := c.Request.Context()
+
+	dishes, _, err := h.dishRepo.List(ctx, filter, 0, -1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取菜品列表失败"})
+		return nil, false
+	}
+	attachDishStats(ctx, h.preferenceRepo, dishes)
+
+	favorites := make(map[uint]bool)
+	if list, err := h.preferenceRepo.ListFavorites(ctx, member.UserID); err != nil {
+		logrus.Warnf("查询收藏列表失败: %v", err)
+	} else {
+		for _, favorite := range list {
+			favorites[favorite.DishID] = true
+		}
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -recommendHistoryDays)
+	records, _, err := h.mealRecordRepo.List(ctx, member.HouseholdID, repositories.MealRecordFilter{From: &since}, 0, -1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用餐记录失败"})
+		return nil, false
+	}
+	// 记录按用餐时间倒序，第一次出现即为最近一次
+	lastEaten := make(map[uint]int)
+	for _, record := range records {
+		for _, line := range record.Dishes {
+			if _, ok := lastEaten[line.DishID]; !ok {
+				lastEaten[line.DishID] = max(int(now.Sub(record.EatenAt).Hours()/24), 0)
+			}
+		}
+	}
+
+	var stock map[uint]float64
+	if withStock {
+		stock, err = h.pantryRepo.StockLevels(ctx, member.HouseholdID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取库存失败"})
+			return nil, false
+		}
+	}
+
+	candidates := make([]recommend.Candidate, 0, len(dishes))
+	for _, dish := range dishes {
+		dish.IngredientCost = dish.CalculateIngredientCost()
+		dish.Allergens = dish.CollectAllergens()
+
+		days, eaten := lastEaten[dish.ID]
+		if !eaten {
+			days = -1
+		}
+		coverage := -1.0
+		if withStock {
+			coverage = recommend.StockCoverage(dish, people, stock)
+		}
+		candidates = append(candidates, recommend.Candidate{
+			Dish:           dish,
+			Favorite:       favorites[dish.ID],
+			DaysSinceEaten: days,
+			StockCoverage:  coverage,
+		})
+	}
+	return candidates, true
+}
@@ -0,0 +1,417 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShoppingListHandler struct {
+	shoppingListRepo repositories.ShoppingListRepository
+	dishRepo         repositories.DishRepository
+	mealPlanRepo     repositories.MealPlanRepository
+	pantryRepo       repositories.PantryRepository
+	householdRepo    repositories.HouseholdRepository
+}
+
+func NewShoppingListHandler(shoppingListRepo repositories.ShoppingListRepository, dishRepo repositories.DishRepository, mealPlanRepo repositories.MealPlanRepository, pantryRepo repositories.PantryRepository, householdRepo repositories.HouseholdRepository) *ShoppingListHandler {
+	return &ShoppingListHandler{
+		shoppingListRepo: shoppingListRepo,
+		dishRepo:         dishRepo,
+		mealPlanRepo:     mealPlanRepo,
+		pantryRepo:       pantryRepo,
+		householdRepo:    householdRepo,
+	}
+}
+
+// CreateShoppingListRequest 按菜品或计划日期范围生成清单，两者可以同时提供
+type CreateShoppingListRequest struct {
+	Name      string                   `json:"name" binding:"max=100"`
+	Dishes    []ShoppingDishRequest    `json:"dishes" binding:"omitempty,max=100,dive"`
+	StartDate string                   `json:"start_date"` // 汇总该范围内尚未吃过的计划餐次
+	EndDate   string                   `json:"end_date"`
+	InStock   []ShoppingInStockRequest `json:"in_stock" binding:"omitempty,dive"` // 手动填写的现有库存，与库存记录累加
+	// IgnorePantry 为 true 时不扣除库存记录中的数量
+	IgnorePantry bool `json:"ignore_pantry"`
+}
+
+type ShoppingDishRequest struct {
+	DishID   uint `json:"dish_id" binding:"required"`
+	Servings int  `json:"servings" binding:"omitempty,min=1,max=99"` // 做几份，默认 1
+}
+
+type ShoppingInStockRequest struct {
+	IngredientID uint    `json:"ingredient_id" binding:"required"`
+	Quantity     float64 `json:"quantity" binding:"min=0"`
+}
+
+type UpdateShoppingListItemRequest struct {
+	Checked bool `json:"checked"`
+}
+
+// loadList 加载当前家庭的清单，失败时直接写入错误响应
+func (h *ShoppingListHandler) loadList(c *gin.Context, write bool) (*models.ShoppingList, bool) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的清单ID"})
+		return nil, false
+	}
+
+	list, err := h.shoppingListRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil || list.HouseholdID != member.HouseholdID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "购物清单不存在"})
+		return nil, false
+	}
+
+	if write && !member.CanWrite() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只读成员不能修改购物清单"})
+		return nil, false
+	}
+	return list, true
+}
+
+// collectDishes 合并请求中的菜品和计划餐次中的菜品，返回菜品ID到份数的映射，失败时直接写入错误响应
+func (h *ShoppingListHandler) collectDishes(c *gin.Context, householdID uint, req *CreateShoppingListRequest) (map[uint]int, []uint, bool) {
+	servings := make(map[uint]int)
+	var order []uint
+	add := func(dishID uint, count int) {
+		if _, ok := servings[dishID]; !ok {
+			order = append(order, dishID)
+		}
+		servings[dishID] += count
+	}
+
+	for _, item := range req.Dishes {
+		count := item.Servings
+		if count == 0 {
+			count = 1
+		}
+		add(item.DishID, count)
+	}
+
+	if req.StartDate != "" || req.EndDate != "" {
+		if req.StartDate == "" || req.EndDate == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "开始日期和结束日期需要同时填写"})
+			return nil, nil, false
+		}
+		if !checkPlanRange(c, req.StartDate, req.EndDate) {
+			return nil, nil, false
+		}
+
+		slots, err := h.mealPlanRepo.ListSlots(c.Request.Context(), householdID, req.StartDate, req.EndDate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用餐计划失败"})
+			return nil, nil, false
+		}
+		for _, slot := range slots {
+			// 已经吃过的餐次不需要再买
+			if slot.Eaten() {
+				continue
+			}
+			for _, dish := range slot.Dishes {
+				if dish.Dish != nil {
+					add(dish.DishID, dish.Quantity)
+				}
+			}
+		}
+	}
+
+	if len(servings) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "没有需要采购的菜品"})
+		return nil, nil, false
+	}
+	return servings, order, true
+}
+
+// buildItems 按食材汇总菜品用量，扣除库存并估算费用，失败时直接写入错误响应
+func (h *ShoppingListHandler) buildItems(c *gin.Context, servings map[uint]int, order []uint, inStock map[uint]float64) ([]models.ShoppingListItem, bool) {
+	index := make(map[uint]int)
+	var items []models.ShoppingListItem
+	for _, dishID := range order {
+		dish, err := h.dishRepo.GetByID(c.Request.Context(), dishID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "菜品不存在"})
+			return nil, false
+		}
+
+		// 菜品的食材用量对应 dish.Servings 份，换算为要做的份数
+		dish.ScaleServings(servings[dishID])
+		for _, di := range dish.Ingredients {
+			if di.Ingredient == nil {
+				continue
+			}
+			required := di.Quantity
+			if i, ok := index[di.IngredientID]; ok {
+				items[i].Required += required
+				continue
+			}
+			index[di.IngredientID] = len(items)
+			items = append(items, models.ShoppingListItem{
+				IngredientID: di.IngredientID,
+				Name:         di.Ingredient.Name,
+				Unit:         di.Ingredient.Unit,
+				Required:     required,
+				UnitPrice:    di.Ingredient.Price,
+			})
+		}
+	}
+
+	var result []models.ShoppingListItem
+	for _, item := range items {
+		item.Required = models.RoundQuantity(item.Required)
+		item.InStock = models.RoundQuantity(inStock[item.IngredientID])
+		item.Quantity = models.RoundQuantity(item.Required - item.InStock)
+		// 库存足够的食材不需要购买
+		if item.Quantity <= 0 {
+			continue
+		}
+		item.EstimatedCost = models.RoundPrice(item.Quantity * item.UnitPrice)
+		item.SortOrder = len(result)
+		result = append(result, item)
+	}
+	return result, true
+}
+
+// formatQuantity 去掉数量末尾多余的零
+func formatQuantity(quantity float64) string {
+	return strconv.FormatFloat(models.RoundQuantity(quantity), 'f', -1, 64)
+}
+
+func (h *ShoppingListHandler) List(c *gin.Context) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	lists, total, err := h.shoppingListRepo.List(c.Request.Context(), member.HouseholdID, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取购物清单失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   lists,
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
+	})
+}
+
+func (h *ShoppingListHandler) GetByID(c *gin.Context) {
+	list, ok := h.loadList(c, false)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ShoppingListHandler) Create(c *gin.Context) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return
+	}
+	if !member.CanWrite() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只读成员不能创建购物清单"})
+		return
+	}
+
+	var req CreateShoppingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	servings, order, ok := h.collectDishes(c, member.HouseholdID, &req)
+	if !ok {
+		return
+	}
+
+	inStock := make(map[uint]float64)
+	if !req.IgnorePantry {
+		levels, err := h.pantryRepo.StockLevels(c.Request.Context(), member.HouseholdID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取库存失败"})
+			return
+		}
+		inStock = levels
+	}
+	for _, stock := range req.InStock {
+		inStock[stock.IngredientID] += stock.Quantity
+	}
+
+	items, ok := h.buildItems(c, servings, order, inStock)
+	if !ok {
+		return
+	}
+
+	list := &models.ShoppingList{
+		HouseholdID: member.HouseholdID,
+		UserID:      member.UserID,
+		Name:        req.Name,
+		Items:       items,
+	}
+	if list.Name == "" {
+		list.Name = "购物清单 " + time.Now().Format("2006-01-02")
+	}
+	for _, item := range items {
+		list.EstimatedCost += item.EstimatedCost
+	}
+	list.EstimatedCost = models.RoundPrice(list.EstimatedCost)
+
+	if err := h.shoppingListRepo.Create(c.Request.Context(), list); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建购物清单失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, list)
+}
+
+func (h *ShoppingListHandler) Delete(c *gin.Context) {
+	list, ok := h.loadList(c, true)
+	if !ok {
+		return
+	}
+
+	if err := h.shoppingListRepo.Delete(c.Request.Context(), list.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除购物清单失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "购物清单删除成功"})
+}
+
+// UpdateItem 勾选或取消勾选清单条目
+func (h *ShoppingListHandler) UpdateItem(c *gin.Context) {
+	list, ok := h.loadList(c, true)
+	if !ok {
+		return
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的条目ID"})
+		return
+	}
+
+	var item *models.ShoppingListItem
+	for i := range list.Items {
+		if list.Items[i].ID == uint(itemID) {
+			item = &list.Items[i]
+			break
+		}
+	}
+	if item == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "购物清单条目不存在"})
+		return
+	}
+
+	var req UpdateShoppingListItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Checked != item.Checked {
+		item.Checked = req.Checked
+		item.CheckedAt = nil
+		if req.Checked {
+			now := time.Now()
+			item.CheckedAt = &now
+		}
+		if err := h.shoppingListRepo.UpdateItem(c.Request.Context(), item); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "更新购物清单条目失败"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// Export 导出清单，format 为 text（默认，便于粘贴到聊天中）或 csv
+func (h *ShoppingListHandler) Export(c *gin.Context) {
+	list, ok := h.loadList(c, false)
+	if !ok {
+		return
+	}
+
+	switch c.DefaultQuery("format", "text") {
+	case "text":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(shoppingListText(list)))
+	case "csv":
+		data, err := shoppingListCSV(list)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "导出购物清单失败"})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=shopping-list-%d.csv", list.ID))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的导出格式"})
+	}
+}
+
+// shoppingListText 纯文本格式，已勾选的条目标记为 [x]
+func shoppingListText(list *models.ShoppingList) string {
+	var b strings.Builder
+	b.WriteString(list.Name)
+	b.WriteString("\n")
+	for _, item := range list.Items {
+		mark := "[ ]"
+		if item.Checked {
+			mark = "[x]"
+		}
+		fmt.Fprintf(&b, "%s %s %s%s", mark, item.Name, formatQuantity(item.Quantity), item.Unit)
+		if item.EstimatedCost > 0 {
+			fmt.Fprintf(&b, " 约¥%.2f", item.EstimatedCost)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "预计花费 ¥%.2f\n", list.EstimatedCost)
+	return b.String()
+}
+
+// shoppingListCSV CSV 格式，带 UTF-8 BOM 以便 Excel 正确识别中文
+func shoppingListCSV(list *models.ShoppingList) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+
+	w := csv.NewWriter(&buf)
+	rows := [][]string{{"食材", "需要", "库存", "购买数量", "单位", "单价", "预计花费", "已购买"}}
+	for _, item := range list.Items {
+		checked := "否"
+		if item.Checked {
+			checked = "是"
+		}
+		rows = append(rows, []string{
+			item.Name,
+			formatQuantity(item.Required),
+			formatQuantity(item.InStock),
+			formatQuantity(item.Quantity),
+			item.Unit,
+			fmt.Sprintf("%.2f", item.UnitPrice),
+			fmt.Sprintf("%.2f", item.EstimatedCost),
+			checked,
+		})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatsHandler 当前家庭的花费和用餐统计
+type StatsHandler struct {
+	statsRepo     repositories.StatsRepository
+	householdRepo repositories.HouseholdRepository
+}
+
+func NewStatsHandler(statsRepo repositories.StatsRepository, householdRepo repositories.HouseholdRepository) *StatsHandler {
+	return &StatsHandler{
+		statsRepo:     statsRepo,
+		householdRepo: householdRepo,
+	}
+}
+
+// parseStatsFilter 解析 from/to 和 meal_type，返回当前家庭ID，失败时直接写入错误响应
+func (h *StatsHandler) parseStatsFilter(c *gin.Context) (uint, repositories.StatsFilter, bool) {
+	member, ok := currentMembership(c, h.householdRepo)
+	if !ok {
+		return 0, repositories.StatsFilter{}, false
+	}
+
+	from, to, ok := parseTimeRange(c)
+	if !ok {
+		return 0, repositories.StatsFilter{}, false
+	}
+	mealType := c.Query("meal_type")
+	if mealType != "" && !models.IsValidMealType(mealType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的餐次"})
+		return 0, repositories.StatsFilter{}, false
+	}
+	return member.HouseholdID, repositories.StatsFilter{From: from, To: to, MealType: mealType}, true
+}
+
+// Summary 总花费、用餐次数、平均每餐花费和吃过的不同菜品数
+func (h *StatsHandler) Summary(c *gin.Context) {
+	householdID, filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.statsRepo.Summary(c.Request.Context(), householdID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "统计花费失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// Spending 按 period=day|week|month 汇总每个时段的花费
+func (h *StatsHandler) Spending(c *gin.Context) {
+	householdID, filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+
+	period := c.DefaultQuery("period", repositories.StatsPeriodDay)
+	switch period {
+	case repositories.StatsPeriodDay, repositories.StatsPeriodWeek, repositories.StatsPeriodMonth:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period 只能为 day、week 或 month"})
+		return
+	}
+
+	buckets, err := h.statsRepo.Spending(c.Request.Context(), householdID, filter, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "统计花费失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"period": period, "data": buckets})
+}
+
+// Dishes 最常吃的菜品
+func (h *StatsHandler) Dishes(c *gin.Context) {
+	householdID, filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+	limit, ok := queryInt(c, "limit", 10, 1, 100, "limit 应为 1-100")
+	if !ok {
+		return
+	}
+
+	dishes, err := h.statsRepo.TopDishes(c.Request.Context(), householdID, filter, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "统计常吃的菜品失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dishes})
+}
+
+// Categories 最常吃的分类
+func (h *StatsHandler) Categories(c *gin.Context) {
+	householdID, filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+	limit, ok := queryInt(c, "limit", 10, 1, 100, "limit 应为 1-100")
+	if !ok {
+		return
+	}
+
+	categories, err := h.statsRepo.TopCategories(c.Request.Context(), householdID, filter, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "统计常吃的分类失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": categories})
+}
+
+// Streak 连续用餐中没有重复菜品的最长区间
+func (h *StatsHandler) Streak(c *gin.Context) {
+	householdID, filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+
+	streak, err := h.statsRepo.VarietyStreak(c.Request.Context(), householdID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "统计不重样的用餐失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, streak)
+}
+
+// Members 每位成员参与的用餐次数、平摊的花费和记录的次数
+func (h *StatsHandler) Members(c *gin.Context) {
+	householdID, filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.statsRepo.Members(c.Request.Context(), householdID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "统计成员用餐失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDishTags 每道菜最多的标签数量
+const maxDishTags = 20
+
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type TagHandler struct {
+	tagRepo repositories.TagRepository
+}
+
+func NewTagHandler(tagRepo repositories.TagRepository) *TagHandler {
+	return &TagHandler{
+		tagRepo: tagRepo,
+	}
+}
+
+type CreateTagRequest struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Color string `json:"color"` // #rrggbb，可以省略
+}
+
+type UpdateTagRequest struct {
+	Name  string  `json:"name" binding:"max=50"`
+	Color *string `json:"color"` // 传空字符串表示清除颜色
+}
+
+// checkTagColor 校验标签颜色，失败时直接写入错误响应
+func checkTagColor(c *gin.Context, color string) bool {
+	if color != "" && !tagColorPattern.MatchString(color) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "颜色格式应为 #rrggbb"})
+		return false
+	}
+	return true
+}
+
+// checkTagName 去除空白并检查名称是否与其他标签重复，失败时直接写入错误响应
+func (h *TagHandler) checkTagName(c *gin.Context, name string, id uint) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "标签名称不能为空"})
+		return "", false
+	}
+	if existing, err := h.tagRepo.GetByName(c.Request.Context(), name); err == nil && existing.ID != id {
+		c.JSON(http.StatusConflict, gin.H{"error": "标签名称已存在"})
+		return "", false
+	}
+	return name, true
+}
+
+func (h *TagHandler) List(c *gin.Context) {
+	tags, err := h.tagRepo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取标签列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": tags,
+	})
+}
+
+// Cloud 标签云，返回每个标签被多少道菜使用
+func (h *TagHandler) Cloud(c *gin.Context) {
+	usages, err := h.tagRepo.Cloud(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取标签云失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": usages,
+	})
+}
+
+func (h *TagHandler) Create(c *gin.Context) {
+	var req CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name, ok := h.checkTagName(c, req.Name, 0)
+	if !ok || !checkTagColor(c, req.Color) {
+		return
+	}
+
+	tag := &models.Tag{
+		Name:  name,
+		Color: req.Color,
+	}
+
+	if err := h.tagRepo.Create(c.Request.Context(), tag); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建标签失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+func (h *TagHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的标签ID"})
+		return
+	}
+
+	var req UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.tagRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "标签不存在"})
+		return
+	}
+
+	// 更新字段
+	if req.Name != "" {
+		name, ok := h.checkTagName(c, req.Name, tag.ID)
+		if !ok {
+			return
+		}
+		tag.Name = name
+	}
+	if req.Color != nil {
+		if !checkTagColor(c, *req.Color) {
+			return
+		}
+		tag.Color = *req.Color
+	}
+
+	if err := h.tagRepo.Update(c.Request.Context(), tag); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新标签失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// Delete 删除标签，菜品上的该标签随之移除
+func (h *TagHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的标签ID"})
+		return
+	}
+
+	if err := h.tagRepo.Delete(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "标签不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "标签删除成功"})
+}
+
+// loadTags 按ID查询菜品的标签，有不存在的标签时直接写入错误响应
+func loadTags(c *gin.Context, tagRepo repositories.TagRepository, ids []uint) ([]models.Tag, bool) {
+	ids = uniqueIDs(ids)
+	if len(ids) > maxDishTags {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "标签数量超过限制"})
+		return nil, false
+	}
+	tags, err := tagRepo.GetByIDs(c.Request.Context(), ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询标签失败"})
+		return nil, false
+	}
+	if len(tags) != len(ids) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "标签不存在"})
+		return nil, false
+	}
+	return tags, true
+}
+
+// parseIDList 解析逗号分隔的ID列表并去重，失败时直接写入错误响应
+func parseIDList(c *gin.Context, value, message string) ([]uint, bool) {
+	var ids []uint
+	for _, part := range splitQuery(value) {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": message})
+			return nil, false
+		}
+		ids = append(ids, uint(id))
+	}
+	return uniqueIDs(ids), true
+}
+
+func uniqueIDs(ids []uint) []uint {
+	result := make([]uint, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
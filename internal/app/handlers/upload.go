@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/config"
+	"foodcook/internal/pkg/storage"
+	"foodcook/internal/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type UploadHandler struct {
+	uploadRepo repositories.UploadRepository
+	storage    storage.Storage
+	cfg        config.UploadConfig
+}
+
+func NewUploadHandler(uploadRepo repositories.UploadRepository, store storage.Storage, cfg config.UploadConfig) *UploadHandler {
+	return &UploadHandler{
+		uploadRepo: uploadRepo,
+		storage:    store,
+		cfg:        cfg,
+	}
+}
+
+// isAllowed 检查嗅探得到的 MIME 类型是否在允许列表中
+func (h *UploadHandler) isAllowed(contentType string) bool {
+	for _, allowed := range h.cfg.AllowedTypes {
+		if allowed == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *UploadHandler) urlFor(key string) string {
+	return strings.TrimRight(h.cfg.BaseURL, "/") + "/" + key
+}
+
+// Upload 上传图片，表单字段为 file。返回的 url 可直接用于 image_url、avatar_url 等字段
+func (h *UploadHandler) Upload(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		return
+	}
+
+	// 预留 1MB 给 multipart 的其他部分
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.cfg.MaxSize+1<<20)
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "文件大小超过限制"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请选择要上传的文件"})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > h.cfg.MaxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "文件大小超过限制"})
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, h.cfg.MaxSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取文件失败"})
+		return
+	}
+	if int64(len(data)) > h.cfg.MaxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "文件大小超过限制"})
+		return
+	}
+
+	// 根据文件内容判断类型，忽略客户端声明的 Content-Type 和扩展名
+	contentType := http.DetectContentType(data)
+	if !h.isAllowed(contentType) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "不支持的文件类型: " + contentType})
+		return
+	}
+
+	thumbnail, thumbnailType, size, err := storage.Thumbnail(data, h.cfg.ThumbnailWidth)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无法解析图片"})
+		return
+	}
+
+	name, err := utils.NewTokenID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "上传失败"})
+		return
+	}
+	dir := time.Now().Format("2006/01")
+	key := path.Join(dir, name+storage.ExtensionFor(contentType))
+	thumbnailKey := path.Join(dir, name+"_thumb"+storage.ExtensionFor(thumbnailType))
+
+	ctx := c.Request.Context()
+	if err := h.storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		logrus.Errorf("store upload %s failed: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存文件失败"})
+		return
+	}
+	if err := h.storage.Put(ctx, thumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), thumbnailType); err != nil {
+		logrus.Errorf("store thumbnail %s failed: %v", thumbnailKey, err)
+		h.storage.Delete(ctx, key)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存文件失败"})
+		return
+	}
+
+	upload := &models.Upload{
+		UserID:       userID.(uint),
+		Key:          key,
+		ThumbnailKey: thumbnailKey,
+		URL:          h.urlFor(key),
+		ThumbnailURL: h.urlFor(thumbnailKey),
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		Width:        size.X,
+		Height:       size.Y,
+	}
+
+	if err := h.uploadRepo.Create(ctx, upload); err != nil {
+		h.storage.Delete(ctx, key)
+		h.storage.Delete(ctx, thumbnailKey)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存上传记录失败"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, upload)
+}
+
+// Serve 读取上传的文件。文件名是随机生成的，内容不会变化，可以长期缓存
+func (h *UploadHandler) Serve(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if key == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文件不存在"})
+		return
+	}
+
+	etag := fmt.Sprintf(`"%s"`, key)
+	cacheControl := fmt.Sprintf("public, max-age=%d, immutable", h.cfg.CacheMaxAge)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Header("ETag", etag)
+		c.Header("Cache-Control", cacheControl)
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	reader, info, err := h.storage.Get(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "文件不存在"})
+			return
+		}
+		logrus.Errorf("read upload %s failed: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败"})
+		return
+	}
+	defer reader.Close()
+
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", cacheControl)
+	c.Header("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.DataFromReader(http.StatusOK, info.Size, contentType, reader, nil)
+}
+
+// CollectOrphans 删除超过保留期仍未被引用的上传文件，返回清理的数量
+func (h *UploadHandler) CollectOrphans(ctx context.Context) (int, error) {
+	before := time.Now().Add(-time.Duration(h.cfg.OrphanTTLHours) * time.Hour)
+	removed := 0
+	for {
+		uploads, err := h.uploadRepo.ListOrphans(ctx, before, 100)
+		if err != nil {
+			return removed, err
+		}
+		if len(uploads) == 0 {
+			return removed, nil
+		}
+
+		for _, upload := range uploads {
+			if err := h.storage.Delete(ctx, upload.Key); err != nil {
+				return removed, fmt.Errorf("delete %s: %w", upload.Key, err)
+			}
+			if upload.ThumbnailKey != "" {
+				if err := h.storage.Delete(ctx, upload.ThumbnailKey); err != nil {
+					return removed, fmt.Errorf("delete %s: %w", upload.ThumbnailKey, err)
+				}
+			}
+			if err := h.uploadRepo.Delete(ctx, upload.ID); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+}
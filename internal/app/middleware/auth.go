@@ -4,12 +4,14 @@ import (
 	"net/http"
 	"strings"
 
+	"foodcook/internal/domain/repositories"
 	"foodcook/internal/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(revocationStore repositories.TokenRevocationStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -34,14 +36,27 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 检查令牌是否已被吊销
+		revoked, err := isRevoked(c, revocationStore, claims)
+		if err != nil {
+			logrus.Errorf("check token revocation failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Token verification failed"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
 		// 将用户信息存储到上下文中
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
+		setClaims(c, claims)
 		c.Next()
 	}
 }
 
-func OptionalAuthMiddleware() gin.HandlerFunc {
+func OptionalAuthMiddleware(revocationStore repositories.TokenRevocationStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -62,9 +77,34 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if revoked, err := isRevoked(c, revocationStore, claims); err != nil || revoked {
+			c.Next()
+			return
+		}
+
 		// 将用户信息存储到上下文中
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
+		setClaims(c, claims)
 		c.Next()
 	}
 }
+
+func isRevoked(c *gin.Context, revocationStore repositories.TokenRevocationStore, claims *utils.Claims) (bool, error) {
+	var keys []string
+	if claims.ID != "" {
+		keys = append(keys, utils.TokenRevocationKey(claims.ID))
+	}
+	if claims.FamilyID != "" {
+		keys = append(keys, utils.FamilyRevocationKey(claims.FamilyID))
+	}
+	if len(keys) == 0 {
+		return false, nil
+	}
+	return revocationStore.IsRevoked(c.Request.Context(), keys...)
+}
+
+func setClaims(c *gin.Context, claims *utils.Claims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("household_id", claims.HouseholdID)
+	c.Set("claims", claims)
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/cache"
+	"foodcook/internal/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// permissionKeyPrefix 权限缓存键前缀，完整格式为 perm:<user_id>:<jti>
+const permissionKeyPrefix = "perm:"
+
+// PermissionResolver 解析用户所属角色的权限，结果按访问令牌缓存，
+// 令牌有效期内不再重复查询数据库
+type PermissionResolver struct {
+	userRepo repositories.UserRepository
+	roleRepo repositories.RoleRepository
+	cache    cache.Cache
+}
+
+func NewPermissionResolver(userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, c cache.Cache) *PermissionResolver {
+	return &PermissionResolver{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+		cache:    c,
+	}
+}
+
+func permissionUserPrefix(userID uint) string {
+	return fmt.Sprintf("%s%d:", permissionKeyPrefix, userID)
+}
+
+// Resolve 返回令牌所属用户的角色
+func (p *PermissionResolver) Resolve(ctx context.Context, claims *utils.Claims) (*models.Role, error) {
+	// 旧令牌没有 jti，无法按令牌缓存
+	var key string
+	if claims.ID != "" {
+		key = permissionUserPrefix(claims.UserID) + claims.ID
+		var role models.Role
+		if cache.GetJSON(ctx, p.cache, key, &role) {
+			return &role, nil
+		}
+	}
+
+	user, err := p.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	role, err := p.roleRepo.GetByName(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if key != "" && claims.ExpiresAt != nil {
+		if ttl := time.Until(claims.ExpiresAt.Time); ttl > 0 {
+			cache.SetJSON(ctx, p.cache, key, role, ttl)
+		}
+	}
+	return role, nil
+}
+
+// InvalidateUser 用户角色变更后清除其所有令牌的权限缓存
+func (p *PermissionResolver) InvalidateUser(ctx context.Context, userID uint) {
+	cache.Invalidate(ctx, p.cache, permissionUserPrefix(userID))
+}
+
+// InvalidateAll 角色权限变更后清除全部权限缓存
+func (p *PermissionResolver) InvalidateAll(ctx context.Context) {
+	cache.Invalidate(ctx, p.cache, permissionKeyPrefix)
+}
+
+// loadRole 解析当前请求用户的角色并存入上下文
+func loadRole(c *gin.Context, resolver *PermissionResolver) (*models.Role, bool) {
+	if role, exists := c.Get("role"); exists {
+		return role.(*models.Role), true
+	}
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Abort()
+		return nil, false
+	}
+
+	role, err := resolver.Resolve(c.Request.Context(), claims.(*utils.Claims))
+	if err != nil {
+		logrus.Warnf("resolve permissions failed: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户不存在"})
+		c.Abort()
+		return nil, false
+	}
+
+	c.Set("role", role)
+	return role, true
+}
+
+// RequirePermission 要求当前用户拥有全部指定权限，需放在 AuthMiddleware 之后
+func RequirePermission(resolver *PermissionResolver, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := loadRole(c, resolver)
+		if !ok {
+			return
+		}
+
+		for _, permission := range permissions {
+			if !role.HasPermission(permission) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "缺少权限: " + permission})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// LoadPermissions 只加载权限而不做校验，供处理器通过 HasPermission 判断
+func LoadPermissions(resolver *PermissionResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := loadRole(c, resolver); !ok {
+			return
+		}
+		c.Next()
+	}
+}
+
+// HasPermission 判断当前请求用户是否拥有指定权限，需先经过 LoadPermissions 或 RequirePermission
+func HasPermission(c *gin.Context, permission string) bool {
+	role, exists := c.Get("role")
+	if !exists {
+		return false
+	}
+	return role.(*models.Role).HasPermission(permission)
+}
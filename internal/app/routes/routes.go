@@ -3,6 +3,8 @@ package routes
 import (
 	"foodcook/internal/app/handlers"
 	"foodcook/internal/app/middleware"
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
 	"net/http"
 	"time"
 
@@ -15,6 +17,21 @@ func SetupRoutes(
 	ingredientHandler *handlers.IngredientHandler,
 	mealRecordHandler *handlers.MealRecordHandler,
 	categoryHandler *handlers.CategoryHandler,
+	tagHandler *handlers.TagHandler,
+	preferenceHandler *handlers.PreferenceHandler,
+	cacheHandler *handlers.CacheHandler,
+	householdHandler *handlers.HouseholdHandler,
+	adminHandler *handlers.AdminHandler,
+	uploadHandler *handlers.UploadHandler,
+	mealPlanHandler *handlers.MealPlanHandler,
+	shoppingListHandler *handlers.ShoppingListHandler,
+	pantryHandler *handlers.PantryHandler,
+	recommendationHandler *handlers.RecommendationHandler,
+	menuHandler *handlers.MenuHandler,
+	statsHandler *handlers.StatsHandler,
+	budgetHandler *handlers.BudgetHandler,
+	permissionResolver *middleware.PermissionResolver,
+	revocationStore repositories.TokenRevocationStore,
 ) *gin.Engine {
 	r := gin.Default()
 
@@ -30,6 +47,14 @@ func SetupRoutes(
 		})
 	})
 
+	authRequired := middleware.AuthMiddleware(revocationStore)
+	requirePermission := func(permissions ...string) gin.HandlerFunc {
+		return middleware.RequirePermission(permissionResolver, permissions...)
+	}
+
+	// 上传文件访问，带缓存头
+	r.GET("/uploads/*key", uploadHandler.Serve)
+
 	// API路由组
 	api := r.Group("/api")
 	{
@@ -38,50 +63,192 @@ func SetupRoutes(
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
-			auth.GET("/profile", middleware.AuthMiddleware(), authHandler.GetProfile)
+			auth.POST("/refresh", authHandler.Refresh)
+			auth.POST("/logout", authRequired, authHandler.Logout)
+			auth.POST("/logout-all", authRequired, authHandler.LogoutAll)
+			auth.POST("/switch-household", authRequired, authHandler.SwitchHousehold)
+			auth.GET("/profile", authRequired, authHandler.GetProfile)
+			auth.PUT("/profile/dietary", authRequired, authHandler.UpdateDietaryRestrictions) // 修改自己的饮食限制
 		}
 
-		// 菜品路由 - 只有 root 用户可以管理
+		// 菜品路由 - 需要 dish:write 权限才能管理
 		dishes := api.Group("/dishes")
 		{
-			dishes.GET("", dishHandler.List)          // 所有用户都可以查看菜品列表
-			dishes.GET("/:id", dishHandler.GetByID)   // 所有用户都可以查看菜品详情
-			dishes.GET("/search", dishHandler.Search) // 所有用户都可以搜索菜品
-			// 以下操作需要 root 权限
-			dishes.POST("", middleware.AuthMiddleware(), middleware.RootMiddleware(), dishHandler.Create)
-			dishes.PUT("/:id", middleware.AuthMiddleware(), middleware.RootMiddleware(), dishHandler.Update)
-			dishes.DELETE("/:id", middleware.AuthMiddleware(), middleware.RootMiddleware(), dishHandler.Delete)
+			dishes.GET("", dishHandler.List)                           // 所有用户都可以查看菜品列表
+			dishes.GET("/:id", dishHandler.GetByID)                    // 所有用户都可以查看菜品详情
+			dishes.GET("/search", dishHandler.Search)                  // 所有用户都可以搜索菜品
+			dishes.GET("/dietary-options", dishHandler.DietaryOptions) // 可用的过敏原、饮食标签和辣度
+			dishWrite := requirePermission(models.PermissionDishWrite)
+			dishes.POST("", authRequired, dishWrite, dishHandler.Create)
+			dishes.POST("/import", authRequired, dishWrite, dishHandler.Import)                // 从菜谱链接生成草稿
+			dishes.POST("/import/confirm", authRequired, dishWrite, dishHandler.ConfirmImport) // 确认草稿并创建菜品
+			dishes.PUT("/:id", authRequired, dishWrite, dishHandler.Update)
+			dishes.DELETE("/:id", authRequired, dishWrite, dishHandler.Delete)
+
+			// 评分和收藏 - 登录用户都可以操作自己的
+			dishes.GET("/:id/ratings", authRequired, preferenceHandler.ListRatings)
+			dishes.PUT("/:id/rating", authRequired, preferenceHandler.Rate)
+			dishes.DELETE("/:id/rating", authRequired, preferenceHandler.DeleteRating)
+			dishes.PUT("/:id/favorite", authRequired, preferenceHandler.AddFavorite)
+			dishes.DELETE("/:id/favorite", authRequired, preferenceHandler.RemoveFavorite)
 		}
 
-		// 食材路由 - 只有 root 用户可以管理
+		// 当前用户收藏的菜品
+		api.GET("/favorites", authRequired, preferenceHandler.Favorites)
+
+		// 食材路由 - 需要 ingredient:write 权限才能管理
 		ingredients := api.Group("/ingredients")
 		{
-			ingredients.GET("", ingredientHandler.List) // 所有用户都可以查看食材列表
-			// 以下操作需要 root 权限
-			ingredients.POST("", middleware.AuthMiddleware(), middleware.RootMiddleware(), ingredientHandler.Create)
-			ingredients.PUT("/:id", middleware.AuthMiddleware(), middleware.RootMiddleware(), ingredientHandler.Update)
-			ingredients.DELETE("/:id", middleware.AuthMiddleware(), middleware.RootMiddleware(), ingredientHandler.Delete)
+			ingredients.GET("", ingredientHandler.List)        // 所有用户都可以查看食材列表
+			ingredients.GET("/units", ingredientHandler.Units) // 支持的单位
+			ingredients.GET("/:id/price-history", ingredientHandler.PriceHistory)
+			ingredientWrite := requirePermission(models.PermissionIngredientWrite)
+			ingredients.POST("", authRequired, ingredientWrite, ingredientHandler.Create)
+			ingredients.POST("/nutrition/import", authRequired, ingredientWrite, ingredientHandler.ImportNutrition) // 从 CSV 批量导入营养成分
+			ingredients.PUT("/:id", authRequired, ingredientWrite, ingredientHandler.Update)
+			ingredients.DELETE("/:id", authRequired, ingredientWrite, ingredientHandler.Delete)
 		}
 
-		// 分类路由 - 只有 root 用户可以管理
+		// 分类路由 - 需要 category:write 权限才能管理
 		categories := api.Group("/categories")
 		{
-			categories.GET("", categoryHandler.List) // 所有用户都可以查看分类列表
-			// 以下操作需要 root 权限
-			categories.POST("", middleware.AuthMiddleware(), middleware.RootMiddleware(), categoryHandler.Create)
-			categories.PUT("/:id", middleware.AuthMiddleware(), middleware.RootMiddleware(), categoryHandler.Update)
-			categories.DELETE("/:id", middleware.AuthMiddleware(), middleware.RootMiddleware(), categoryHandler.Delete)
+			categories.GET("", categoryHandler.List)      // 所有用户都可以查看分类列表
+			categories.GET("/tree", categoryHandler.Tree) // 按上下级关系组成的分类树
+			categoryWrite := requirePermission(models.PermissionCategoryWrite)
+			categories.POST("", authRequired, categoryWrite, categoryHandler.Create)
+			categories.PUT("/:id", authRequired, categoryWrite, categoryHandler.Update)
+			categories.DELETE("/:id", authRequired, categoryWrite, categoryHandler.Delete)
+		}
+
+		// 标签路由 - 需要 tag:write 权限才能管理
+		tags := api.Group("/tags")
+		{
+			tags.GET("", tagHandler.List)        // 所有用户都可以查看标签列表
+			tags.GET("/cloud", tagHandler.Cloud) // 标签云，附带使用次数
+			tagWrite := requirePermission(models.PermissionTagWrite)
+			tags.POST("", authRequired, tagWrite, tagHandler.Create)
+			tags.PUT("/:id", authRequired, tagWrite, tagHandler.Update)
+			tags.DELETE("/:id", authRequired, tagWrite, tagHandler.Delete)
 		}
 
 		// 用餐记录路由
-		mealRecords := api.Group("/meal-records")
+		// 拥有 meal:read:any 权限的用户可以查看任意家庭的记录
+		mealRecords := api.Group("/meal-records", authRequired, middleware.LoadPermissions(permissionResolver))
+		{
+			mealRecords.GET("", mealRecordHandler.List)
+			mealRecords.POST("", mealRecordHandler.Create)
+			mealRecords.GET("/nutrition", mealRecordHandler.DailyNutrition) // 每日营养汇总
+			mealRecords.GET("/:id", mealRecordHandler.GetByID)
+			mealRecords.GET("/:id/nutrition", mealRecordHandler.Nutrition)
+			mealRecords.PUT("/:id", mealRecordHandler.Update)
+			mealRecords.DELETE("/:id", mealRecordHandler.Delete)
+			mealRecords.GET("/:id/comments", mealRecordHandler.ListComments)
+			mealRecords.POST("/:id/comments", mealRecordHandler.CreateComment)
+			mealRecords.DELETE("/:id/comments/:comment_id", mealRecordHandler.DeleteComment)
+			mealRecords.POST("/:id/photos", mealRecordHandler.AddPhoto)
+			mealRecords.PUT("/:id/photos/order", mealRecordHandler.ReorderPhotos)
+			mealRecords.PUT("/:id/photos/:photo_id", mealRecordHandler.UpdatePhoto)
+			mealRecords.DELETE("/:id/photos/:photo_id", mealRecordHandler.DeletePhoto)
+		}
+
+		// 用餐计划路由 - 家庭成员共享
+		mealPlans := api.Group("/meal-plans", authRequired)
+		{
+			mealPlans.GET("", mealPlanHandler.List)
+			mealPlans.POST("", mealPlanHandler.Create)
+			mealPlans.GET("/:id", mealPlanHandler.GetByID)
+			mealPlans.PUT("/:id", mealPlanHandler.Update)
+			mealPlans.DELETE("/:id", mealPlanHandler.Delete)
+			mealPlans.POST("/:id/copy-last-week", mealPlanHandler.CopyLastWeek)
+			mealPlans.POST("/:id/slots", mealPlanHandler.CreateSlot)
+			mealPlans.PUT("/:id/slots/:slot_id", mealPlanHandler.UpdateSlot)
+			mealPlans.DELETE("/:id/slots/:slot_id", mealPlanHandler.DeleteSlot)
+			mealPlans.POST("/:id/slots/:slot_id/eaten", mealPlanHandler.MarkEaten)
+		}
+
+		// 购物清单路由 - 家庭成员共享
+		shoppingLists := api.Group("/shopping-lists", authRequired)
 		{
-			mealRecords.GET("", middleware.AuthMiddleware(), mealRecordHandler.List)
-			mealRecords.POST("", middleware.AuthMiddleware(), mealRecordHandler.Create)
-			mealRecords.GET("/:id", middleware.AuthMiddleware(), mealRecordHandler.GetByID)
-			mealRecords.PUT("/:id", middleware.AuthMiddleware(), mealRecordHandler.Update)
-			mealRecords.DELETE("/:id", middleware.AuthMiddleware(), mealRecordHandler.Delete)
+			shoppingLists.GET("", shoppingListHandler.List)
+			shoppingLists.POST("", shoppingListHandler.Create)
+			shoppingLists.GET("/:id", shoppingListHandler.GetByID)
+			shoppingLists.GET("/:id/export", shoppingListHandler.Export)
+			shoppingLists.DELETE("/:id", shoppingListHandler.Delete)
+			shoppingLists.PUT("/:id/items/:item_id", shoppingListHandler.UpdateItem)
 		}
+
+		// 库存路由 - 家庭成员共享
+		pantry := api.Group("/pantry", authRequired)
+		{
+			pantry.GET("", pantryHandler.List)
+			pantry.POST("", pantryHandler.Create)
+			pantry.GET("/expiring", pantryHandler.Expiring)
+			pantry.POST("/consume", pantryHandler.Consume)
+			pantry.PUT("/:id", pantryHandler.Update)
+			pantry.DELETE("/:id", pantryHandler.Delete)
+		}
+
+		// 今天吃什么 - 按当前家庭的用餐历史、收藏和库存推荐
+		api.GET("/recommendations", authRequired, recommendationHandler.Recommend)
+
+		// 随机菜单 - 按分类和标签的数量要求组合菜品，选中的菜单可保存为用餐记录或计划餐次
+		menus := api.Group("/menus", authRequired)
+		{
+			menus.POST("/generate", menuHandler.Generate)
+			menus.POST("/apply", menuHandler.Apply)
+		}
+
+		// 统计 - 当前家庭的花费、常吃的菜品和成员用餐情况
+		stats := api.Group("/stats", authRequired)
+		{
+			stats.GET("/summary", statsHandler.Summary)
+			stats.GET("/spending", statsHandler.Spending)
+			stats.GET("/dishes", statsHandler.Dishes)
+			stats.GET("/categories", statsHandler.Categories)
+			stats.GET("/streak", statsHandler.Streak)
+			stats.GET("/members", statsHandler.Members)
+		}
+
+		// 预算 - 家庭和个人的月度预算，personal=true 时为当前用户的个人预算
+		budgets := api.Group("/budgets", authRequired)
+		{
+			budgets.GET("", budgetHandler.History)
+			budgets.GET("/:month", budgetHandler.Get)
+			budgets.PUT("/:month", budgetHandler.Save)
+			budgets.DELETE("/:month", budgetHandler.Delete)
+		}
+
+		// 家庭路由 - 成员共享用餐记录
+		households := api.Group("/households", authRequired)
+		{
+			households.GET("", householdHandler.List)
+			households.POST("", householdHandler.Create)
+			households.POST("/join", householdHandler.Join)
+			households.GET("/:id", householdHandler.GetByID)
+			households.PUT("/:id", householdHandler.Update)
+			households.DELETE("/:id", householdHandler.Delete)
+			households.POST("/:id/invitations", householdHandler.CreateInvitation)
+			households.PUT("/:id/members/:user_id", householdHandler.UpdateMemberRole)
+			households.DELETE("/:id/members/:user_id", householdHandler.RemoveMember)
+		}
+
+		// 图片上传
+		api.POST("/uploads", authRequired, uploadHandler.Upload)
+
+		// 管理路由 - 角色与用户权限
+		admin := api.Group("/admin", authRequired, requirePermission(models.PermissionUserManage))
+		{
+			admin.GET("/permissions", adminHandler.ListPermissions)
+			admin.GET("/roles", adminHandler.ListRoles)
+			admin.POST("/roles", adminHandler.CreateRole)
+			admin.PUT("/roles/:id", adminHandler.UpdateRole)
+			admin.DELETE("/roles/:id", adminHandler.DeleteRole)
+			admin.GET("/users", adminHandler.ListUsers)
+			admin.PUT("/users/:id/role", adminHandler.AssignRole)
+		}
+
+		// 缓存统计
+		api.GET("/cache/stats", authRequired, requirePermission(models.PermissionCacheRead), cacheHandler.Stats)
 	}
 
 	return r
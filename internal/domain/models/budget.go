@@ -0,0 +1,81 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// BudgetMonthLayout 预算月份的格式
+const BudgetMonthLayout = "2006-01"
+
+// 预算的使用程度，花费达到预算的 80% 时提醒，达到 100% 时为超支
+const (
+	BudgetLevelOK       = "ok"
+	BudgetLevelWarning  = "warning"
+	BudgetLevelExceeded = "exceeded"
+
+	BudgetWarningRatio = 0.8
+)
+
+// Budget 家庭或成员个人某个月的用餐预算，花费按用餐记录的总价计算。
+// 个人预算只统计该成员记录的用餐
+type Budget struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HouseholdID uint      `json:"household_id" gorm:"not null;index"`
+	UserID      *uint     `json:"user_id,omitempty" gorm:"index"` // 为空时为家庭预算
+	Month       string    `json:"month" gorm:"size:7;not null;index"`
+	Amount      float64   `json:"amount" gorm:"type:decimal(10,2);not null"`
+	Notes       string    `json:"notes" gorm:"size:255"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 关联关系
+	CategoryLimits []BudgetCategoryLimit `json:"category_limits,omitempty" gorm:"foreignKey:BudgetID"`
+}
+
+func (Budget) TableName() string {
+	return "budgets"
+}
+
+// BudgetCategoryLimit 预算中某个分类（含下级分类）的限额
+type BudgetCategoryLimit struct {
+	ID         uint    `json:"id" gorm:"primaryKey"`
+	BudgetID   uint    `json:"budget_id" gorm:"not null;index"`
+	CategoryID uint    `json:"category_id" gorm:"not null"`
+	Amount     float64 `json:"amount" gorm:"type:decimal(10,2);not null"`
+
+	// 关联关系
+	Category *Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+}
+
+func (BudgetCategoryLimit) TableName() string {
+	return "budget_category_limits"
+}
+
+// MonthRange 返回预算月份的起止时间，按服务器本地时区，结束时间不包含
+func (b *Budget) MonthRange() (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(BudgetMonthLayout, b.Month, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// BudgetLevel 按花费占预算的比例判断使用程度
+func BudgetLevel(spent, amount float64) string {
+	switch {
+	case spent >= amount:
+		return BudgetLevelExceeded
+	case spent >= amount*BudgetWarningRatio:
+		return BudgetLevelWarning
+	}
+	return BudgetLevelOK
+}
+
+// BudgetPercent 花费占预算的百分比，保留一位小数
+func BudgetPercent(spent, amount float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	return math.Round(spent/amount*1000) / 10
+}
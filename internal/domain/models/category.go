@@ -10,9 +10,15 @@ type Category struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
 	Name        string         `json:"name" gorm:"size:50;not null"`
 	Description string         `json:"description" gorm:"type:text"`
+	ParentID    *uint          `json:"parent_id" gorm:"index"` // 上级分类，为空时是顶级分类
+	SortOrder   int            `json:"sort_order" gorm:"not null;default:0"`
+	Icon        string         `json:"icon" gorm:"size:255"` // 图标，emoji 或图片地址
 	CreatedAt   time.Time      `json:"created_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// Children 下级分类，仅在分类树中返回
+	Children []*Category `json:"children,omitempty" gorm:"-"`
+
 	// 关联关系
 	Dishes []Dish `json:"dishes,omitempty" gorm:"foreignKey:CategoryID"`
 }
@@ -20,3 +26,49 @@ type Category struct {
 func (Category) TableName() string {
 	return "categories"
 }
+
+// BuildCategoryTree 把已排序的分类列表组装为树，同一级保持原有顺序。
+// 上级分类不存在时作为顶级分类处理
+func BuildCategoryTree(categories []*Category) []*Category {
+	nodes := make(map[uint]*Category, len(categories))
+	for _, category := range categories {
+		node := *category
+		node.Children = []*Category{}
+		nodes[node.ID] = &node
+	}
+
+	roots := []*Category{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if node.ParentID != nil {
+			if parent, ok := nodes[*node.ParentID]; ok && parent.ID != node.ID {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// CategoryDescendantIDs 返回 id 及其所有下级分类的ID
+func CategoryDescendantIDs(categories []*Category, id uint) []uint {
+	children := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{id}
+	seen := map[uint]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 过敏原标识，按常见的九类过敏原划分
+const (
+	AllergenPeanut    = "peanut"
+	AllergenTreeNut   = "tree_nut"
+	AllergenMilk      = "milk"
+	AllergenEgg       = "egg"
+	AllergenFish      = "fish"
+	AllergenShellfish = "shellfish"
+	AllergenSoy       = "soy"
+	AllergenWheat     = "wheat"
+	AllergenSesame    = "sesame"
+)
+
+// Allergens 所有过敏原及中文名称
+var Allergens = map[string]string{
+	AllergenPeanut:    "花生",
+	AllergenTreeNut:   "坚果",
+	AllergenMilk:      "乳制品",
+	AllergenEgg:       "蛋类",
+	AllergenFish:      "鱼类",
+	AllergenShellfish: "甲壳类及贝类",
+	AllergenSoy:       "大豆",
+	AllergenWheat:     "小麦（麸质）",
+	AllergenSesame:    "芝麻",
+}
+
+// 饮食标签，菜品上标注是否符合，用户上表示只吃符合的菜品
+const (
+	DietVegetarian = "vegetarian"
+	DietHalal      = "halal"
+)
+
+// Diets 所有饮食标签及中文名称
+var Diets = map[string]string{
+	DietVegetarian: "素食",
+	DietHalal:      "清真",
+}
+
+// 辣度，0 为不辣
+const (
+	SpicyNone   = 0
+	SpicyMild   = 1
+	SpicyMedium = 2
+	SpicyHot    = 3
+)
+
+// SpicyLevels 辣度的中文名称
+var SpicyLevels = []string{"不辣", "微辣", "中辣", "特辣"}
+
+// IsValidSpicyLevel 检查辣度是否有效
+func IsValidSpicyLevel(level int) bool {
+	return level >= SpicyNone && level <= SpicyHot
+}
+
+// NormalizeAllergens 去除空白、转为小写并去重排序，包含未知的过敏原时返回错误
+func NormalizeAllergens(allergens []string) ([]string, error) {
+	return normalizeLabels(allergens, Allergens, "过敏原")
+}
+
+// NormalizeDiets 去除空白、转为小写并去重排序，包含未知的饮食标签时返回错误
+func NormalizeDiets(diets []string) ([]string, error) {
+	return normalizeLabels(diets, Diets, "饮食标签")
+}
+
+func normalizeLabels(labels []string, known map[string]string, kind string) ([]string, error) {
+	result := []string{}
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if label == "" || seen[label] {
+			continue
+		}
+		if _, ok := known[label]; !ok {
+			return nil, fmt.Errorf("无效的%s: %s", kind, label)
+		}
+		seen[label] = true
+		result = append(result, label)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// DietaryRestrictions 用户的饮食限制，零值表示没有限制
+type DietaryRestrictions struct {
+	Allergens     []string `json:"allergens" gorm:"serializer:json;type:text"` // 过敏或忌口的过敏原
+	Diets         []string `json:"diets" gorm:"serializer:json;type:text"`     // 只吃符合这些饮食标签的菜品
+	MaxSpicyLevel *int     `json:"max_spicy_level"`                            // 能接受的最高辣度，为空时不限
+}
+
+// IsZero 没有任何饮食限制
+func (r DietaryRestrictions) IsZero() bool {
+	return len(r.Allergens) == 0 && len(r.Diets) == 0 && r.MaxSpicyLevel == nil
+}
+
+// Violations 返回菜品违反饮食限制的原因，需要预加载菜品的食材
+func (r DietaryRestrictions) Violations(dish *Dish) []string {
+	var reasons []string
+	dishAllergens := dish.CollectAllergens()
+	for _, allergen := range r.Allergens {
+		if containsString(dishAllergens, allergen) {
+			reasons = append(reasons, "含有"+Allergens[allergen])
+		}
+	}
+	for _, diet := range r.Diets {
+		if !dish.HasDiet(diet) {
+			reasons = append(reasons, "不是"+Diets[diet]+"菜品")
+		}
+	}
+	if r.MaxSpicyLevel != nil && dish.SpicyLevel > *r.MaxSpicyLevel {
+		reasons = append(reasons, "辣度为"+SpicyLevels[dish.SpicyLevel])
+	}
+	return reasons
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
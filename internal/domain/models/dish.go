@@ -1,6 +1,7 @@
 package models
 
 import (
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
@@ -14,16 +15,131 @@ type Dish struct {
 	Price       float64        `json:"price" gorm:"type:decimal(10,2);not null"`
 	CookingLink string         `json:"cooking_link" gorm:"size:255"`
 	CategoryID  *uint          `json:"category_id"`
+	AutoPrice   bool           `json:"auto_price" gorm:"not null;default:false"` // 价格随食材成本自动更新
+	PrepMinutes int            `json:"prep_minutes" gorm:"not null;default:0"`
+	CookMinutes int            `json:"cook_minutes" gorm:"not null;default:0"`
+	Difficulty  string         `json:"difficulty" gorm:"size:10"`
+	Servings    int            `json:"servings" gorm:"not null;default:1"` // 食材用量对应的份数
+	Vegetarian  bool           `json:"vegetarian" gorm:"not null;default:false"`
+	Halal       bool           `json:"halal" gorm:"not null;default:false"`
+	SpicyLevel  int            `json:"spicy_level" gorm:"not null;default:0"` // 0 不辣到 3 特辣
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// IngredientCost 按食材当前单价计算的成本，不保存
+	IngredientCost float64 `json:"ingredient_cost" gorm:"-"`
+	// Allergens 由食材的过敏原汇总而来，不保存
+	Allergens []string `json:"allergens" gorm:"-"`
+	// Nutrition 按食材用量计算的营养成分合计，仅在菜品详情中返回
+	Nutrition           *NutritionSummary `json:"nutrition,omitempty" gorm:"-"`
+	NutritionPerServing *Nutrition        `json:"nutrition_per_serving,omitempty" gorm:"-"`
+	// 评分汇总和被吃过的次数，不保存
+	AverageRating float64 `json:"average_rating" gorm:"-"`
+	RatingCount   int64   `json:"rating_count" gorm:"-"`
+	TimesEaten    int64   `json:"times_eaten" gorm:"-"`
+
 	// 关联关系
 	Category    *Category        `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
 	Ingredients []DishIngredient `json:"ingredients,omitempty" gorm:"foreignKey:DishID"`
+	Steps       []DishStep       `json:"steps,omitempty" gorm:"foreignKey:DishID"`
+	Tags        []Tag            `json:"tags,omitempty" gorm:"many2many:dish_tags"`
 	MealRecords []MealRecordDish `json:"meal_records,omitempty" gorm:"foreignKey:DishID"`
 }
 
 func (Dish) TableName() string {
 	return "dishes"
 }
+
+// ApplyStats 填充评分汇总和被吃过的次数
+func (d *Dish) ApplyStats(stats DishStats) {
+	d.AverageRating = stats.AverageRating
+	d.RatingCount = stats.RatingCount
+	d.TimesEaten = stats.TimesEaten
+}
+
+// 菜谱难度
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// IsValidDifficulty 检查难度是否有效，空字符串表示未设置
+func IsValidDifficulty(difficulty string) bool {
+	switch difficulty {
+	case "", DifficultyEasy, DifficultyMedium, DifficultyHard:
+		return true
+	}
+	return false
+}
+
+// ScaleServings 把食材用量换算为 servings 份，需要预加载食材
+func (d *Dish) ScaleServings(servings int) {
+	if servings <= 0 || d.Servings <= 0 || servings == d.Servings {
+		return
+	}
+	factor := float64(servings) / float64(d.Servings)
+	for i := range d.Ingredients {
+		d.Ingredients[i].Quantity = RoundQuantity(d.Ingredients[i].Quantity * factor)
+	}
+	d.Servings = servings
+}
+
+// CalculateIngredientCost 按食材当前单价计算菜品的食材成本，需要预加载食材
+func (d *Dish) CalculateIngredientCost() float64 {
+	var cost float64
+	for _, di := range d.Ingredients {
+		if di.Ingredient != nil {
+			cost += di.Quantity * di.Ingredient.Price
+		}
+	}
+	return RoundPrice(cost)
+}
+
+// CalculateNutrition 按食材用量汇总菜品全部份数的营养成分，需要预加载食材及其换算关系。
+// 没有营养数据或单位无法换算为克的食材不计入，记录在 Missing 中
+func (d *Dish) CalculateNutrition() *NutritionSummary {
+	summary := &NutritionSummary{}
+	for _, di := range d.Ingredients {
+		if di.Ingredient == nil {
+			continue
+		}
+		grams, err := di.Ingredient.Grams(di.Quantity)
+		if err != nil || di.Ingredient.Nutrition.IsZero() {
+			summary.addMissing(di.Ingredient.Name)
+			continue
+		}
+		summary.Nutrition = summary.Nutrition.Add(di.Ingredient.Nutrition.Scale(grams / 100))
+	}
+	summary.Nutrition = summary.Nutrition.Round()
+	return summary
+}
+
+// CollectAllergens 汇总食材的过敏原并排序，需要预加载食材
+func (d *Dish) CollectAllergens() []string {
+	allergens := []string{}
+	for _, di := range d.Ingredients {
+		if di.Ingredient == nil {
+			continue
+		}
+		for _, allergen := range di.Ingredient.Allergens {
+			if !containsString(allergens, allergen) {
+				allergens = append(allergens, allergen)
+			}
+		}
+	}
+	sort.Strings(allergens)
+	return allergens
+}
+
+// HasDiet 检查菜品是否符合饮食标签
+func (d *Dish) HasDiet(diet string) bool {
+	switch diet {
+	case DietVegetarian:
+		return d.Vegetarian
+	case DietHalal:
+		return d.Halal
+	}
+	return false
+}
@@ -1,10 +1,12 @@
 package models
 
+import "math"
+
 type DishIngredient struct {
 	ID           uint    `json:"id" gorm:"primaryKey"`
 	DishID       uint    `json:"dish_id" gorm:"not null"`
 	IngredientID uint    `json:"ingredient_id" gorm:"not null"`
-	Quantity     float64 `json:"quantity" gorm:"type:decimal(10,2);not null"`
+	Quantity     float64 `json:"quantity" gorm:"type:decimal(12,4);not null"`
 
 	// 关联关系
 	Dish       *Dish       `json:"dish,omitempty" gorm:"foreignKey:DishID"`
@@ -14,3 +16,8 @@ type DishIngredient struct {
 func (DishIngredient) TableName() string {
 	return "dish_ingredients"
 }
+
+// RoundQuantity 食材数量保留四位小数，克换算为斤等较大的单位时不至于变成 0
+func RoundQuantity(quantity float64) float64 {
+	return math.Round(quantity*10000) / 10000
+}
@@ -0,0 +1,15 @@
+package models
+
+// DishStep 菜谱步骤，按 SortOrder 排列
+type DishStep struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	DishID       uint   `json:"dish_id" gorm:"not null;index"`
+	SortOrder    int    `json:"sort_order" gorm:"not null;default:0"`
+	Content      string `json:"content" gorm:"type:text;not null"`
+	TimerSeconds int    `json:"timer_seconds" gorm:"not null;default:0"` // 步骤计时，0 表示不需要计时
+	ImageURL     string `json:"image_url" gorm:"size:255"`
+}
+
+func (DishStep) TableName() string {
+	return "dish_steps"
+}
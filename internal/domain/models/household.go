@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	HouseholdRoleOwner  = "owner"
+	HouseholdRoleMember = "member"
+	HouseholdRoleViewer = "viewer"
+)
+
+// Household 家庭，成员共享菜品和用餐记录。
+// 每个用户都有一个个人家庭（IsPersonal），用于存放只属于自己的记录
+type Household struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	Name       string         `json:"name" gorm:"size:100;not null"`
+	OwnerID    uint           `json:"owner_id" gorm:"not null;index"`
+	IsPersonal bool           `json:"is_personal" gorm:"default:false;not null"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联关系
+	Members []HouseholdMember `json:"members,omitempty" gorm:"foreignKey:HouseholdID"`
+}
+
+func (Household) TableName() string {
+	return "households"
+}
+
+// PersonalHouseholdName 个人家庭的默认名称
+func PersonalHouseholdName(username string) string {
+	return username + "的家"
+}
+
+type HouseholdMember struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HouseholdID uint      `json:"household_id" gorm:"not null;uniqueIndex:idx_household_user"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_household_user;index"`
+	Role        string    `json:"role" gorm:"size:20;not null"` // owner, member, viewer
+	CreatedAt   time.Time `json:"created_at"`
+
+	// 关联关系
+	Household *Household `json:"household,omitempty" gorm:"foreignKey:HouseholdID"`
+	User      *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+func (HouseholdMember) TableName() string {
+	return "household_members"
+}
+
+// CanWrite 是否可以创建和编辑家庭中的用餐记录
+func (m *HouseholdMember) CanWrite() bool {
+	return m.Role == HouseholdRoleOwner || m.Role == HouseholdRoleMember
+}
+
+// IsOwner 是否为家庭所有者
+func (m *HouseholdMember) IsOwner() bool {
+	return m.Role == HouseholdRoleOwner
+}
+
+// IsValidHouseholdRole 检查成员角色是否合法
+func IsValidHouseholdRole(role string) bool {
+	switch role {
+	case HouseholdRoleOwner, HouseholdRoleMember, HouseholdRoleViewer:
+		return true
+	}
+	return false
+}
+
+// HouseholdInvitation 家庭邀请码
+type HouseholdInvitation struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HouseholdID uint      `json:"household_id" gorm:"not null;index"`
+	Code        string    `json:"code" gorm:"size:32;uniqueIndex;not null"`
+	Role        string    `json:"role" gorm:"size:20;not null"`
+	CreatedBy   uint      `json:"created_by" gorm:"not null"`
+	MaxUses     int       `json:"max_uses" gorm:"not null;default:1"`
+	UsedCount   int       `json:"used_count" gorm:"not null;default:0"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// 关联关系
+	Household *Household `json:"household,omitempty" gorm:"foreignKey:HouseholdID"`
+}
+
+func (HouseholdInvitation) TableName() string {
+	return "household_invitations"
+}
+
+// Usable 邀请码是否仍可使用
+func (i *HouseholdInvitation) Usable(now time.Time) bool {
+	return now.Before(i.ExpiresAt) && i.UsedCount < i.MaxUses
+}
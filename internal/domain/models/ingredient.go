@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"foodcook/internal/pkg/units"
+
 	"gorm.io/gorm"
 )
 
@@ -11,13 +13,44 @@ type Ingredient struct {
 	Name      string         `json:"name" gorm:"size:100;not null"`
 	Price     float64        `json:"price" gorm:"type:decimal(10,2);not null"`
 	Unit      string         `json:"unit" gorm:"size:20;not null"`
+	Nutrition Nutrition      `json:"nutrition" gorm:"embedded;embeddedPrefix:nutrition_"` // 每 100 克的营养成分
+	Allergens []string       `json:"allergens" gorm:"serializer:json;type:text"`          // 含有的过敏原，菜品自动继承
 	CreatedAt time.Time      `json:"created_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// 关联关系
-	DishIngredients []DishIngredient `json:"dish_ingredients,omitempty" gorm:"foreignKey:IngredientID"`
+	DishIngredients []DishIngredient           `json:"dish_ingredients,omitempty" gorm:"foreignKey:IngredientID"`
+	Conversions     []IngredientUnitConversion `json:"conversions,omitempty" gorm:"foreignKey:IngredientID"`
 }
 
 func (Ingredient) TableName() string {
 	return "ingredients"
 }
+
+// UnitConversions 返回食材的换算关系，需要预加载 Conversions
+func (i *Ingredient) UnitConversions() []units.Conversion {
+	conversions := make([]units.Conversion, 0, len(i.Conversions))
+	for _, conv := range i.Conversions {
+		conversions = append(conversions, units.Conversion{Unit: conv.Unit, Quantity: conv.Quantity, Target: conv.TargetUnit})
+	}
+	return conversions
+}
+
+// Grams 把以食材单位计的数量换算为克，需要预加载 Conversions
+func (i *Ingredient) Grams(quantity float64) (float64, error) {
+	return units.Convert(quantity, i.Unit, "克", i.UnitConversions())
+}
+
+// IngredientUnitConversion 食材特有的单位换算：1 Unit 约等于 Quantity 个 TargetUnit，
+// 例如一块豆腐约 300 克
+type IngredientUnitConversion struct {
+	ID           uint    `json:"id" gorm:"primaryKey"`
+	IngredientID uint    `json:"ingredient_id" gorm:"not null;index"`
+	Unit         string  `json:"unit" gorm:"size:20;not null"`
+	Quantity     float64 `json:"quantity" gorm:"type:decimal(10,4);not null"`
+	TargetUnit   string  `json:"target_unit" gorm:"size:20;not null"`
+}
+
+func (IngredientUnitConversion) TableName() string {
+	return "ingredient_unit_conversions"
+}
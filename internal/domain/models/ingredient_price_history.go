@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IngredientPriceHistory 食材价格变动记录，创建食材和每次修改价格或单位时追加一条
+type IngredientPriceHistory struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	IngredientID uint      `json:"ingredient_id" gorm:"not null;index"`
+	Price        float64   `json:"price" gorm:"type:decimal(10,2);not null"`
+	Unit         string    `json:"unit" gorm:"size:20;not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
+
+func (IngredientPriceHistory) TableName() string {
+	return "ingredient_price_histories"
+}
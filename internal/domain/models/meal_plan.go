@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PlanDateLayout 计划中日期的格式，按字符串存储以避免时区换算
+const PlanDateLayout = "2006-01-02"
+
+// MealPlan 用餐计划，覆盖一段日期（通常为一周），由若干餐次组成
+type MealPlan struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	HouseholdID uint           `json:"household_id" gorm:"not null;index"`
+	UserID      uint           `json:"user_id" gorm:"not null"`
+	Name        string         `json:"name" gorm:"size:100;not null"`
+	StartDate   string         `json:"start_date" gorm:"size:10;not null;index"`
+	EndDate     string         `json:"end_date" gorm:"size:10;not null;index"`
+	Notes       string         `json:"notes" gorm:"type:text"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联关系
+	Slots []MealPlanSlot `json:"slots,omitempty" gorm:"foreignKey:MealPlanID"`
+}
+
+func (MealPlan) TableName() string {
+	return "meal_plans"
+}
+
+// Contains 日期是否在计划范围内
+func (p *MealPlan) Contains(date string) bool {
+	return date >= p.StartDate && date <= p.EndDate
+}
+
+// MealPlanSlot 计划中的一餐，MealRecordID 不为空表示已经吃过并生成了用餐记录
+type MealPlanSlot struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	MealPlanID   uint      `json:"meal_plan_id" gorm:"not null;index"`
+	Date         string    `json:"date" gorm:"size:10;not null;index"`
+	MealType     string    `json:"meal_type" gorm:"size:20;not null"`
+	MealLabel    string    `json:"meal_label,omitempty" gorm:"size:50"`
+	Servings     int       `json:"servings" gorm:"not null;default:1"`
+	Notes        string    `json:"notes" gorm:"size:255"`
+	MealRecordID *uint     `json:"meal_record_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// 关联关系
+	Dishes []MealPlanSlotDish `json:"dishes,omitempty" gorm:"foreignKey:SlotID"`
+}
+
+func (MealPlanSlot) TableName() string {
+	return "meal_plan_slots"
+}
+
+// Eaten 是否已经标记为吃过
+func (s *MealPlanSlot) Eaten() bool {
+	return s.MealRecordID != nil
+}
+
+// SameMeal 是否为同一天的同一餐
+func (s *MealPlanSlot) SameMeal(other *MealPlanSlot) bool {
+	return s.Date == other.Date && s.MealType == other.MealType && s.MealLabel == other.MealLabel
+}
+
+type MealPlanSlotDish struct {
+	ID       uint `json:"id" gorm:"primaryKey"`
+	SlotID   uint `json:"slot_id" gorm:"not null;index"`
+	DishID   uint `json:"dish_id" gorm:"not null"`
+	Quantity int  `json:"quantity" gorm:"not null;default:1"`
+
+	// 关联关系
+	Dish *Dish `json:"dish,omitempty" gorm:"foreignKey:DishID"`
+}
+
+func (MealPlanSlotDish) TableName() string {
+	return "meal_plan_slot_dishes"
+}
@@ -7,19 +7,71 @@ import (
 )
 
 type MealRecord struct {
-	ID         uint           `json:"id" gorm:"primaryKey"`
-	UserID     uint           `json:"user_id" gorm:"not null"`
-	TotalPrice float64        `json:"total_price" gorm:"type:decimal(10,2);not null"`
-	Thoughts   string         `json:"thoughts" gorm:"type:text"`
-	ImageURL   string         `json:"image_url" gorm:"size:255"`
-	CreatedAt  time.Time      `json:"created_at"`
-	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserID      uint           `json:"user_id" gorm:"not null"`
+	HouseholdID uint           `json:"household_id" gorm:"not null;default:0;index"` // 0 表示尚未迁移到个人家庭
+	TotalPrice  float64        `json:"total_price" gorm:"type:decimal(10,2);not null"`
+	Thoughts    string         `json:"thoughts" gorm:"type:text"`
+	ImageURL    string         `json:"image_url,omitempty" gorm:"size:255"` // 已废弃，启动时迁移为第一张照片
+	EatenAt     time.Time      `json:"eaten_at" gorm:"index"`               // 实际用餐时间，可以晚于用餐补记
+	MealType    string         `json:"meal_type" gorm:"size:20;index"`      // breakfast, lunch, dinner, snack, custom
+	MealLabel   string         `json:"meal_label,omitempty" gorm:"size:50"` // 自定义餐次的名称，如"夜宵"
+	CreatedAt   time.Time      `json:"created_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// 关联关系
-	User   *User            `json:"user,omitempty" gorm:"foreignKey:UserID"`
-	Dishes []MealRecordDish `json:"dishes,omitempty" gorm:"foreignKey:MealRecordID"`
+	User         *User                   `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Dishes       []MealRecordDish        `json:"dishes,omitempty" gorm:"foreignKey:MealRecordID"`
+	Comments     []MealRecordComment     `json:"comments,omitempty" gorm:"foreignKey:MealRecordID"`
+	Photos       []MealRecordPhoto       `json:"photos,omitempty" gorm:"foreignKey:MealRecordID"`
+	Participants []MealRecordParticipant `json:"participants,omitempty" gorm:"foreignKey:MealRecordID"`
 }
 
 func (MealRecord) TableName() string {
 	return "meal_records"
 }
+
+const (
+	MealTypeBreakfast = "breakfast"
+	MealTypeLunch     = "lunch"
+	MealTypeDinner    = "dinner"
+	MealTypeSnack     = "snack"
+	MealTypeCustom    = "custom"
+)
+
+// IsValidMealType 检查餐次是否合法
+func IsValidMealType(mealType string) bool {
+	switch mealType {
+	case MealTypeBreakfast, MealTypeLunch, MealTypeDinner, MealTypeSnack, MealTypeCustom:
+		return true
+	}
+	return false
+}
+
+// MealTypeAt 根据用餐时间推断餐次，用于未指定餐次的记录
+func MealTypeAt(t time.Time) string {
+	switch hour := t.Hour(); {
+	case hour >= 5 && hour < 10:
+		return MealTypeBreakfast
+	case hour >= 10 && hour < 14:
+		return MealTypeLunch
+	case hour >= 17 && hour < 21:
+		return MealTypeDinner
+	}
+	return MealTypeSnack
+}
+
+// MealRecordParticipant 用餐参与者，UserID 为家庭成员，GuestName 为不在系统中的客人，二者只填其一
+type MealRecordParticipant struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	MealRecordID uint   `json:"meal_record_id" gorm:"not null;index"`
+	UserID       *uint  `json:"user_id,omitempty" gorm:"index"`
+	GuestName    string `json:"guest_name,omitempty" gorm:"size:50;index"`
+
+	// 关联关系
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+func (MealRecordParticipant) TableName() string {
+	return "meal_record_participants"
+}
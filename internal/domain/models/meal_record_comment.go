@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MealRecordComment 家庭成员对用餐记录的评论
+type MealRecordComment struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	MealRecordID uint           `json:"meal_record_id" gorm:"not null;index"`
+	UserID       uint           `json:"user_id" gorm:"not null"`
+	Content      string         `json:"content" gorm:"type:text;not null"`
+	CreatedAt    time.Time      `json:"created_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联关系
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+func (MealRecordComment) TableName() string {
+	return "meal_record_comments"
+}
@@ -1,10 +1,15 @@
 package models
 
+import "math"
+
+// MealRecordDish 用餐记录中的一道菜。UnitPrice 是用餐时的单价快照，
+// 之后修改菜品价格不会影响历史记录
 type MealRecordDish struct {
-	ID           uint `json:"id" gorm:"primaryKey"`
-	MealRecordID uint `json:"meal_record_id" gorm:"not null"`
-	DishID       uint `json:"dish_id" gorm:"not null"`
-	Quantity     int  `json:"quantity" gorm:"default:1"`
+	ID           uint    `json:"id" gorm:"primaryKey"`
+	MealRecordID uint    `json:"meal_record_id" gorm:"not null"`
+	DishID       uint    `json:"dish_id" gorm:"not null"`
+	Quantity     int     `json:"quantity" gorm:"default:1"`
+	UnitPrice    float64 `json:"unit_price" gorm:"type:decimal(10,2);not null;default:0"`
 
 	// 关联关系
 	MealRecord *MealRecord `json:"meal_record,omitempty" gorm:"foreignKey:MealRecordID"`
@@ -14,3 +19,13 @@ type MealRecordDish struct {
 func (MealRecordDish) TableName() string {
 	return "meal_record_dishes"
 }
+
+// Subtotal 该行小计
+func (d *MealRecordDish) Subtotal() float64 {
+	return RoundPrice(d.UnitPrice * float64(d.Quantity))
+}
+
+// RoundPrice 价格保留两位小数
+func RoundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// MealRecordPhoto 用餐记录的照片，按 SortOrder 升序展示，第一张作为封面。
+// 可以关联到记录中的某道菜
+type MealRecordPhoto struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	MealRecordID     uint      `json:"meal_record_id" gorm:"not null;index"`
+	MealRecordDishID *uint     `json:"meal_record_dish_id"`
+	UserID           uint      `json:"user_id" gorm:"not null"` // 上传照片的成员
+	URL              string    `json:"url" gorm:"size:255;not null"`
+	Caption          string    `json:"caption" gorm:"size:255"`
+	SortOrder        int       `json:"sort_order" gorm:"not null;default:0"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func (MealRecordPhoto) TableName() string {
+	return "meal_record_photos"
+}
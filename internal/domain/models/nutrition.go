@@ -0,0 +1,89 @@
+package models
+
+import "math"
+
+// Nutrition 营养成分。食材上为每 100 克的含量，菜品和用餐记录上为合计
+type Nutrition struct {
+	Calories float64 `json:"calories" gorm:"type:decimal(10,2);not null;default:0"` // 能量，千卡
+	Protein  float64 `json:"protein" gorm:"type:decimal(10,2);not null;default:0"`  // 蛋白质，克
+	Fat      float64 `json:"fat" gorm:"type:decimal(10,2);not null;default:0"`      // 脂肪，克
+	Carbs    float64 `json:"carbs" gorm:"type:decimal(10,2);not null;default:0"`    // 碳水化合物，克
+	Fiber    float64 `json:"fiber" gorm:"type:decimal(10,2);not null;default:0"`    // 膳食纤维，克
+	Sodium   float64 `json:"sodium" gorm:"type:decimal(10,2);not null;default:0"`   // 钠，毫克
+}
+
+// IsZero 全部为 0 视为没有营养数据
+func (n Nutrition) IsZero() bool {
+	return n == Nutrition{}
+}
+
+// IsValid 检查每 100 克的含量是否在合理范围内
+func (n Nutrition) IsValid() bool {
+	for _, grams := range []float64{n.Protein, n.Fat, n.Carbs, n.Fiber} {
+		if grams < 0 || grams > 100 {
+			return false
+		}
+	}
+	// 纯脂肪约 900 千卡，食盐的钠约 39000 毫克
+	return n.Calories >= 0 && n.Calories <= 900 && n.Sodium >= 0 && n.Sodium <= 40000
+}
+
+// Add 返回两者之和
+func (n Nutrition) Add(other Nutrition) Nutrition {
+	return Nutrition{
+		Calories: n.Calories + other.Calories,
+		Protein:  n.Protein + other.Protein,
+		Fat:      n.Fat + other.Fat,
+		Carbs:    n.Carbs + other.Carbs,
+		Fiber:    n.Fiber + other.Fiber,
+		Sodium:   n.Sodium + other.Sodium,
+	}
+}
+
+// Scale 返回乘以 factor 后的含量
+func (n Nutrition) Scale(factor float64) Nutrition {
+	return Nutrition{
+		Calories: n.Calories * factor,
+		Protein:  n.Protein * factor,
+		Fat:      n.Fat * factor,
+		Carbs:    n.Carbs * factor,
+		Fiber:    n.Fiber * factor,
+		Sodium:   n.Sodium * factor,
+	}
+}
+
+// Round 保留一位小数
+func (n Nutrition) Round() Nutrition {
+	round := func(v float64) float64 { return math.Round(v*10) / 10 }
+	return Nutrition{
+		Calories: round(n.Calories),
+		Protein:  round(n.Protein),
+		Fat:      round(n.Fat),
+		Carbs:    round(n.Carbs),
+		Fiber:    round(n.Fiber),
+		Sodium:   round(n.Sodium),
+	}
+}
+
+// NutritionSummary 汇总的营养成分，Missing 为没有营养数据或无法换算为克、因而没有计入的食材名称
+type NutritionSummary struct {
+	Nutrition
+	Missing []string `json:"missing,omitempty"`
+}
+
+// Add 累加另一份汇总，按 factor 倍计入，缺少数据的食材去重合并
+func (s *NutritionSummary) Add(other *NutritionSummary, factor float64) {
+	s.Nutrition = s.Nutrition.Add(other.Nutrition.Scale(factor))
+	for _, name := range other.Missing {
+		s.addMissing(name)
+	}
+}
+
+func (s *NutritionSummary) addMissing(name string) {
+	for _, existing := range s.Missing {
+		if existing == name {
+			return
+		}
+	}
+	s.Missing = append(s.Missing, name)
+}
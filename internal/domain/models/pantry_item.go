@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PantryItem 家中现有的一批食材库存。日期与用餐计划一样按 2006-01-02 字符串存储，
+// ExpiryDate 为空表示不会过期
+type PantryItem struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	HouseholdID  uint           `json:"household_id" gorm:"not null;index"`
+	IngredientID uint           `json:"ingredient_id" gorm:"not null;index"`
+	Quantity     float64        `json:"quantity" gorm:"type:decimal(12,4);not null"`
+	Unit         string         `json:"unit" gorm:"size:20;not null"`
+	PurchaseDate string         `json:"purchase_date" gorm:"size:10"`
+	ExpiryDate   string         `json:"expiry_date" gorm:"size:10;index"`
+	Location     string         `json:"location" gorm:"size:50"` // 冷藏、冷冻、常温等
+	Notes        string         `json:"notes" gorm:"size:255"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联关系
+	Ingredient *Ingredient `json:"ingredient,omitempty" gorm:"foreignKey:IngredientID"`
+}
+
+func (PantryItem) TableName() string {
+	return "pantry_items"
+}
+
+// ExpiresBy 是否在指定日期（含）之前到期
+func (p *PantryItem) ExpiresBy(date string) bool {
+	return p.ExpiryDate != "" && p.ExpiryDate <= date
+}
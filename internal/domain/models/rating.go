@@ -0,0 +1,58 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// 评分星级范围
+const (
+	MinRatingStars = 1
+	MaxRatingStars = 5
+)
+
+// DishRating 用户对菜品的评分。可以关联到某次用餐的菜品行，
+// 同一用户对同一菜品（或同一菜品行）只保留一条评分
+type DishRating struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	DishID           uint      `json:"dish_id" gorm:"not null;index"`
+	UserID           uint      `json:"user_id" gorm:"not null;index"`
+	MealRecordDishID *uint     `json:"meal_record_dish_id" gorm:"index"` // 为空表示对菜品本身的评分
+	Stars            int       `json:"stars" gorm:"not null"`
+	Comment          string    `json:"comment" gorm:"size:500"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	// 关联关系
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+func (DishRating) TableName() string {
+	return "dish_ratings"
+}
+
+// DishFavorite 用户收藏的菜品
+type DishFavorite struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	DishID    uint      `json:"dish_id" gorm:"primaryKey;index"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// 关联关系
+	Dish *Dish `json:"dish,omitempty" gorm:"foreignKey:DishID"`
+}
+
+func (DishFavorite) TableName() string {
+	return "dish_favorites"
+}
+
+// DishStats 菜品的评分汇总和被吃过的次数
+type DishStats struct {
+	AverageRating float64 `json:"average_rating"`
+	RatingCount   int64   `json:"rating_count"`
+	TimesEaten    int64   `json:"times_eaten"` // 包含该菜品的用餐记录数
+}
+
+// RoundRating 平均评分保留一位小数
+func RoundRating(rating float64) float64 {
+	return math.Round(rating*10) / 10
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken 刷新令牌，只保存令牌的哈希值。
+// 同一次登录产生的令牌属于同一个 FamilyID，每次刷新都会轮换出新令牌
+type RefreshToken struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	FamilyID    string     `json:"family_id" gorm:"size:36;not null;index"`
+	HouseholdID uint       `json:"household_id" gorm:"not null;default:0"` // 刷新后沿用的当前家庭
+	TokenHash   string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt      *time.Time `json:"used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// RevokedToken 已吊销的访问令牌或令牌家族，未启用 Redis 时作为吊销存储
+type RevokedToken struct {
+	Key       string    `json:"key" gorm:"primaryKey;size:100"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
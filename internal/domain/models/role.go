@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+)
+
+// 权限标识，格式为 资源:操作[:范围]
+const (
+	PermissionAll             = "*" // 拥有全部权限，仅内置 root 角色使用
+	PermissionDishWrite       = "dish:write"
+	PermissionIngredientWrite = "ingredient:write"
+	PermissionCategoryWrite   = "category:write"
+	PermissionTagWrite        = "tag:write"
+	PermissionUserManage      = "user:manage"
+	PermissionMealReadAny     = "meal:read:any"
+	PermissionCacheRead       = "cache:read"
+)
+
+// Permissions 所有可分配的权限及说明
+var Permissions = map[string]string{
+	PermissionDishWrite:       "创建、修改、删除菜品",
+	PermissionIngredientWrite: "创建、修改、删除食材",
+	PermissionCategoryWrite:   "创建、修改、删除分类",
+	PermissionTagWrite:        "创建、修改、删除标签",
+	PermissionUserManage:      "管理角色和用户权限",
+	PermissionMealReadAny:     "查看任意家庭的用餐记录",
+	PermissionCacheRead:       "查看缓存统计",
+}
+
+// IsValidPermission 检查权限标识是否合法
+func IsValidPermission(permission string) bool {
+	if permission == PermissionAll {
+		return true
+	}
+	_, ok := Permissions[permission]
+	return ok
+}
+
+// Role 角色，即一组命名的权限集合。用户通过 User.Role 关联角色名称
+type Role struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;size:50;not null"`
+	Description string    `json:"description" gorm:"size:255"`
+	Permissions []string  `json:"permissions" gorm:"serializer:json;type:text"`
+	IsBuiltin   bool      `json:"is_builtin" gorm:"default:false;not null"` // 内置角色不能删除或改名
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Role) TableName() string {
+	return "roles"
+}
+
+// HasPermission 检查角色是否拥有指定权限
+func (r *Role) HasPermission(permission string) bool {
+	for _, p := range r.Permissions {
+		if p == PermissionAll || p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// BuiltinRoles 系统内置角色，启动时确保存在
+func BuiltinRoles() []Role {
+	return []Role{
+		{
+			Name:        RoleRoot,
+			Description: "超级管理员，拥有全部权限",
+			Permissions: []string{PermissionAll},
+			IsBuiltin:   true,
+		},
+		{
+			Name:        RoleUser,
+			Description: "普通用户",
+			Permissions: []string{},
+			IsBuiltin:   true,
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShoppingList 购物清单，由菜品所需食材汇总生成，条目可以逐项勾选
+type ShoppingList struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	HouseholdID   uint           `json:"household_id" gorm:"not null;index"`
+	UserID        uint           `json:"user_id" gorm:"not null"`
+	Name          string         `json:"name" gorm:"size:100;not null"`
+	EstimatedCost float64        `json:"estimated_cost" gorm:"type:decimal(10,2);not null;default:0"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联关系
+	Items []ShoppingListItem `json:"items,omitempty" gorm:"foreignKey:ShoppingListID"`
+}
+
+func (ShoppingList) TableName() string {
+	return "shopping_lists"
+}
+
+// ShoppingListItem 购物清单条目。名称、单位和单价是生成时的快照，
+// Required 为菜品所需总量，InStock 为已有库存，Quantity 为需要购买的数量
+type ShoppingListItem struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	ShoppingListID uint       `json:"shopping_list_id" gorm:"not null;index"`
+	IngredientID   uint       `json:"ingredient_id" gorm:"not null"`
+	Name           string     `json:"name" gorm:"size:100;not null"`
+	Unit           string     `json:"unit" gorm:"size:20;not null"`
+	Required       float64    `json:"required" gorm:"type:decimal(12,4);not null"`
+	InStock        float64    `json:"in_stock" gorm:"type:decimal(12,4);not null;default:0"`
+	Quantity       float64    `json:"quantity" gorm:"type:decimal(12,4);not null"`
+	UnitPrice      float64    `json:"unit_price" gorm:"type:decimal(10,2);not null;default:0"`
+	EstimatedCost  float64    `json:"estimated_cost" gorm:"type:decimal(10,2);not null;default:0"`
+	Checked        bool       `json:"checked" gorm:"not null;default:false"`
+	CheckedAt      *time.Time `json:"checked_at,omitempty"`
+	SortOrder      int        `json:"sort_order" gorm:"not null;default:0"`
+}
+
+func (ShoppingListItem) TableName() string {
+	return "shopping_list_items"
+}
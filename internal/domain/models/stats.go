@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+// SpendingSummary 一段时间内的花费汇总
+type SpendingSummary struct {
+	TotalSpent     float64 `json:"total_spent"`
+	MealCount      int64   `json:"meal_count"`
+	AveragePerMeal float64 `json:"average_per_meal"`
+	DishCount      int64   `json:"dish_count"` // 吃过的不同菜品数
+}
+
+// SpendingBucket 按日、周或月汇总的花费，Period 为 2006-01-02（日、周一所在日期）或 2006-01（月）
+type SpendingBucket struct {
+	Period         string  `json:"period"`
+	TotalSpent     float64 `json:"total_spent"`
+	MealCount      int64   `json:"meal_count"`
+	AveragePerMeal float64 `json:"average_per_meal"`
+}
+
+// DishFrequency 菜品被吃过的次数和花费
+type DishFrequency struct {
+	DishID     uint    `json:"dish_id"`
+	Name       string  `json:"name"`
+	TimesEaten int64   `json:"times_eaten"` // 出现在多少次用餐中
+	Quantity   int64   `json:"quantity"`    // 累计份数
+	TotalSpent float64 `json:"total_spent"`
+}
+
+// CategoryFrequency 分类下的菜品被吃过的次数，CategoryID 为空表示未分类
+type CategoryFrequency struct {
+	CategoryID *uint   `json:"category_id"`
+	Name       string  `json:"name"`
+	TimesEaten int64   `json:"times_eaten"`
+	DishCount  int64   `json:"dish_count"` // 吃过的不同菜品数
+	TotalSpent float64 `json:"total_spent"`
+}
+
+// MemberStats 家庭成员的用餐统计，每餐的花费由参与者平摊
+type MemberStats struct {
+	UserID    uint    `json:"user_id"`
+	Username  string  `json:"username"`
+	MealCount int64   `json:"meal_count"` // 参与的用餐次数
+	Spent     float64 `json:"spent"`      // 平摊的花费
+	Recorded  int64   `json:"recorded"`   // 记录的用餐次数
+}
+
+// MealDishEntry 用餐记录中的一道菜，用于按时间顺序分析
+type MealDishEntry struct {
+	MealRecordID uint
+	EatenAt      time.Time
+	DishID       uint
+}
+
+// VarietyStreak 连续用餐中没有重复菜品的最长区间
+type VarietyStreak struct {
+	MealCount int        `json:"meal_count"`
+	DishCount int        `json:"dish_count"`
+	StartAt   *time.Time `json:"start_at"`
+	EndAt     *time.Time `json:"end_at"`
+}
+
+// LongestVarietyStreak 在按用餐时间升序排列的菜品中查找最长的一段连续用餐，
+// 其间每道菜只出现一次。同一餐内的重复菜品不算重复
+func LongestVarietyStreak(entries []MealDishEntry) VarietyStreak {
+	type meal struct {
+		eatenAt time.Time
+		dishes  []uint
+	}
+	var meals []meal
+	for i, entry := range entries {
+		if i == 0 || entry.MealRecordID != entries[i-1].MealRecordID {
+			meals = append(meals, meal{eatenAt: entry.EatenAt})
+		}
+		last := &meals[len(meals)-1]
+		last.dishes = append(last.dishes, entry.DishID)
+	}
+
+	var best VarietyStreak
+	lastSeen := make(map[uint]int) // 菜品ID -> 最近一次出现的餐序号
+	start := 0
+	dishes := make(map[uint]bool)
+	for i, m := range meals {
+		for _, dishID := range m.dishes {
+			if seen, ok := lastSeen[dishID]; ok && seen >= start && seen < i {
+				start = seen + 1
+			}
+		}
+		for _, dishID := range m.dishes {
+			lastSeen[dishID] = i
+		}
+
+		if length := i - start + 1; length > best.MealCount {
+			for id := range dishes {
+				delete(dishes, id)
+			}
+			for _, window := range meals[start : i+1] {
+				for _, dishID := range window.dishes {
+					dishes[dishID] = true
+				}
+			}
+			startAt, endAt := meals[start].eatenAt, m.eatenAt
+			best = VarietyStreak{MealCount: length, DishCount: len(dishes), StartAt: &startAt, EndAt: &endAt}
+		}
+	}
+	return best
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Tag 菜品标签，如「快手菜」「小孩爱吃」「聚会」。与分类不同，一道菜可以有多个标签
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"uniqueIndex;size:50;not null"`
+	Color     string    `json:"color" gorm:"size:20"` // 前端显示的颜色，如 #ff9900
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// DishTag 菜品与标签的关联
+type DishTag struct {
+	DishID uint `json:"dish_id" gorm:"primaryKey"`
+	TagID  uint `json:"tag_id" gorm:"primaryKey;index"`
+}
+
+func (DishTag) TableName() string {
+	return "dish_tags"
+}
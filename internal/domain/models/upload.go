@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Upload 上传的图片。URL 被菜品、用餐记录照片或用户头像引用，
+// 超过保留期仍未被引用的上传会被清理
+type Upload struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"not null;index"`
+	Key          string    `json:"key" gorm:"size:255;uniqueIndex;not null"`
+	ThumbnailKey string    `json:"thumbnail_key" gorm:"size:255"`
+	URL          string    `json:"url" gorm:"size:255;index;not null"`
+	ThumbnailURL string    `json:"thumbnail_url" gorm:"size:255"`
+	ContentType  string    `json:"content_type" gorm:"size:50;not null"`
+	Size         int64     `json:"size" gorm:"not null"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
+
+func (Upload) TableName() string {
+	return "uploads"
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"foodcook/internal/domain/models"
+)
+
+// BudgetRepository 家庭和成员的月度预算。userID 为空表示家庭预算
+type BudgetRepository interface {
+	// Save 保存预算，同一家庭、成员和月份已有预算时覆盖金额、备注和全部分类限额
+	Save(ctx context.Context, budget *models.Budget) error
+	// Find 查询某个月的预算，包含分类限额
+	Find(ctx context.Context, householdID uint, userID *uint, month string) (*models.Budget, error)
+	// Delete 删除预算及其分类限额
+	Delete(ctx context.Context, id uint) error
+	// List 按月份倒序返回预算历史
+	List(ctx context.Context, householdID uint, userID *uint, offset, limit int) ([]*models.Budget, int64, error)
+}
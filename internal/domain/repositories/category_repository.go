@@ -11,5 +11,10 @@ type CategoryRepository interface {
 	GetByID(ctx context.Context, id uint) (*models.Category, error)
 	Update(ctx context.Context, category *models.Category) error
 	Delete(ctx context.Context, id uint) error
+	// DeleteWithReassign 在同一事务中把分类下的菜品和下级分类移到 targetID，然后删除分类
+	DeleteWithReassign(ctx context.Context, id, targetID uint) error
+	// List 按排序值和名称返回全部分类
 	List(ctx context.Context) ([]*models.Category, error)
+	// CountUsage 返回直接属于该分类的菜品数和下级分类数
+	CountUsage(ctx context.Context, id uint) (dishes int64, children int64, err error)
 }
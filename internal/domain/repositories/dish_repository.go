@@ -8,18 +8,49 @@ import (
 
 type DishRepository interface {
 	Create(ctx context.Context, dish *models.Dish) error
-	CreateWithIngredients(ctx context.Context, dish *models.Dish, ingredients []DishIngredientRequest) error
+	// CreateWithIngredients 在同一事务中创建菜品、食材关联和菜谱步骤，标签关联按 dish.Tags 保存
+	CreateWithIngredients(ctx context.Context, dish *models.Dish, ingredients []DishIngredientRequest, steps []DishStepRequest) error
 	GetByID(ctx context.Context, id uint) (*models.Dish, error)
 	Update(ctx context.Context, dish *models.Dish) error
-	UpdateWithIngredients(ctx context.Context, dish *models.Dish, ingredients []DishIngredientRequest) error
+	// UpdateWithIngredients 在同一事务中更新菜品，ingredients、steps 不为 nil 时分别替换食材关联和菜谱步骤，
+	// 标签关联替换为 dish.Tags
+	UpdateWithIngredients(ctx context.Context, dish *models.Dish, ingredients []DishIngredientRequest, steps []DishStepRequest) error
 	Delete(ctx context.Context, id uint) error
-	List(ctx context.Context, offset, limit int, categoryID *uint) ([]*models.Dish, int64, error)
+	List(ctx context.Context, filter DishFilter, offset, limit int) ([]*models.Dish, int64, error)
 	GetByCategory(ctx context.Context, categoryID uint) ([]*models.Dish, error)
-	Search(ctx context.Context, keyword string, offset, limit int) ([]*models.Dish, int64, error)
+	// Search 按名称和描述搜索菜品，同时应用 filter 中的筛选条件
+	Search(ctx context.Context, keyword string, filter DishFilter, offset, limit int) ([]*models.Dish, int64, error)
 	IsUsedInMealRecords(ctx context.Context, dishID uint) (bool, error)
+	// RecalculateAutoPrices 按食材当前单价重新计算使用该食材且开启自动定价的菜品价格，返回更新的菜品数
+	RecalculateAutoPrices(ctx context.Context, ingredientID uint) (int64, error)
 }
 
 type DishIngredientRequest struct {
 	IngredientID uint    `json:"ingredient_id"`
 	Quantity     float64 `json:"quantity"`
 }
+
+type DishStepRequest struct {
+	Content      string `json:"content"`
+	TimerSeconds int    `json:"timer_seconds"`
+	ImageURL     string `json:"image_url"`
+}
+
+// DishFilter 菜品列表的筛选条件，零值表示不筛选
+type DishFilter struct {
+	CategoryID         *uint
+	IncludeDescendants bool     // 同时包含 CategoryID 的下级分类中的菜品
+	ExcludeAllergens   []string // 排除任一食材含有这些过敏原的菜品
+	Diets              []string // 只返回同时符合这些饮食标签的菜品
+	MaxSpicyLevel      *int
+	TagIDs             []uint // 带有这些标签的菜品
+	MatchAllTags       bool   // 为 true 时需要带有全部标签，否则带有任一标签即可
+	Sort               string // 排序方式，为空时按创建时间倒序
+}
+
+// 菜品列表的排序方式
+const (
+	DishSortNewest     = "newest"
+	DishSortRating     = "rating"     // 平均评分从高到低
+	DishSortPopularity = "popularity" // 被吃过的次数从多到少
+)
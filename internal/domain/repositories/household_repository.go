@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+
+	"foodcook/internal/domain/models"
+)
+
+type HouseholdRepository interface {
+	// Create 创建家庭，并将 ownerID 加入为所有者
+	Create(ctx context.Context, household *models.Household) error
+	GetByID(ctx context.Context, id uint) (*models.Household, error)
+	Update(ctx context.Context, household *models.Household) error
+	Delete(ctx context.Context, id uint) error
+	// EnsurePersonal 获取用户的个人家庭，不存在时创建，并把尚未归属家庭的用餐记录迁移进去
+	EnsurePersonal(ctx context.Context, userID uint, name string) (*models.Household, error)
+
+	ListMemberships(ctx context.Context, userID uint) ([]*models.HouseholdMember, error)
+	GetMember(ctx context.Context, householdID, userID uint) (*models.HouseholdMember, error)
+	UpdateMemberRole(ctx context.Context, householdID, userID uint, role string) error
+	RemoveMember(ctx context.Context, householdID, userID uint) error
+
+	CreateInvitation(ctx context.Context, invitation *models.HouseholdInvitation) error
+	GetInvitationByCode(ctx context.Context, code string) (*models.HouseholdInvitation, error)
+	// AcceptInvitation 消耗一次邀请码并把用户加入家庭
+	AcceptInvitation(ctx context.Context, invitationID, userID uint) (*models.HouseholdMember, error)
+}
@@ -2,15 +2,26 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"foodcook/internal/domain/models"
 )
 
 type IngredientRepository interface {
+	// Create 创建食材并记录初始价格
 	Create(ctx context.Context, ingredient *models.Ingredient) error
 	GetByID(ctx context.Context, id uint) (*models.Ingredient, error)
-	Update(ctx context.Context, ingredient *models.Ingredient) error
+	// Update 更新食材，价格或单位变化时追加一条价格记录；conversions 不为 nil 时在同一事务中替换原有的换算关系
+	Update(ctx context.Context, ingredient *models.Ingredient, conversions []models.IngredientUnitConversion) error
+	// ChangeUnit 与 Update 相同，并在同一事务中把菜品用量和未删除的库存数量乘以 factor，换算为食材的新单位
+	ChangeUnit(ctx context.Context, ingredient *models.Ingredient, factor float64, conversions []models.IngredientUnitConversion) error
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, offset, limit int) ([]*models.Ingredient, int64, error)
 	IsUsedInDishes(ctx context.Context, ingredientID uint) (bool, error)
+	// IsUsedInPantry 是否有家庭的库存中还有这种食材
+	IsUsedInPantry(ctx context.Context, ingredientID uint) (bool, error)
+	// SetNutrition 在同一事务中批量更新食材每 100 克的营养成分，键为食材ID
+	SetNutrition(ctx context.Context, nutrition map[uint]models.Nutrition) error
+	// PriceHistory 按时间顺序返回食材的价格记录，from/to 为空表示不限制
+	PriceHistory(ctx context.Context, ingredientID uint, from, to *time.Time) ([]*models.IngredientPriceHistory, error)
 }
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"foodcook/internal/domain/models"
+)
+
+// ErrSlotEaten 餐次已经标记为吃过
+var ErrSlotEaten = errors.New("餐次已标记为吃过")
+
+type MealPlanRepository interface {
+	// Create 创建计划及其中的餐次和菜品
+	Create(ctx context.Context, plan *models.MealPlan) error
+	GetByID(ctx context.Context, id uint) (*models.MealPlan, error)
+	// Update 只更新计划本身，不修改餐次
+	Update(ctx context.Context, plan *models.MealPlan) error
+	Delete(ctx context.Context, id uint) error
+	// List 返回与 [from, to] 有交集的计划，from/to 为空表示不限制
+	List(ctx context.Context, householdID uint, from, to string, offset, limit int) ([]*models.MealPlan, int64, error)
+
+	// CreateSlots 批量创建餐次及其菜品
+	CreateSlots(ctx context.Context, slots []*models.MealPlanSlot) error
+	GetSlot(ctx context.Context, id uint) (*models.MealPlanSlot, error)
+	// UpdateSlot 更新餐次并替换全部菜品
+	UpdateSlot(ctx context.Context, slot *models.MealPlanSlot) error
+	DeleteSlot(ctx context.Context, id uint) error
+	// ListSlots 返回家庭所有计划中日期在 [from, to] 内的餐次
+	ListSlots(ctx context.Context, householdID uint, from, to string) ([]*models.MealPlanSlot, error)
+	// MarkSlotEaten 记录餐次对应的用餐记录，餐次已标记时返回 ErrSlotEaten
+	MarkSlotEaten(ctx context.Context, slotID, mealRecordID uint) error
+}
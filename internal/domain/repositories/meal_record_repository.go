@@ -2,15 +2,52 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"foodcook/internal/domain/models"
 )
 
 type MealRecordRepository interface {
-	Create(ctx context.Context, mealRecord *models.MealRecord, dishIDs []uint) error
+	Create(ctx context.Context, mealRecord *models.MealRecord, dishes []MealRecordDishRequest) error
+	// CreateWithPantry 创建记录并在同一事务中扣减家庭库存，返回实际扣减情况
+	CreateWithPantry(ctx context.Context, mealRecord *models.MealRecord, dishes []MealRecordDishRequest, usages []PantryUsage) ([]PantryUsage, error)
 	GetByID(ctx context.Context, id uint) (*models.MealRecord, error)
-	Update(ctx context.Context, mealRecord *models.MealRecord) error
+	// UpdateWithDishes 在同一事务中更新记录、替换菜品行和参与者，已有菜品的行保留原ID；
+	// dishes 或 participants 为 nil 时保留原有内容
+	UpdateWithDishes(ctx context.Context, mealRecord *models.MealRecord, dishes []MealRecordDishRequest, participants []models.MealRecordParticipant) error
 	Delete(ctx context.Context, id uint) error
-	List(ctx context.Context, userID uint, offset, limit int) ([]*models.MealRecord, int64, error)
+	// List 按用餐时间排序返回家庭的用餐记录
+	List(ctx context.Context, householdID uint, filter MealRecordFilter, offset, limit int) ([]*models.MealRecord, int64, error)
 	GetByUser(ctx context.Context, userID uint) ([]*models.MealRecord, error)
+
+	AddComment(ctx context.Context, comment *models.MealRecordComment) error
+	GetComment(ctx context.Context, id uint) (*models.MealRecordComment, error)
+	ListComments(ctx context.Context, mealRecordID uint) ([]*models.MealRecordComment, error)
+	DeleteComment(ctx context.Context, id uint) error
+
+	// AddPhoto 添加照片并排在最后
+	AddPhoto(ctx context.Context, photo *models.MealRecordPhoto) error
+	GetPhoto(ctx context.Context, id uint) (*models.MealRecordPhoto, error)
+	UpdatePhoto(ctx context.Context, photo *models.MealRecordPhoto) error
+	DeletePhoto(ctx context.Context, id uint) error
+	// ReorderPhotos 按 photoIDs 的顺序重排照片，photoIDs 必须包含记录的全部照片
+	ReorderPhotos(ctx context.Context, mealRecordID uint, photoIDs []uint) error
+	// MigrateImageURLs 把旧的 ImageURL 迁移为第一张照片，返回迁移的记录数
+	MigrateImageURLs(ctx context.Context) (int, error)
+}
+
+type MealRecordDishRequest struct {
+	DishID    uint    `json:"dish_id"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+}
+
+// MealRecordFilter 用餐记录列表的筛选条件，零值表示不筛选
+type MealRecordFilter struct {
+	From          *time.Time // 用餐时间不早于 From
+	To            *time.Time // 用餐时间早于 To
+	MealType      string
+	ParticipantID uint   // 参与用餐的家庭成员
+	Guest         string // 参与用餐的客人姓名
+	Ascending     bool   // 按用餐时间升序，默认倒序
 }
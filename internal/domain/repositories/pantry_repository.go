@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+
+	"foodcook/internal/domain/models"
+)
+
+type PantryRepository interface {
+	Create(ctx context.Context, item *models.PantryItem) error
+	GetByID(ctx context.Context, id uint) (*models.PantryItem, error)
+	Update(ctx context.Context, item *models.PantryItem) error
+	Delete(ctx context.Context, id uint) error
+	// List 返回家庭的库存，ingredientID 为 0 表示全部食材，location 为空表示全部位置
+	List(ctx context.Context, householdID, ingredientID uint, location string, offset, limit int) ([]*models.PantryItem, int64, error)
+	// ListExpiring 返回在 date（含）之前到期的库存，包括已经过期的
+	ListExpiring(ctx context.Context, householdID uint, date string) ([]*models.PantryItem, error)
+	// StockLevels 按食材汇总家庭的库存数量
+	StockLevels(ctx context.Context, householdID uint) (map[uint]float64, error)
+	// Consume 按先到期先用的顺序扣减库存，用完的批次会被删除。库存不足时扣到 0 为止
+	Consume(ctx context.Context, householdID uint, usages []PantryUsage) ([]PantryUsage, error)
+}
+
+// PantryUsage 一种食材的用量，Deducted 为实际从库存扣减的数量
+type PantryUsage struct {
+	IngredientID uint    `json:"ingredient_id"`
+	Required     float64 `json:"required"`
+	Deducted     float64 `json:"deducted"`
+}
+
+// Shortage 库存不足的数量
+func (u PantryUsage) Shortage() float64 {
+	return models.RoundQuantity(u.Required - u.Deducted)
+}
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+
+	"foodcook/internal/domain/models"
+)
+
+// PreferenceRepository 用户对菜品的评分和收藏
+type PreferenceRepository interface {
+	// SaveRating 保存评分，同一用户对同一菜品（或同一菜品行）已有评分时覆盖星级和评语
+	SaveRating(ctx context.Context, rating *models.DishRating) error
+	// GetRating 查询用户对菜品的评分，mealRecordDishID 为空时查询对菜品本身的评分
+	GetRating(ctx context.Context, userID, dishID uint, mealRecordDishID *uint) (*models.DishRating, error)
+	DeleteRating(ctx context.Context, id uint) error
+	// ListRatings 按更新时间倒序返回菜品的评分
+	ListRatings(ctx context.Context, dishID uint, offset, limit int) ([]*models.DishRating, int64, error)
+	// DishStats 批量统计菜品的平均评分、评分数和被吃过的次数，没有数据的菜品不出现在结果中
+	DishStats(ctx context.Context, dishIDs []uint) (map[uint]models.DishStats, error)
+
+	// AddFavorite 收藏菜品，已收藏时不做任何操作
+	AddFavorite(ctx context.Context, userID, dishID uint) error
+	RemoveFavorite(ctx context.Context, userID, dishID uint) error
+	// ListFavorites 按收藏时间倒序返回用户收藏的菜品，已删除的菜品不返回
+	ListFavorites(ctx context.Context, userID uint) ([]*models.DishFavorite, error)
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"foodcook/internal/domain/models"
+)
+
+type RoleRepository interface {
+	Create(ctx context.Context, role *models.Role) error
+	GetByID(ctx context.Context, id uint) (*models.Role, error)
+	GetByName(ctx context.Context, name string) (*models.Role, error)
+	Update(ctx context.Context, role *models.Role) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context) ([]*models.Role, error)
+	// CountUsers 统计使用该角色的用户数量
+	CountUsers(ctx context.Context, name string) (int64, error)
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"foodcook/internal/domain/models"
+)
+
+type ShoppingListRepository interface {
+	// Create 创建清单及其全部条目
+	Create(ctx context.Context, list *models.ShoppingList) error
+	GetByID(ctx context.Context, id uint) (*models.ShoppingList, error)
+	Delete(ctx context.Context, id uint) error
+	// List 返回家庭的清单，不包含条目
+	List(ctx context.Context, householdID uint, offset, limit int) ([]*models.ShoppingList, int64, error)
+	// UpdateItem 更新条目的勾选状态
+	UpdateItem(ctx context.Context, item *models.ShoppingListItem) error
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"foodcook/internal/domain/models"
+)
+
+// 花费统计的时间粒度
+const (
+	StatsPeriodDay   = "day"
+	StatsPeriodWeek  = "week" // 按周一开始的自然周
+	StatsPeriodMonth = "month"
+)
+
+// StatsFilter 统计的时间范围和餐次，零值表示不筛选
+type StatsFilter struct {
+	From     *time.Time // 用餐时间不早于 From
+	To       *time.Time // 用餐时间早于 To
+	MealType string
+	UserID   uint // 记录人，用于个人预算
+}
+
+// StatsRepository 家庭用餐和花费的统计，在数据库中聚合而不加载全部记录
+type StatsRepository interface {
+	Summary(ctx context.Context, householdID uint, filter StatsFilter) (*models.SpendingSummary, error)
+	// Spending 按时间粒度汇总花费，按时间升序，没有用餐的时段不出现在结果中
+	Spending(ctx context.Context, householdID uint, filter StatsFilter, period string) ([]models.SpendingBucket, error)
+	// TopDishes 按吃过的次数倒序返回菜品
+	TopDishes(ctx context.Context, householdID uint, filter StatsFilter, limit int) ([]models.DishFrequency, error)
+	// TopCategories 按菜品被吃过的次数倒序返回分类
+	TopCategories(ctx context.Context, householdID uint, filter StatsFilter, limit int) ([]models.CategoryFrequency, error)
+	// VarietyStreak 连续用餐中没有重复菜品的最长区间
+	VarietyStreak(ctx context.Context, householdID uint, filter StatsFilter) (models.VarietyStreak, error)
+	// Members 按成员统计参与的用餐、平摊的花费和记录的次数，按平摊花费倒序
+	Members(ctx context.Context, householdID uint, filter StatsFilter) ([]models.MemberStats, error)
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+
+	"foodcook/internal/domain/models"
+)
+
+type TagRepository interface {
+	Create(ctx context.Context, tag *models.Tag) error
+	GetByID(ctx context.Context, id uint) (*models.Tag, error)
+	// GetByIDs 按ID批量查询标签，不存在的ID被忽略
+	GetByIDs(ctx context.Context, ids []uint) ([]models.Tag, error)
+	GetByName(ctx context.Context, name string) (*models.Tag, error)
+	Update(ctx context.Context, tag *models.Tag) error
+	// Delete 删除标签及其与菜品的关联
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context) ([]*models.Tag, error)
+	// Cloud 返回全部标签及使用该标签的菜品数，按菜品数倒序
+	Cloud(ctx context.Context) ([]TagUsage, error)
+}
+
+// TagUsage 标签及其使用次数
+type TagUsage struct {
+	models.Tag
+	DishCount int64 `json:"dish_count"`
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"foodcook/internal/domain/models"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// MarkUsed 将未使用且未吊销的令牌标记为已使用，返回是否标记成功
+	MarkUsed(ctx context.Context, id uint) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	// RevokeByUser 吊销用户所有未过期的令牌，返回涉及的令牌家族
+	RevokeByUser(ctx context.Context, userID uint) ([]string, error)
+}
+
+// TokenRevocationStore 访问令牌吊销存储，AuthMiddleware 通过它检查令牌是否已被吊销
+type TokenRevocationStore interface {
+	Revoke(ctx context.Context, key string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, keys ...string) (bool, error)
+}
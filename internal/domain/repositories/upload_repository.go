@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"foodcook/internal/domain/models"
+)
+
+type UploadRepository interface {
+	Create(ctx context.Context, upload *models.Upload) error
+	Delete(ctx context.Context, id uint) error
+	// ListOrphans 返回 before 之前创建、且未被任何菜品、菜谱步骤、用餐记录照片或用户头像引用的上传
+	ListOrphans(ctx context.Context, before time.Time, limit int) ([]*models.Upload, error)
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/cache"
+)
+
+const categoryCachePrefix = "category:"
+
+// CachedCategoryRepository 为分类仓储增加读穿透缓存。
+// 菜品结果中预加载了分类，所以分类变更时同时清空菜品缓存
+type CachedCategoryRepository struct {
+	next  repositories.CategoryRepository
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+func NewCachedCategoryRepository(next repositories.CategoryRepository, c cache.Cache, ttl time.Duration) repositories.CategoryRepository {
+	return &CachedCategoryRepository{next: next, cache: c, ttl: ttl}
+}
+
+func (r *CachedCategoryRepository) invalidate(ctx context.Context) {
+	cache.Invalidate(ctx, r.cache, categoryCachePrefix, dishCachePrefix)
+}
+
+func (r *CachedCategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	if err := r.next.Create(ctx, category); err != nil {
+		return err
+	}
+	r.invalidate(ctx)
+	return nil
+}
+
+func (r *CachedCategoryRepository) GetByID(ctx context.Context, id uint) (*models.Category, error) {
+	key := fmt.Sprintf("%sid:%d", categoryCachePrefix, id)
+
+	var category models.Category
+	if cache.GetJSON(ctx, r.cache, key, &category) {
+		return &category, nil
+	}
+
+	result, err := r.next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cache.SetJSON(ctx, r.cache, key, result, r.ttl)
+	return result, nil
+}
+
+func (r *CachedCategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	err := r.next.Update(ctx, category)
+	r.invalidate(ctx)
+	return err
+}
+
+func (r *CachedCategoryRepository) Delete(ctx context.Context, id uint) error {
+	err := r.next.Delete(ctx, id)
+	r.invalidate(ctx)
+	return err
+}
+
+func (r *CachedCategoryRepository) DeleteWithReassign(ctx context.Context, id, targetID uint) error {
+	err := r.next.DeleteWithReassign(ctx, id, targetID)
+	r.invalidate(ctx)
+	return err
+}
+
+func (r *CachedCategoryRepository) CountUsage(ctx context.Context, id uint) (int64, int64, error) {
+	return r.next.CountUsage(ctx, id)
+}
+
+func (r *CachedCategoryRepository) List(ctx context.Context) ([]*models.Category, error) {
+	key := categoryCachePrefix + "list"
+
+	var categories []*models.Category
+	if cache.GetJSON(ctx, r.cache, key, &categories) {
+		return categories, nil
+	}
+
+	result, err := r.next.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cache.SetJSON(ctx, r.cache, key, result, r.ttl)
+	return result, nil
+}
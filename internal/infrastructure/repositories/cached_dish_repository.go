@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/cache"
+)
+
+const dishCachePrefix = "dish:"
+
+// CachedDishRepository 为菜品仓储增加读穿透缓存，写操作后清空菜品缓存
+type CachedDishRepository struct {
+	next  repositories.DishRepository
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+func NewCachedDishRepository(next repositories.DishRepository, c cache.Cache, ttl time.Duration) repositories.DishRepository {
+	return &CachedDishRepository{next: next, cache: c, ttl: ttl}
+}
+
+// dishPage 列表和搜索结果的缓存结构
+type dishPage struct {
+	Dishes []*models.Dish `json:"dishes"`
+	Total  int64          `json:"total"`
+}
+
+func (r *CachedDishRepository) invalidate(ctx context.Context) {
+	cache.Invalidate(ctx, r.cache, dishCachePrefix)
+}
+
+func (r *CachedDishRepository) Create(ctx context.Context, dish *models.Dish) error {
+	if err := r.next.Create(ctx, dish); err != nil {
+		return err
+	}
+	r.invalidate(ctx)
+	return nil
+}
+
+func (r *CachedDishRepository) CreateWithIngredients(ctx context.Context, dish *models.Dish, ingredients []repositories.DishIngredientRequest, steps []repositories.DishStepRequest) error {
+	if err := r.next.CreateWithIngredients(ctx, dish, ingredients, steps); err != nil {
+		return err
+	}
+	r.invalidate(ctx)
+	return nil
+}
+
+func (r *CachedDishRepository) GetByID(ctx context.Context, id uint) (*models.Dish, error) {
+	key := fmt.Sprintf("%sid:%d", dishCachePrefix, id)
+
+	var dish models.Dish
+	if cache.GetJSON(ctx, r.cache, key, &dish) {
+		return &dish, nil
+	}
+
+	result, err := r.next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cache.SetJSON(ctx, r.cache, key, result, r.ttl)
+	return result, nil
+}
+
+func (r *CachedDishRepository) Update(ctx context.Context, dish *models.Dish) error {
+	err := r.next.Update(ctx, dish)
+	r.invalidate(ctx)
+	return err
+}
+
+func (r *CachedDishRepository) UpdateWithIngredients(ctx context.Context, dish *models.Dish, ingredients []repositories.DishIngredientRequest, steps []repositories.DishStepRequest) error {
+	err := r.next.UpdateWithIngredients(ctx, dish, ingredients, steps)
+	r.invalidate(ctx)
+	return err
+}
+
+func (r *CachedDishRepository) Delete(ctx context.Context, id uint) error {
+	err := r.next.Delete(ctx, id)
+	r.invalidate(ctx)
+	return err
+}
+
+// sortedByStats 按评分或次数排序的结果随评分和用餐记录变化，不缓存
+func sortedByStats(filter repositories.DishFilter) bool {
+	return filter.Sort == repositories.DishSortRating || filter.Sort == repositories.DishSortPopularity
+}
+
+func (r *CachedDishRepository) List(ctx context.Context, filter repositories.DishFilter, offset, limit int) ([]*models.Dish, int64, error) {
+	if sortedByStats(filter) {
+		return r.next.List(ctx, filter, offset, limit)
+	}
+	key := fmt.Sprintf("%slist:%s:%d:%d", dishCachePrefix, dishFilterKey(filter), offset, limit)
+
+	var page dishPage
+	if cache.GetJSON(ctx, r.cache, key, &page) {
+		return page.Dishes, page.Total, nil
+	}
+
+	dishes, total, err := r.next.List(ctx, filter, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	cache.SetJSON(ctx, r.cache, key, dishPage{Dishes: dishes, Total: total}, r.ttl)
+	return dishes, total, nil
+}
+
+// dishFilterKey 把筛选条件编码为缓存键的一部分
+func dishFilterKey(filter repositories.DishFilter) string {
+	category := "all"
+	if filter.CategoryID != nil {
+		category = fmt.Sprintf("%d", *filter.CategoryID)
+		if filter.IncludeDescendants {
+			category += "+"
+		}
+	}
+	spicy := "all"
+	if filter.MaxSpicyLevel != nil {
+		spicy = fmt.Sprintf("%d", *filter.MaxSpicyLevel)
+	}
+	tags := make([]string, 0, len(filter.TagIDs))
+	for _, id := range filter.TagIDs {
+		tags = append(tags, fmt.Sprintf("%d", id))
+	}
+	match := "any"
+	if filter.MatchAllTags {
+		match = "all"
+	}
+	return fmt.Sprintf("%s:%s:%s:%s:%s:%s", category, strings.Join(filter.ExcludeAllergens, ","), strings.Join(filter.Diets, ","), spicy,
+		strings.Join(tags, ","), match)
+}
+
+func (r *CachedDishRepository) GetByCategory(ctx context.Context, categoryID uint) ([]*models.Dish, error) {
+	return r.next.GetByCategory(ctx, categoryID)
+}
+
+func (r *CachedDishRepository) Search(ctx context.Context, keyword string, filter repositories.DishFilter, offset, limit int) ([]*models.Dish, int64, error) {
+	if sortedByStats(filter) {
+		return r.next.Search(ctx, keyword, filter, offset, limit)
+	}
+	key := fmt.Sprintf("%ssearch:%s:%d:%d:%s", dishCachePrefix, dishFilterKey(filter), offset, limit, url.QueryEscape(keyword))
+
+	var page dishPage
+	if cache.GetJSON(ctx, r.cache, key, &page) {
+		return page.Dishes, page.Total, nil
+	}
+
+	dishes, total, err := r.next.Search(ctx, keyword, filter, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	cache.SetJSON(ctx, r.cache, key, dishPage{Dishes: dishes, Total: total}, r.ttl)
+	return dishes, total, nil
+}
+
+func (r *CachedDishRepository) IsUsedInMealRecords(ctx context.Context, dishID uint) (bool, error) {
+	return r.next.IsUsedInMealRecords(ctx, dishID)
+}
+
+func (r *CachedDishRepository) RecalculateAutoPrices(ctx context.Context, ingredientID uint) (int64, error) {
+	updated, err := r.next.RecalculateAutoPrices(ctx, ingredientID)
+	if updated > 0 {
+		r.invalidate(ctx)
+	}
+	return updated, err
+}
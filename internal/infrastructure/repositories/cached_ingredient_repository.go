@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/cache"
+)
+
+// CachedIngredientRepository 食材本身不缓存，但菜品结果中预加载了食材，
+// 所以食材变更后需要清空菜品缓存
+type CachedIngredientRepository struct {
+	repositories.IngredientRepository
+	cache cache.Cache
+}
+
+func NewCachedIngredientRepository(next repositories.IngredientRepository, c cache.Cache) repositories.IngredientRepository {
+	return &CachedIngredientRepository{IngredientRepository: next, cache: c}
+}
+
+func (r *CachedIngredientRepository) Update(ctx context.Context, ingredient *models.Ingredient, conversions []models.IngredientUnitConversion) error {
+	err := r.IngredientRepository.Update(ctx, ingredient, conversions)
+	cache.Invalidate(ctx, r.cache, dishCachePrefix)
+	return err
+}
+
+func (r *CachedIngredientRepository) ChangeUnit(ctx context.Context, ingredient *models.Ingredient, factor float64, conversions []models.IngredientUnitConversion) error {
+	err := r.IngredientRepository.ChangeUnit(ctx, ingredient, factor, conversions)
+	cache.Invalidate(ctx, r.cache, dishCachePrefix)
+	return err
+}
+
+func (r *CachedIngredientRepository) Delete(ctx context.Context, id uint) error {
+	err := r.IngredientRepository.Delete(ctx, id)
+	cache.Invalidate(ctx, r.cache, dishCachePrefix)
+	return err
+}
+
+func (r *CachedIngredientRepository) SetNutrition(ctx context.Context, nutrition map[uint]models.Nutrition) error {
+	err := r.IngredientRepository.SetNutrition(ctx, nutrition)
+	cache.Invalidate(ctx, r.cache, dishCachePrefix)
+	return err
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/cache"
+)
+
+// CachedTagRepository 标签本身不缓存，使用次数随菜品变化。
+// 菜品结果中预加载了标签，所以修改或删除标签时清空菜品缓存
+type CachedTagRepository struct {
+	repositories.TagRepository
+	cache cache.Cache
+}
+
+func NewCachedTagRepository(next repositories.TagRepository, c cache.Cache) repositories.TagRepository {
+	return &CachedTagRepository{TagRepository: next, cache: c}
+}
+
+func (r *CachedTagRepository) Update(ctx context.Context, tag *models.Tag) error {
+	err := r.TagRepository.Update(ctx, tag)
+	cache.Invalidate(ctx, r.cache, dishCachePrefix)
+	return err
+}
+
+func (r *CachedTagRepository) Delete(ctx context.Context, id uint) error {
+	err := r.TagRepository.Delete(ctx, id)
+	cache.Invalidate(ctx, r.cache, dishCachePrefix)
+	return err
+}
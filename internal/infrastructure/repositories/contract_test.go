@@ -0,0 +1,465 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+	"foodcook/internal/pkg/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// backend 同一种存储上的一组仓储，契约测试对每种存储运行同样的用例
+type backend struct {
+	user       repositories.UserRepository
+	household  repositories.HouseholdRepository
+	category   repositories.CategoryRepository
+	dish       repositories.DishRepository
+	ingredient repositories.IngredientRepository
+	mealRecord repositories.MealRecordRepository
+	pantry     repositories.PantryRepository
+}
+
+func newMemoryBackend(t *testing.T) *backend {
+	store := NewMemoryStore()
+	return &backend{
+		user:       NewMemoryUserRepository(store),
+		household:  NewMemoryHouseholdRepository(store),
+		category:   NewMemoryCategoryRepository(store),
+		dish:       NewMemoryDishRepository(store),
+		ingredient: NewMemoryIngredientRepository(store),
+		mealRecord: NewMemoryMealRecordRepository(store),
+		pantry:     NewMemoryPantryRepository(store),
+	}
+}
+
+// newSQLiteBackend 在临时文件中创建 SQLite 数据库，表结构与启动时的迁移相同
+func newSQLiteBackend(t *testing.T) *backend {
+	path := filepath.Join(t.TempDir(), "foodcook.db")
+	db, err := gorm.Open(sqlite.Open(path+"?_foreign_keys=on"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开 SQLite 数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	previous := database.DB
+	database.DB = db
+	defer func() { database.DB = previous }()
+	if err := database.InitTables(); err != nil {
+		t.Fatalf("初始化表结构失败: %v", err)
+	}
+
+	return &backend{
+		user:       NewSQLiteUserRepository(db),
+		household:  NewSQLiteHouseholdRepository(db),
+		category:   NewSQLiteCategoryRepository(db),
+		dish:       NewSQLiteDishRepository(db),
+		ingredient: NewSQLiteIngredientRepository(db),
+		mealRecord: NewSQLiteMealRecordRepository(db),
+		pantry:     NewSQLitePantryRepository(db),
+	}
+}
+
+func TestRepositoryContract(t *testing.T) {
+	backends := []struct {
+		name string
+		new  func(t *testing.T) *backend
+	}{
+		{"memory", newMemoryBackend},
+		{"sqlite", newSQLiteBackend},
+	}
+	cases := []struct {
+		name string
+		run  func(t *testing.T, b *backend)
+	}{
+		{"DishSoftDelete", testDishSoftDelete},
+		{"IngredientSoftDelete", testIngredientSoftDelete},
+		{"CategorySoftDelete", testCategorySoftDelete},
+		{"MealRecordSoftDelete", testMealRecordSoftDelete},
+		{"DishPagination", testDishPagination},
+		{"IngredientPagination", testIngredientPagination},
+		{"MealRecordPagination", testMealRecordPagination},
+		{"IsUsedInMealRecords", testIsUsedInMealRecords},
+		{"IsUsedInDishes", testIsUsedInDishes},
+		{"IsUsedInPantry", testIsUsedInPantry},
+	}
+
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			for _, c := range cases {
+				t.Run(c.name, func(t *testing.T) {
+					c.run(t, b.new(t))
+				})
+			}
+		})
+	}
+}
+
+// fixture 契约用例共用的数据
+type fixture struct {
+	ctx       context.Context
+	user      *models.User
+	household *models.Household
+}
+
+func newFixture(t *testing.T, b *backend) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	user := &models.User{Username: "tester", Email: "tester@example.com", PasswordHash: "x", Role: models.RoleUser}
+	if err := b.user.Create(ctx, user); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	household, err := b.household.EnsurePersonal(ctx, user.ID, models.PersonalHouseholdName(user.Username))
+	if err != nil {
+		t.Fatalf("创建家庭失败: %v", err)
+	}
+	return &fixture{ctx: ctx, user: user, household: household}
+}
+
+func (f *fixture) dish(t *testing.T, b *backend, name string, price float64) *models.Dish {
+	t.Helper()
+	dish := &models.Dish{Name: name, Price: price}
+	if err := b.dish.Create(f.ctx, dish); err != nil {
+		t.Fatalf("创建菜品失败: %v", err)
+	}
+	return dish
+}
+
+func (f *fixture) ingredient(t *testing.T, b *backend, name string) *models.Ingredient {
+	t.Helper()
+	ingredient := &models.Ingredient{Name: name, Price: 10, Unit: "斤"}
+	if err := b.ingredient.Create(f.ctx, ingredient); err != nil {
+		t.Fatalf("创建食材失败: %v", err)
+	}
+	return ingredient
+}
+
+func (f *fixture) mealRecord(t *testing.T, b *backend, eatenAt time.Time, dishes ...*models.Dish) *models.MealRecord {
+	t.Helper()
+	record := &models.MealRecord{UserID: f.user.ID, HouseholdID: f.household.ID, EatenAt: eatenAt, MealType: models.MealTypeDinner}
+	lines := make([]repositories.MealRecordDishRequest, 0, len(dishes))
+	for _, dish := range dishes {
+		lines = append(lines, repositories.MealRecordDishRequest{DishID: dish.ID, Quantity: 1, UnitPrice: dish.Price})
+		record.TotalPrice += dish.Price
+	}
+	if err := b.mealRecord.Create(f.ctx, record, lines); err != nil {
+		t.Fatalf("创建用餐记录失败: %v", err)
+	}
+	return record
+}
+
+func testDishSoftDelete(t *testing.T, b *backend) {
+	f := newFixture(t, b)
+	kept := f.dish(t, b, "麻婆豆腐", 28)
+	deleted := f.dish(t, b, "白切鸡", 45)
+
+	if err := b.dish.Delete(f.ctx, deleted.ID); err != nil {
+		t.Fatalf("删除菜品失败: %v", err)
+	}
+	if _, err := b.dish.GetByID(f.ctx, deleted.ID); err == nil {
+		t.Error("已删除的菜品仍能按ID查询")
+	}
+	if err := b.dish.Delete(f.ctx, deleted.ID); err == nil {
+		t.Error("重复删除菜品没有返回错误")
+	}
+
+	dishes, total, err := b.dish.List(f.ctx, repositories.DishFilter{}, 0, 10)
+	if err != nil {
+		t.Fatalf("查询菜品失败: %v", err)
+	}
+	if total != 1 || len(dishes) != 1 || dishes[0].ID != kept.ID {
+		t.Errorf("列表返回 %d 道（total %d），期望只有未删除的菜品 %d", len(dishes), total, kept.ID)
+	}
+	if _, total, err := b.dish.Search(f.ctx, "白切鸡", repositories.DishFilter{}, 0, 10); err != nil || total != 0 {
+		t.Errorf("搜索到已删除的菜品: total=%d err=%v", total, err)
+	}
+}
+
+func testIngredientSoftDelete(t *testing.T, b *backend) {
+	f := newFixture(t, b)
+	kept := f.ingredient(t, b, "豆腐")
+	deleted := f.ingredient(t, b, "猪肉")
+
+	if err := b.ingredient.Delete(f.ctx, deleted.ID); err != nil {
+		t.Fatalf("删除食材失败: %v", err)
+	}
+	if _, err := b.ingredient.GetByID(f.ctx, deleted.ID); err == nil {
+		t.Error("已删除的食材仍能按ID查询")
+	}
+	ingredients, total, err := b.ingredient.List(f.ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("查询食材失败: %v", err)
+	}
+	if total != 1 || len(ingredients) != 1 || ingredients[0].ID != kept.ID {
+		t.Errorf("列表返回 %d 种（total %d），期望只有未删除的食材 %d", len(ingredients), total, kept.ID)
+	}
+}
+
+func testCategorySoftDelete(t *testing.T, b *backend) {
+	f := newFixture(t, b)
+	kept := &models.Category{Name: "川菜"}
+	deleted := &models.Category{Name: "粤菜"}
+	for _, category := range []*models.Category{kept, deleted} {
+		if err := b.category.Create(f.ctx, category); err != nil {
+			t.Fatalf("创建分类失败: %v", err)
+		}
+	}
+
+	if err := b.category.Delete(f.ctx, deleted.ID); err != nil {
+		t.Fatalf("删除分类失败: %v", err)
+	}
+	if _, err := b.category.GetByID(f.ctx, deleted.ID); err == nil {
+		t.Error("已删除的分类仍能按ID查询")
+	}
+	categories, err := b.category.List(f.ctx)
+	if err != nil {
+		t.Fatalf("查询分类失败: %v", err)
+	}
+	if len(categories) != 1 || categories[0].ID != kept.ID {
+		t.Errorf("列表返回 %d 个分类，期望只有未删除的分类 %d", len(categories), kept.ID)
+	}
+}
+
+func testMealRecordSoftDelete(t *testing.T, b *backend) {
+	f := newFixture(t, b)
+	dish := f.dish(t, b, "麻婆豆腐", 28)
+	now := time.Now()
+	kept := f.mealRecord(t, b, now.Add(-time.Hour), dish)
+	deleted := f.mealRecord(t, b, now, dish)
+
+	if err := b.mealRecord.Delete(f.ctx, deleted.ID); err != nil {
+		t.Fatalf("删除用餐记录失败: %v", err)
+	}
+	if _, err := b.mealRecord.GetByID(f.ctx, deleted.ID); err == nil {
+		t.Error("已删除的用餐记录仍能按ID查询")
+	}
+	records, total, err := b.mealRecord.List(f.ctx, f.household.ID, repositories.MealRecordFilter{}, 0, 10)
+	if err != nil {
+		t.Fatalf("查询用餐记录失败: %v", err)
+	}
+	if total != 1 || len(records) != 1 || records[0].ID != kept.ID {
+		t.Errorf("列表返回 %d 条（total %d），期望只有未删除的记录 %d", len(records), total, kept.ID)
+	}
+	byUser, err := b.mealRecord.GetByUser(f.ctx, f.user.ID)
+	if err != nil {
+		t.Fatalf("查询用户的用餐记录失败: %v", err)
+	}
+	if len(byUser) != 1 {
+		t.Errorf("按用户查询返回 %d 条，期望 1", len(byUser))
+	}
+}
+
+// pageCases 共 5 条数据时各分页参数应返回的条数
+var pageCases = []struct {
+	offset, limit int
+	want          int
+}{
+	{offset: 0, limit: 2, want: 2},
+	{offset: 2, limit: 2, want: 2},
+	{offset: 4, limit: 2, want: 1},
+	{offset: 5, limit: 2, want: 0},
+	{offset: 10, limit: 2, want: 0},
+	{offset: 0, limit: -1, want: 5},
+}
+
+func testDishPagination(t *testing.T, b *backend) {
+	f := newFixture(t, b)
+	for i := 1; i <= 6; i++ {
+		f.dish(t, b, fmt.Sprintf("菜品%d", i), float64(i))
+	}
+	// 已删除的菜品不计入总数
+	if err := b.dish.Delete(f.ctx, 6); err != nil {
+		t.Fatalf("删除菜品失败: %v", err)
+	}
+
+	seen := make(map[uint]bool)
+	for _, pc := range pageCases {
+		dishes, total, err := b.dish.List(f.ctx, repositories.DishFilter{}, pc.offset, pc.limit)
+		if err != nil {
+			t.Fatalf("查询菜品失败: %v", err)
+		}
+		if total != 5 || len(dishes) != pc.want {
+			t.Errorf("offset=%d limit=%d: 返回 %d 道（total %d），期望 %d 道（total 5）", pc.offset, pc.limit, len(dishes), total, pc.want)
+		}
+		if pc.limit > 0 {
+			for _, dish := range dishes {
+				if seen[dish.ID] {
+					t.Errorf("菜品 %d 出现在多个分页中", dish.ID)
+				}
+				seen[dish.ID] = true
+			}
+		}
+	}
+	if len(seen) != 5 {
+		t.Errorf("各分页共返回 %d 道菜品，期望 5", len(seen))
+	}
+
+	if _, total, err := b.dish.Search(f.ctx, "菜品", repositories.DishFilter{}, 0, 2); err != nil || total != 5 {
+		t.Errorf("搜索的总数为 %d（err %v），期望 5", total, err)
+	}
+}
+
+func testIngredientPagination(t *testing.T, b *backend) {
+	f := newFixture(t, b)
+	for i := 1; i <= 5; i++ {
+		f.ingredient(t, b, fmt.Sprintf("食材%d", i))
+	}
+
+	for _, pc := range pageCases {
+		ingredients, total, err := b.ingredient.List(f.ctx, pc.offset, pc.limit)
+		if err != nil {
+			t.Fatalf("查询食材失败: %v", err)
+		}
+		if total != 5 || len(ingredients) != pc.want {
+			t.Errorf("offset=%d limit=%d: 返回 %d 种（total %d），期望 %d 种（total 5）", pc.offset, pc.limit, len(ingredients), total, pc.want)
+		}
+	}
+}
+
+func testMealRecordPagination(t *testing.T, b *backend) {
+	f := newFixture(t, b)
+	dish := f.dish(t, b, "麻婆豆腐", 28)
+	start := time.Now().Add(-10 * 24 * time.Hour)
+	for i := 0; i < 5; i++ {
+		f.mealRecord(t, b, start.Add(time.Duration(i)*24*time.Hour), dish)
+	}
+
+	for _, pc := range pageCases {
+		records, total, err := b.mealRecord.List(f.ctx, f.household.ID, repositories.MealRecordFilter{}, pc.offset, pc.limit)
+		if err != nil {
+			t.Fatalf("查询用餐记录失败: %v", err)
+		}
+		if total != 5 || len(records) != pc.want {
+			t.Errorf("offset=%d limit=%d: 返回 %d 条（total %d），期望 %d 条（total 5）", pc.offset, pc.limit, len(records), total, pc.want)
+		}
+	}
+
+	// 筛选条件同时作用于总数
+	from := start.Add(36 * time.Hour)
+	records, total, err := b.mealRecord.List(f.ctx, f.household.ID, repositories.MealRecordFilter{From: &from, Ascending: true}, 0, 2)
+	if err != nil {
+		t.Fatalf("查询用餐记录失败: %v", err)
+	}
+	if total != 3 || len(records) != 2 || !records[0].EatenAt.Before(records[1].EatenAt) {
+		t.Errorf("按时间筛选返回 %d 条（total %d），期望升序的 2 条（total 3）", len(records), total)
+	}
+
+	if _, total, err := b.mealRecord.List(f.ctx, f.household.ID+1, repositories.MealRecordFilter{}, 0, 10); err != nil || total != 0 {
+		t.Errorf("其他家庭的总数为 %d（err %v），期望 0", total, err)
+	}
+}
+
+func testIsUsedInMealRecords(t *testing.T, b *backend) {
+	f := newFixture(t, b)
+	eaten := f.dish(t, b, "麻婆豆腐", 28)
+	unused := f.dish(t, b, "白切鸡", 45)
+	record := f.mealRecord(t, b, time.Now(), eaten)
+
+	assertUsed := func(dish *models.Dish, want bool) {
+		t.Helper()
+		used, err := b.dish.IsUsedInMealRecords(f.ctx, dish.ID)
+		if err != nil {
+			t.Fatalf("检查菜品使用情况失败: %v", err)
+		}
+		if used != want {
+			t.Errorf("菜品 %s: IsUsedInMealRecords = %v，期望 %v", dish.Name, used, want)
+		}
+	}
+	assertUsed(eaten, true)
+	assertUsed(unused, false)
+
+	// 替换菜品行后，只有新的菜品算作被使用
+	err := b.mealRecord.UpdateWithDishes(f.ctx, record, []repositories.MealRecordDishRequest{{DishID: unused.ID, Quantity: 1, UnitPrice: unused.Price}}, nil)
+	if err != nil {
+		t.Fatalf("更新用餐记录失败: %v", err)
+	}
+	assertUsed(eaten, false)
+	assertUsed(unused, true)
+
+	// 已删除的用餐记录不算
+	if err := b.mealRecord.Delete(f.ctx, record.ID); err != nil {
+		t.Fatalf("删除用餐记录失败: %v", err)
+	}
+	assertUsed(unused, false)
+}
+
+func testIsUsedInDishes(t *testing.T, b *backend) {
+	f := newFixture(t, b)
+	tofu := f.ingredient(t, b, "豆腐")
+	pork := f.ingredient(t, b, "猪肉")
+	unused := f.ingredient(t, b, "鸡肉")
+
+	dish := &models.Dish{Name: "麻婆豆腐", Price: 28}
+	err := b.dish.CreateWithIngredients(f.ctx, dish, []repositories.DishIngredientRequest{
+		{IngredientID: tofu.ID, Quantity: 1},
+		{IngredientID: pork.ID, Quantity: 0.2},
+	}, nil)
+	if err != nil {
+		t.Fatalf("创建菜品失败: %v", err)
+	}
+
+	assertUsed := func(ingredient *models.Ingredient, want bool) {
+		t.Helper()
+		used, err := b.ingredient.IsUsedInDishes(f.ctx, ingredient.ID)
+		if err != nil {
+			t.Fatalf("检查食材使用情况失败: %v", err)
+		}
+		if used != want {
+			t.Errorf("食材 %s: IsUsedInDishes = %v，期望 %v", ingredient.Name, used, want)
+		}
+	}
+	assertUsed(tofu, true)
+	assertUsed(pork, true)
+	assertUsed(unused, false)
+
+	// 替换食材后，移除的食材不再算作被使用
+	err = b.dish.UpdateWithIngredients(f.ctx, dish, []repositories.DishIngredientRequest{{IngredientID: tofu.ID, Quantity: 1}}, nil)
+	if err != nil {
+		t.Fatalf("更新菜品失败: %v", err)
+	}
+	assertUsed(tofu, true)
+	assertUsed(pork, false)
+}
+
+func testIsUsedInPantry(t *testing.T, b *backend) {
+	f := newFixture(t, b)
+	stocked := f.ingredient(t, b, "豆腐")
+	unused := f.ingredient(t, b, "猪肉")
+
+	item := &models.PantryItem{HouseholdID: f.household.ID, IngredientID: stocked.ID, Quantity: 2, Unit: stocked.Unit}
+	if err := b.pantry.Create(f.ctx, item); err != nil {
+		t.Fatalf("添加库存失败: %v", err)
+	}
+
+	assertUsed := func(ingredient *models.Ingredient, want bool) {
+		t.Helper()
+		used, err := b.ingredient.IsUsedInPantry(f.ctx, ingredient.ID)
+		if err != nil {
+			t.Fatalf("检查食材库存失败: %v", err)
+		}
+		if used != want {
+			t.Errorf("食材 %s: IsUsedInPantry = %v，期望 %v", ingredient.Name, used, want)
+		}
+	}
+	assertUsed(stocked, true)
+	assertUsed(unused, false)
+
+	if err := b.pantry.Delete(f.ctx, item.ID); err != nil {
+		t.Fatalf("删除库存失败: %v", err)
+	}
+	assertUsed(stocked, false)
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+)
+
+type MemoryBudgetRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryBudgetRepository(store *MemoryStore) repositories.BudgetRepository {
+	return &MemoryBudgetRepository{store: store}
+}
+
+// budgetsOf 返回家庭或成员的预算，按月份倒序，调用方需持有读锁
+func (s *MemoryStore) budgetsOf(householdID uint, userID *uint) []*models.Budget {
+	var budgets []*models.Budget
+	for _, budget := range s.budgets {
+		if budget.HouseholdID != householdID || (budget.UserID == nil) != (userID == nil) {
+			continue
+		}
+		if userID != nil && *budget.UserID != *userID {
+			continue
+		}
+		budgets = append(budgets, budget)
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Month > budgets[j].Month })
+	return budgets
+}
+
+// loadBudget 返回预算副本并加载分类限额，调用方需持有读锁
+func (s *MemoryStore) loadBudget(budget *models.Budget) *models.Budget {
+	b := *budget
+	b.CategoryLimits = nil
+	for _, limit := range s.budgetLimits {
+		if limit.BudgetID != b.ID {
+			continue
+		}
+		l := *limit
+		l.Category = s.loadCategory(&l.CategoryID)
+		b.CategoryLimits = append(b.CategoryLimits, l)
+	}
+	sort.Slice(b.CategoryLimits, func(i, j int) bool { return b.CategoryLimits[i].ID < b.CategoryLimits[j].ID })
+	return &b
+}
+
+// deleteBudgetLimits 删除预算的全部分类限额，调用方需持有写锁
+func (s *MemoryStore) deleteBudgetLimits(budgetID uint) {
+	for id, limit := range s.budgetLimits {
+		if limit.BudgetID == budgetID {
+			delete(s.budgetLimits, id)
+		}
+	}
+}
+
+func (r *MemoryBudgetRepository) Save(ctx context.Context, budget *models.Budget) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	budget.CreatedAt = now
+	budget.ID = 0
+	for _, existing := range r.store.budgetsOf(budget.HouseholdID, budget.UserID) {
+		if existing.Month == budget.Month {
+			budget.ID = existing.ID
+			budget.CreatedAt = existing.CreatedAt
+			break
+		}
+	}
+	if budget.ID == 0 {
+		budget.ID = r.store.nextID("budgets")
+	}
+	budget.UpdatedAt = now
+
+	r.store.deleteBudgetLimits(budget.ID)
+	for i := range budget.CategoryLimits {
+		limit := &budget.CategoryLimits[i]
+		limit.ID = r.store.nextID("budget_category_limits")
+		limit.BudgetID = budget.ID
+		l := *limit
+		l.Category = nil
+		r.store.budgetLimits[l.ID] = &l
+	}
+
+	b := *budget
+	b.CategoryLimits = nil
+	r.store.budgets[b.ID] = &b
+	return nil
+}
+
+func (r *MemoryBudgetRepository) Find(ctx context.Context, householdID uint, userID *uint, month string) (*models.Budget, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, budget := range r.store.budgetsOf(householdID, userID) {
+		if budget.Month == month {
+			return r.store.loadBudget(budget), nil
+		}
+	}
+	return nil, fmt.Errorf("预算不存在")
+}
+
+func (r *MemoryBudgetRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.budgets[id]; !ok {
+		return fmt.Errorf("预算不存在")
+	}
+	r.store.deleteBudgetLimits(id)
+	delete(r.store.budgets, id)
+	return nil
+}
+
+func (r *MemoryBudgetRepository) List(ctx context.Context, householdID uint, userID *uint, offset, limit int) ([]*models.Budget, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	matched := r.store.budgetsOf(householdID, userID)
+	budgets := []*models.Budget{}
+	for _, budget := range paginate(matched, offset, limit) {
+		budgets = append(budgets, r.store.loadBudget(budget))
+	}
+	return budgets, int64(len(matched)), nil
+}
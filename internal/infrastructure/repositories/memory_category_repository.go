@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+)
+
+type MemoryCategoryRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryCategoryRepository(store *MemoryStore) repositories.CategoryRepository {
+	return &MemoryCategoryRepository{store: store}
+}
+
+func (r *MemoryCategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	category.ID = r.store.nextID("categories")
+	if category.CreatedAt.IsZero() {
+		category.CreatedAt = time.Now()
+	}
+
+	stored := *category
+	stored.Dishes = nil
+	stored.Children = nil
+	r.store.categories[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryCategoryRepository) GetByID(ctx context.Context, id uint) (*models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	category := r.store.loadCategory(&id)
+	if category == nil {
+		return nil, fmt.Errorf("分类不存在")
+	}
+	return category, nil
+}
+
+func (r *MemoryCategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.categories[category.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return fmt.Errorf("更新分类失败: 分类不存在")
+	}
+
+	stored := *category
+	stored.Dishes = nil
+	stored.Children = nil
+	r.store.categories[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryCategoryRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	category, ok := r.store.categories[id]
+	if !ok || category.DeletedAt.Valid {
+		return fmt.Errorf("分类不存在")
+	}
+	category.DeletedAt = softDeleted()
+	return nil
+}
+
+func (r *MemoryCategoryRepository) List(ctx context.Context) ([]*models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	categories := []*models.Category{}
+	for _, category := range r.store.categories {
+		if category.DeletedAt.Valid {
+			continue
+		}
+		c := *category
+		categories = append(categories, &c)
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].SortOrder != categories[j].SortOrder {
+			return categories[i].SortOrder < categories[j].SortOrder
+		}
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	return categories, nil
+}
+
+func (r *MemoryCategoryRepository) DeleteWithReassign(ctx context.Context, id, targetID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	category, ok := r.store.categories[id]
+	if !ok || category.DeletedAt.Valid {
+		return fmt.Errorf("分类不存在")
+	}
+
+	for _, dish := range r.store.dishes {
+		if dish.CategoryID != nil && *dish.CategoryID == id {
+			target := targetID
+			dish.CategoryID = &target
+		}
+	}
+	for _, child := range r.store.categories {
+		if child.ParentID != nil && *child.ParentID == id {
+			target := targetID
+			child.ParentID = &target
+		}
+	}
+	category.DeletedAt = softDeleted()
+	return nil
+}
+
+func (r *MemoryCategoryRepository) CountUsage(ctx context.Context, id uint) (int64, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var dishes, children int64
+	for _, dish := range r.store.dishes {
+		if !dish.DeletedAt.Valid && dish.CategoryID != nil && *dish.CategoryID == id {
+			dishes++
+		}
+	}
+	for _, child := range r.store.categories {
+		if !child.DeletedAt.Valid && child.ParentID != nil && *child.ParentID == id {
+			children++
+		}
+	}
+	return dishes, children, nil
+}
@@ -0,0 +1,354 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+)
+
+type MemoryDishRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryDishRepository(store *MemoryStore) repositories.DishRepository {
+	return &MemoryDishRepository{store: store}
+}
+
+// filter 返回未删除且满足条件的菜品，按创建时间倒序排列，调用方需持有读锁
+func (r *MemoryDishRepository) filter(match func(*models.Dish) bool) []*models.Dish {
+	var dishes []*models.Dish
+	for _, dish := range r.store.dishes {
+		if !dish.DeletedAt.Valid && match(dish) {
+			dishes = append(dishes, dish)
+		}
+	}
+	sortByCreatedDesc(dishes,
+		func(d *models.Dish) time.Time { return d.CreatedAt },
+		func(d *models.Dish) uint { return d.ID })
+	return dishes
+}
+
+func (r *MemoryDishRepository) List(ctx context.Context, filter repositories.DishFilter, offset, limit int) ([]*models.Dish, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	matched := r.filter(r.matcher(filter))
+	r.store.sortDishes(matched, filter.Sort)
+
+	var dishes []*models.Dish
+	for _, dish := range paginate(matched, offset, limit) {
+		dishes = append(dishes, r.store.loadDish(dish, true))
+	}
+	return dishes, int64(len(matched)), nil
+}
+
+func (r *MemoryDishRepository) GetByID(ctx context.Context, id uint) (*models.Dish, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	dish, ok := r.store.dishes[id]
+	if !ok || dish.DeletedAt.Valid {
+		return nil, fmt.Errorf("菜品不存在")
+	}
+	d := r.store.loadDish(dish, true)
+	d.Steps = r.store.sortedDishSteps(d.ID)
+	return d, nil
+}
+
+func (r *MemoryDishRepository) Create(ctx context.Context, dish *models.Dish) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.insert(dish)
+	return nil
+}
+
+// insert 写入菜品，调用方需持有写锁
+func (r *MemoryDishRepository) insert(dish *models.Dish) {
+	now := time.Now()
+	dish.ID = r.store.nextID("dishes")
+	if dish.CreatedAt.IsZero() {
+		dish.CreatedAt = now
+	}
+	dish.UpdatedAt = now
+	// 与数据库列的默认值一致
+	if dish.Servings == 0 {
+		dish.Servings = 1
+	}
+	r.save(dish)
+}
+
+// save 保存菜品副本（不含关联），调用方需持有写锁
+func (r *MemoryDishRepository) save(dish *models.Dish) {
+	stored := *dish
+	stored.Category = nil
+	stored.Ingredients = nil
+	stored.Steps = nil
+	stored.MealRecords = nil
+	stored.Tags = nil
+	r.store.dishes[stored.ID] = &stored
+}
+
+// replaceTags 把菜品的标签关联替换为 dish.Tags，调用方需持有写锁
+func (r *MemoryDishRepository) replaceTags(dish *models.Dish) {
+	tagIDs := make([]uint, 0, len(dish.Tags))
+	for _, tag := range dish.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	r.store.dishTags[dish.ID] = tagIDs
+}
+
+// checkIngredients 模拟外键约束，校验关联的食材是否存在，调用方需持有读锁
+func (r *MemoryDishRepository) checkIngredients(ingredients []repositories.DishIngredientRequest) error {
+	for _, ingredient := range ingredients {
+		if _, ok := r.store.ingredients[ingredient.IngredientID]; !ok {
+			return fmt.Errorf("创建食材关联失败: 食材 %d 不存在", ingredient.IngredientID)
+		}
+	}
+	return nil
+}
+
+// replaceIngredients 用新的食材关联替换菜品原有关联，调用方需持有写锁
+func (r *MemoryDishRepository) replaceIngredients(dishID uint, ingredients []repositories.DishIngredientRequest) {
+	for id, di := range r.store.dishIngredients {
+		if di.DishID == dishID {
+			delete(r.store.dishIngredients, id)
+		}
+	}
+
+	for _, ingredient := range ingredients {
+		id := r.store.nextID("dish_ingredients")
+		r.store.dishIngredients[id] = &models.DishIngredient{
+			ID:           id,
+			DishID:       dishID,
+			IngredientID: ingredient.IngredientID,
+			Quantity:     ingredient.Quantity,
+		}
+	}
+}
+
+// replaceSteps 用新的步骤替换菜品原有步骤并填充到 dish.Steps，调用方需持有写锁
+func (r *MemoryDishRepository) replaceSteps(dish *models.Dish, steps []repositories.DishStepRequest) {
+	for id, step := range r.store.dishSteps {
+		if step.DishID == dish.ID {
+			delete(r.store.dishSteps, id)
+		}
+	}
+
+	dish.Steps = nil
+	for i, step := range steps {
+		dishStep := models.DishStep{
+			ID:           r.store.nextID("dish_steps"),
+			DishID:       dish.ID,
+			SortOrder:    i,
+			Content:      step.Content,
+			TimerSeconds: step.TimerSeconds,
+			ImageURL:     step.ImageURL,
+		}
+		stored := dishStep
+		r.store.dishSteps[stored.ID] = &stored
+		dish.Steps = append(dish.Steps, dishStep)
+	}
+}
+
+func (r *MemoryDishRepository) CreateWithIngredients(ctx context.Context, dish *models.Dish, ingredients []repositories.DishIngredientRequest, steps []repositories.DishStepRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// 先校验食材，保证与事务一样要么全部成功要么全部失败
+	if err := r.checkIngredients(ingredients); err != nil {
+		return err
+	}
+
+	r.insert(dish)
+	r.replaceIngredients(dish.ID, ingredients)
+	r.replaceSteps(dish, steps)
+	r.replaceTags(dish)
+	return nil
+}
+
+func (r *MemoryDishRepository) Update(ctx context.Context, dish *models.Dish) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.dishes[dish.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return fmt.Errorf("菜品不存在")
+	}
+
+	dish.UpdatedAt = time.Now()
+	r.save(dish)
+	return nil
+}
+
+func (r *MemoryDishRepository) UpdateWithIngredients(ctx context.Context, dish *models.Dish, ingredients []repositories.DishIngredientRequest, steps []repositories.DishStepRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.dishes[dish.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return fmt.Errorf("更新菜品失败: 菜品不存在")
+	}
+	if err := r.checkIngredients(ingredients); err != nil {
+		return err
+	}
+
+	dish.UpdatedAt = time.Now()
+	r.save(dish)
+	if ingredients != nil {
+		r.replaceIngredients(dish.ID, ingredients)
+	}
+	if steps != nil {
+		r.replaceSteps(dish, steps)
+	}
+	r.replaceTags(dish)
+	return nil
+}
+
+func (r *MemoryDishRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	dish, ok := r.store.dishes[id]
+	if !ok || dish.DeletedAt.Valid {
+		return fmt.Errorf("菜品不存在")
+	}
+	dish.DeletedAt = softDeleted()
+	return nil
+}
+
+func (r *MemoryDishRepository) GetByCategory(ctx context.Context, categoryID uint) ([]*models.Dish, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	matched := r.filter(func(d *models.Dish) bool {
+		return d.CategoryID != nil && *d.CategoryID == categoryID
+	})
+
+	dishes := []*models.Dish{}
+	for _, dish := range matched {
+		dishes = append(dishes, r.store.loadDish(dish, false))
+	}
+	return dishes, nil
+}
+
+func (r *MemoryDishRepository) Search(ctx context.Context, keyword string, filter repositories.DishFilter, offset, limit int) ([]*models.Dish, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keyword = strings.ToLower(keyword)
+	matches := r.matcher(filter)
+	matched := r.filter(func(d *models.Dish) bool {
+		return (strings.Contains(strings.ToLower(d.Name), keyword) ||
+			strings.Contains(strings.ToLower(d.Description), keyword)) && matches(d)
+	})
+	r.store.sortDishes(matched, filter.Sort)
+
+	var dishes []*models.Dish
+	for _, dish := range paginate(matched, offset, limit) {
+		dishes = append(dishes, r.store.loadDish(dish, true))
+	}
+	return dishes, int64(len(matched)), nil
+}
+
+// matcher 返回检查菜品是否满足筛选条件的函数，调用方需持有读锁
+func (r *MemoryDishRepository) matcher(filter repositories.DishFilter) func(*models.Dish) bool {
+	var categoryIDs map[uint]bool
+	if filter.CategoryID != nil {
+		ids := []uint{*filter.CategoryID}
+		if filter.IncludeDescendants {
+			ids = models.CategoryDescendantIDs(r.store.activeCategories(), *filter.CategoryID)
+		}
+		categoryIDs = make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			categoryIDs[id] = true
+		}
+	}
+
+	return func(d *models.Dish) bool {
+		if categoryIDs != nil && (d.CategoryID == nil || !categoryIDs[*d.CategoryID]) {
+			return false
+		}
+		return r.matches(d, filter)
+	}
+}
+
+// matches 检查菜品是否满足分类以外的筛选条件，调用方需持有读锁
+func (r *MemoryDishRepository) matches(d *models.Dish, filter repositories.DishFilter) bool {
+	for _, diet := range filter.Diets {
+		if !d.HasDiet(diet) {
+			return false
+		}
+	}
+	if filter.MaxSpicyLevel != nil && d.SpicyLevel > *filter.MaxSpicyLevel {
+		return false
+	}
+	if len(filter.TagIDs) > 0 {
+		matched := 0
+		for _, tagID := range filter.TagIDs {
+			for _, dishTagID := range r.store.dishTags[d.ID] {
+				if dishTagID == tagID {
+					matched++
+					break
+				}
+			}
+		}
+		if matched == 0 || (filter.MatchAllTags && matched < len(filter.TagIDs)) {
+			return false
+		}
+	}
+	if len(filter.ExcludeAllergens) > 0 {
+		allergens := r.store.loadDish(d, true).CollectAllergens()
+		for _, allergen := range filter.ExcludeAllergens {
+			for _, contained := range allergens {
+				if contained == allergen {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func (r *MemoryDishRepository) IsUsedInMealRecords(ctx context.Context, dishID uint) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, mrd := range r.store.mealRecordDishes {
+		if mrd.DishID != dishID {
+			continue
+		}
+		if record, ok := r.store.mealRecords[mrd.MealRecordID]; ok && !record.DeletedAt.Valid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MemoryDishRepository) RecalculateAutoPrices(ctx context.Context, ingredientID uint) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	used := make(map[uint]bool)
+	for _, di := range r.store.dishIngredients {
+		if di.IngredientID == ingredientID {
+			used[di.DishID] = true
+		}
+	}
+
+	var updated int64
+	now := time.Now()
+	for _, dish := range r.store.dishes {
+		if !dish.AutoPrice || !used[dish.ID] || dish.DeletedAt.Valid {
+			continue
+		}
+		dish.Price = r.store.loadDish(dish, true).CalculateIngredientCost()
+		dish.UpdatedAt = now
+		updated++
+	}
+	return updated, nil
+}
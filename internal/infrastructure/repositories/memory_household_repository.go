@@ -0,0 +1,280 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+)
+
+type MemoryHouseholdRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryHouseholdRepository(store *MemoryStore) repositories.HouseholdRepository {
+	return &MemoryHouseholdRepository{store: store}
+}
+
+// insert 写入家庭和所有者成员，调用方需持有写锁
+func (r *MemoryHouseholdRepository) insert(household *models.Household) {
+	now := time.Now()
+	household.ID = r.store.nextID("households")
+	household.CreatedAt = now
+	household.UpdatedAt = now
+	household.Members = nil
+
+	stored := *household
+	r.store.households[stored.ID] = &stored
+
+	memberID := r.store.nextID("household_members")
+	r.store.householdMembers[memberID] = &models.HouseholdMember{
+		ID:          memberID,
+		HouseholdID: household.ID,
+		UserID:      household.OwnerID,
+		Role:        models.HouseholdRoleOwner,
+		CreatedAt:   now,
+	}
+}
+
+// loadHousehold 返回未删除家庭的副本，调用方需持有读锁
+func (r *MemoryHouseholdRepository) loadHousehold(id uint) *models.Household {
+	household, ok := r.store.households[id]
+	if !ok || household.DeletedAt.Valid {
+		return nil
+	}
+	h := *household
+	h.Members = nil
+	return &h
+}
+
+// findMember 查找成员记录，调用方需持有读锁
+func (r *MemoryHouseholdRepository) findMember(householdID, userID uint) *models.HouseholdMember {
+	for _, member := range r.store.householdMembers {
+		if member.HouseholdID == householdID && member.UserID == userID {
+			return member
+		}
+	}
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) Create(ctx context.Context, household *models.Household) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.insert(household)
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) GetByID(ctx context.Context, id uint) (*models.Household, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	household := r.loadHousehold(id)
+	if household == nil {
+		return nil, fmt.Errorf("家庭不存在")
+	}
+
+	var members []*models.HouseholdMember
+	for _, member := range r.store.householdMembers {
+		if member.HouseholdID == id {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+	for _, member := range members {
+		m := *member
+		m.User = r.store.loadUser(m.UserID)
+		household.Members = append(household.Members, m)
+	}
+	return household, nil
+}
+
+func (r *MemoryHouseholdRepository) Update(ctx context.Context, household *models.Household) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.loadHousehold(household.ID) == nil {
+		return fmt.Errorf("更新家庭失败: 家庭不存在")
+	}
+
+	household.UpdatedAt = time.Now()
+	stored := *household
+	stored.Members = nil
+	r.store.households[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	household, ok := r.store.households[id]
+	if !ok || household.DeletedAt.Valid {
+		return fmt.Errorf("家庭不存在")
+	}
+	household.DeletedAt = softDeleted()
+
+	for memberID, member := range r.store.householdMembers {
+		if member.HouseholdID == id {
+			delete(r.store.householdMembers, memberID)
+		}
+	}
+	for invitationID, invitation := range r.store.householdInvitations {
+		if invitation.HouseholdID == id {
+			delete(r.store.householdInvitations, invitationID)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) EnsurePersonal(ctx context.Context, userID uint, name string) (*models.Household, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var personal *models.Household
+	for _, household := range r.store.households {
+		if household.OwnerID == userID && household.IsPersonal && !household.DeletedAt.Valid {
+			personal = household
+			break
+		}
+	}
+	if personal == nil {
+		personal = &models.Household{Name: name, OwnerID: userID, IsPersonal: true}
+		r.insert(personal)
+	}
+
+	// 迁移尚未归属家庭的用餐记录
+	for _, record := range r.store.mealRecords {
+		if record.UserID == userID && record.HouseholdID == 0 {
+			record.HouseholdID = personal.ID
+		}
+	}
+
+	h := *personal
+	h.Members = nil
+	return &h, nil
+}
+
+func (r *MemoryHouseholdRepository) ListMemberships(ctx context.Context, userID uint) ([]*models.HouseholdMember, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	members := []*models.HouseholdMember{}
+	for _, member := range r.store.householdMembers {
+		if member.UserID != userID {
+			continue
+		}
+		m := *member
+		m.Household = r.loadHousehold(m.HouseholdID)
+		members = append(members, &m)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].CreatedAt.Equal(members[j].CreatedAt) {
+			return members[i].CreatedAt.Before(members[j].CreatedAt)
+		}
+		return members[i].ID < members[j].ID
+	})
+	return members, nil
+}
+
+func (r *MemoryHouseholdRepository) GetMember(ctx context.Context, householdID, userID uint) (*models.HouseholdMember, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	member := r.findMember(householdID, userID)
+	if member == nil {
+		return nil, fmt.Errorf("不是该家庭成员")
+	}
+	m := *member
+	m.Household = r.loadHousehold(m.HouseholdID)
+	return &m, nil
+}
+
+func (r *MemoryHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID, userID uint, role string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	member := r.findMember(householdID, userID)
+	if member == nil {
+		return fmt.Errorf("成员不存在")
+	}
+	member.Role = role
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) RemoveMember(ctx context.Context, householdID, userID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	member := r.findMember(householdID, userID)
+	if member == nil {
+		return fmt.Errorf("成员不存在")
+	}
+	delete(r.store.householdMembers, member.ID)
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) CreateInvitation(ctx context.Context, invitation *models.HouseholdInvitation) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.householdInvitations {
+		if existing.Code == invitation.Code {
+			return fmt.Errorf("创建邀请码失败: 邀请码重复")
+		}
+	}
+
+	invitation.ID = r.store.nextID("household_invitations")
+	if invitation.CreatedAt.IsZero() {
+		invitation.CreatedAt = time.Now()
+	}
+	stored := *invitation
+	stored.Household = nil
+	r.store.householdInvitations[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*models.HouseholdInvitation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, invitation := range r.store.householdInvitations {
+		if invitation.Code == code {
+			i := *invitation
+			i.Household = r.loadHousehold(i.HouseholdID)
+			return &i, nil
+		}
+	}
+	return nil, fmt.Errorf("邀请码不存在")
+}
+
+func (r *MemoryHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID, userID uint) (*models.HouseholdMember, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	invitation, ok := r.store.householdInvitations[invitationID]
+	if !ok {
+		return nil, fmt.Errorf("加入家庭失败: 邀请码不存在")
+	}
+	if invitation.UsedCount >= invitation.MaxUses {
+		return nil, fmt.Errorf("加入家庭失败: 邀请码已用完")
+	}
+	if r.findMember(invitation.HouseholdID, userID) != nil {
+		return nil, fmt.Errorf("加入家庭失败: 已是家庭成员")
+	}
+
+	invitation.UsedCount++
+	member := &models.HouseholdMember{
+		ID:          r.store.nextID("household_members"),
+		HouseholdID: invitation.HouseholdID,
+		UserID:      userID,
+		Role:        invitation.Role,
+		CreatedAt:   time.Now(),
+	}
+	stored := *member
+	r.store.householdMembers[stored.ID] = &stored
+	return member, nil
+}
@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MemoryIngredientRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryIngredientRepository(store *MemoryStore) repositories.IngredientRepository {
+	return &MemoryIngredientRepository{store: store}
+}
+
+func (r *MemoryIngredientRepository) Create(ctx context.Context, ingredient *models.Ingredient) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ingredient.ID = r.store.nextID("ingredients")
+	if ingredient.CreatedAt.IsZero() {
+		ingredient.CreatedAt = time.Now()
+	}
+
+	stored := *ingredient
+	stored.DishIngredients = nil
+	stored.Conversions = nil
+	r.store.ingredients[stored.ID] = &stored
+	r.replaceConversions(stored.ID, ingredient.Conversions)
+	r.recordPrice(&stored, ingredient.CreatedAt)
+	return nil
+}
+
+// recordPrice 追加一条价格记录，调用方需持有写锁
+func (r *MemoryIngredientRepository) recordPrice(ingredient *models.Ingredient, at time.Time) {
+	id := r.store.nextID("ingredient_price_histories")
+	r.store.priceHistory[id] = &models.IngredientPriceHistory{
+		ID:           id,
+		IngredientID: ingredient.ID,
+		Price:        ingredient.Price,
+		Unit:         ingredient.Unit,
+		CreatedAt:    at,
+	}
+}
+
+// replaceConversions 替换食材的换算关系，调用方需持有写锁
+func (r *MemoryIngredientRepository) replaceConversions(ingredientID uint, conversions []models.IngredientUnitConversion) {
+	for id, conv := range r.store.conversions {
+		if conv.IngredientID == ingredientID {
+			delete(r.store.conversions, id)
+		}
+	}
+	for i := range conversions {
+		conversions[i].ID = r.store.nextID("ingredient_unit_conversions")
+		conversions[i].IngredientID = ingredientID
+		conv := conversions[i]
+		r.store.conversions[conv.ID] = &conv
+	}
+}
+
+// loadIngredient 返回食材副本并填充换算关系，调用方需持有读锁
+func (r *MemoryIngredientRepository) loadIngredient(ingredient *models.Ingredient) *models.Ingredient {
+	i := *ingredient
+	i.Conversions = r.store.ingredientConversions(i.ID)
+	return &i
+}
+
+func (r *MemoryIngredientRepository) GetByID(ctx context.Context, id uint) (*models.Ingredient, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	ingredient, ok := r.store.ingredients[id]
+	if !ok || ingredient.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.loadIngredient(ingredient), nil
+}
+
+func (r *MemoryIngredientRepository) Update(ctx context.Context, ingredient *models.Ingredient, conversions []models.IngredientUnitConversion) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.saveIngredient(ingredient, conversions)
+}
+
+func (r *MemoryIngredientRepository) ChangeUnit(ctx context.Context, ingredient *models.Ingredient, factor float64, conversions []models.IngredientUnitConversion) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.saveIngredient(ingredient, conversions); err != nil {
+		return err
+	}
+	for _, di := range r.store.dishIngredients {
+		if di.IngredientID == ingredient.ID {
+			di.Quantity = models.RoundQuantity(di.Quantity * factor)
+		}
+	}
+	for _, item := range r.store.pantry {
+		if item.IngredientID == ingredient.ID && !item.DeletedAt.Valid {
+			item.Quantity = models.RoundQuantity(item.Quantity * factor)
+			item.Unit = ingredient.Unit
+		}
+	}
+	return nil
+}
+
+// saveIngredient 保存食材，价格或单位变化时追加一条价格记录，conversions 不为 nil 时替换换算关系，调用方需持有写锁
+func (r *MemoryIngredientRepository) saveIngredient(ingredient *models.Ingredient, conversions []models.IngredientUnitConversion) error {
+	existing, ok := r.store.ingredients[ingredient.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return gorm.ErrRecordNotFound
+	}
+
+	stored := *ingredient
+	stored.DishIngredients = nil
+	stored.Conversions = nil
+	r.store.ingredients[stored.ID] = &stored
+	if conversions != nil {
+		r.replaceConversions(stored.ID, conversions)
+	}
+	if models.RoundPrice(existing.Price) != models.RoundPrice(stored.Price) || existing.Unit != stored.Unit {
+		r.recordPrice(&stored, time.Now())
+	}
+	return nil
+}
+
+func (r *MemoryIngredientRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if ingredient, ok := r.store.ingredients[id]; ok && !ingredient.DeletedAt.Valid {
+		ingredient.DeletedAt = softDeleted()
+	}
+	return nil
+}
+
+func (r *MemoryIngredientRepository) List(ctx context.Context, offset, limit int) ([]*models.Ingredient, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var ingredients []*models.Ingredient
+	for _, ingredient := range r.store.ingredients {
+		if ingredient.DeletedAt.Valid {
+			continue
+		}
+		ingredients = append(ingredients, r.loadIngredient(ingredient))
+	}
+	sort.Slice(ingredients, func(i, j int) bool { return ingredients[i].ID < ingredients[j].ID })
+
+	return paginate(ingredients, offset, limit), int64(len(ingredients)), nil
+}
+
+func (r *MemoryIngredientRepository) IsUsedInDishes(ctx context.Context, ingredientID uint) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, di := range r.store.dishIngredients {
+		if di.IngredientID == ingredientID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MemoryIngredientRepository) IsUsedInPantry(ctx context.Context, ingredientID uint) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, item := range r.store.pantry {
+		if item.IngredientID == ingredientID && !item.DeletedAt.Valid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MemoryIngredientRepository) SetNutrition(ctx context.Context, nutrition map[uint]models.Nutrition) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, n := range nutrition {
+		if ingredient, ok := r.store.ingredients[id]; ok && !ingredient.DeletedAt.Valid {
+			ingredient.Nutrition = n
+		}
+	}
+	return nil
+}
+
+func (r *MemoryIngredientRepository) PriceHistory(ctx context.Context, ingredientID uint, from, to *time.Time) ([]*models.IngredientPriceHistory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	history := []*models.IngredientPriceHistory{}
+	for _, record := range r.store.priceHistory {
+		if record.IngredientID != ingredientID {
+			continue
+		}
+		if from != nil && record.CreatedAt.Before(*from) {
+			continue
+		}
+		if to != nil && !record.CreatedAt.Before(*to) {
+			continue
+		}
+		h := *record
+		history = append(history, &h)
+	}
+	sort.Slice(history, func(i, j int) bool {
+		if !history[i].CreatedAt.Equal(history[j].CreatedAt) {
+			return history[i].CreatedAt.Before(history[j].CreatedAt)
+		}
+		return history[i].ID < history[j].ID
+	})
+	return history, nil
+}
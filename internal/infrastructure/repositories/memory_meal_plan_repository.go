@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+)
+
+type MemoryMealPlanRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryMealPlanRepository(store *MemoryStore) repositories.MealPlanRepository {
+	return &MemoryMealPlanRepository{store: store}
+}
+
+// loadPlan 返回未删除计划的副本（不含餐次），调用方需持有读锁
+func (r *MemoryMealPlanRepository) loadPlan(id uint) *models.MealPlan {
+	plan, ok := r.store.mealPlans[id]
+	if !ok || plan.DeletedAt.Valid {
+		return nil
+	}
+	p := *plan
+	p.Slots = nil
+	return &p
+}
+
+// loadSlot 返回餐次副本及其菜品，调用方需持有读锁
+func (r *MemoryMealPlanRepository) loadSlot(slot *models.MealPlanSlot) *models.MealPlanSlot {
+	s := *slot
+	s.Dishes = nil
+
+	var dishes []*models.MealPlanSlotDish
+	for _, dish := range r.store.mealPlanSlotDishes {
+		if dish.SlotID == slot.ID {
+			dishes = append(dishes, dish)
+		}
+	}
+	sort.Slice(dishes, func(i, j int) bool { return dishes[i].ID < dishes[j].ID })
+	for _, dish := range dishes {
+		d := *dish
+		if stored, ok := r.store.dishes[d.DishID]; ok && !stored.DeletedAt.Valid {
+			dishCopy := *stored
+			dishCopy.Category = nil
+			dishCopy.Ingredients = nil
+			dishCopy.MealRecords = nil
+			d.Dish = &dishCopy
+		}
+		s.Dishes = append(s.Dishes, d)
+	}
+	return &s
+}
+
+// insertSlot 写入餐次及其菜品，调用方需持有写锁
+func (r *MemoryMealPlanRepository) insertSlot(slot *models.MealPlanSlot) error {
+	for _, dish := range slot.Dishes {
+		if _, ok := r.store.dishes[dish.DishID]; !ok {
+			return fmt.Errorf("菜品 %d 不存在", dish.DishID)
+		}
+	}
+
+	slot.ID = r.store.nextID("meal_plan_slots")
+	if slot.CreatedAt.IsZero() {
+		slot.CreatedAt = time.Now()
+	}
+	stored := *slot
+	stored.Dishes = nil
+	r.store.mealPlanSlots[stored.ID] = &stored
+	r.replaceSlotDishes(slot)
+	return nil
+}
+
+// replaceSlotDishes 替换餐次的全部菜品，调用方需持有写锁
+func (r *MemoryMealPlanRepository) replaceSlotDishes(slot *models.MealPlanSlot) {
+	for id, dish := range r.store.mealPlanSlotDishes {
+		if dish.SlotID == slot.ID {
+			delete(r.store.mealPlanSlotDishes, id)
+		}
+	}
+	for i := range slot.Dishes {
+		dish := &slot.Dishes[i]
+		dish.ID = r.store.nextID("meal_plan_slot_dishes")
+		dish.SlotID = slot.ID
+		stored := *dish
+		stored.Dish = nil
+		r.store.mealPlanSlotDishes[stored.ID] = &stored
+	}
+}
+
+func (r *MemoryMealPlanRepository) Create(ctx context.Context, plan *models.MealPlan) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, slot := range plan.Slots {
+		for _, dish := range slot.Dishes {
+			if _, ok := r.store.dishes[dish.DishID]; !ok {
+				return fmt.Errorf("创建用餐计划失败: 菜品 %d 不存在", dish.DishID)
+			}
+		}
+	}
+
+	now := time.Now()
+	plan.ID = r.store.nextID("meal_plans")
+	plan.CreatedAt = now
+	plan.UpdatedAt = now
+	stored := *plan
+	stored.Slots = nil
+	r.store.mealPlans[stored.ID] = &stored
+
+	for i := range plan.Slots {
+		plan.Slots[i].MealPlanID = plan.ID
+		if err := r.insertSlot(&plan.Slots[i]); err != nil {
+			return fmt.Errorf("创建计划餐次失败: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryMealPlanRepository) GetByID(ctx context.Context, id uint) (*models.MealPlan, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	plan := r.loadPlan(id)
+	if plan == nil {
+		return nil, fmt.Errorf("用餐计划不存在")
+	}
+	for _, slot := range r.store.mealPlanSlots {
+		if slot.MealPlanID == id {
+			plan.Slots = append(plan.Slots, *r.loadSlot(slot))
+		}
+	}
+	sort.Slice(plan.Slots, func(i, j int) bool { return planSlotLess(&plan.Slots[i], &plan.Slots[j]) })
+	return plan, nil
+}
+
+func (r *MemoryMealPlanRepository) Update(ctx context.Context, plan *models.MealPlan) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.loadPlan(plan.ID) == nil {
+		return fmt.Errorf("更新用餐计划失败: 用餐计划不存在")
+	}
+	plan.UpdatedAt = time.Now()
+	stored := *plan
+	stored.Slots = nil
+	r.store.mealPlans[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryMealPlanRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if plan, ok := r.store.mealPlans[id]; ok && !plan.DeletedAt.Valid {
+		plan.DeletedAt = softDeleted()
+	}
+	return nil
+}
+
+func (r *MemoryMealPlanRepository) List(ctx context.Context, householdID uint, from, to string, offset, limit int) ([]*models.MealPlan, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var matched []*models.MealPlan
+	for _, plan := range r.store.mealPlans {
+		if plan.DeletedAt.Valid || plan.HouseholdID != householdID {
+			continue
+		}
+		if (from != "" && plan.EndDate < from) || (to != "" && plan.StartDate > to) {
+			continue
+		}
+		matched = append(matched, plan)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].StartDate != matched[j].StartDate {
+			return matched[i].StartDate > matched[j].StartDate
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	plans := []*models.MealPlan{}
+	for _, plan := range paginate(matched, offset, limit) {
+		plans = append(plans, r.loadPlan(plan.ID))
+	}
+	return plans, int64(len(matched)), nil
+}
+
+func (r *MemoryMealPlanRepository) CreateSlots(ctx context.Context, slots []*models.MealPlanSlot) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, slot := range slots {
+		for _, dish := range slot.Dishes {
+			if _, ok := r.store.dishes[dish.DishID]; !ok {
+				return fmt.Errorf("创建计划餐次失败: 菜品 %d 不存在", dish.DishID)
+			}
+		}
+	}
+	for _, slot := range slots {
+		if err := r.insertSlot(slot); err != nil {
+			return fmt.Errorf("创建计划餐次失败: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryMealPlanRepository) GetSlot(ctx context.Context, id uint) (*models.MealPlanSlot, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	slot, ok := r.store.mealPlanSlots[id]
+	if !ok {
+		return nil, fmt.Errorf("计划餐次不存在")
+	}
+	return r.loadSlot(slot), nil
+}
+
+func (r *MemoryMealPlanRepository) UpdateSlot(ctx context.Context, slot *models.MealPlanSlot) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.mealPlanSlots[slot.ID]; !ok {
+		return fmt.Errorf("更新计划餐次失败: 计划餐次不存在")
+	}
+	for _, dish := range slot.Dishes {
+		if _, ok := r.store.dishes[dish.DishID]; !ok {
+			return fmt.Errorf("更新计划餐次失败: 菜品 %d 不存在", dish.DishID)
+		}
+	}
+
+	stored := *slot
+	stored.Dishes = nil
+	r.store.mealPlanSlots[stored.ID] = &stored
+	r.replaceSlotDishes(slot)
+	return nil
+}
+
+func (r *MemoryMealPlanRepository) DeleteSlot(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for dishID, dish := range r.store.mealPlanSlotDishes {
+		if dish.SlotID == id {
+			delete(r.store.mealPlanSlotDishes, dishID)
+		}
+	}
+	delete(r.store.mealPlanSlots, id)
+	return nil
+}
+
+func (r *MemoryMealPlanRepository) ListSlots(ctx context.Context, householdID uint, from, to string) ([]*models.MealPlanSlot, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	slots := []*models.MealPlanSlot{}
+	for _, slot := range r.store.mealPlanSlots {
+		plan := r.loadPlan(slot.MealPlanID)
+		if plan == nil || plan.HouseholdID != householdID || slot.Date < from || slot.Date > to {
+			continue
+		}
+		slots = append(slots, r.loadSlot(slot))
+	}
+	sort.Slice(slots, func(i, j int) bool { return planSlotLess(slots[i], slots[j]) })
+	return slots, nil
+}
+
+func (r *MemoryMealPlanRepository) MarkSlotEaten(ctx context.Context, slotID, mealRecordID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	slot, ok := r.store.mealPlanSlots[slotID]
+	if !ok {
+		return fmt.Errorf("计划餐次不存在")
+	}
+	if slot.MealRecordID != nil {
+		return repositories.ErrSlotEaten
+	}
+	slot.MealRecordID = &mealRecordID
+	return nil
+}
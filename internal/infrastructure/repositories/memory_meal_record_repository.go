@@ -0,0 +1,446 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MemoryMealRecordRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryMealRecordRepository(store *MemoryStore) repositories.MealRecordRepository {
+	return &MemoryMealRecordRepository{store: store}
+}
+
+func (r *MemoryMealRecordRepository) Create(ctx context.Context, mealRecord *models.MealRecord, dishes []repositories.MealRecordDishRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.insert(mealRecord, dishes)
+}
+
+func (r *MemoryMealRecordRepository) CreateWithPantry(ctx context.Context, mealRecord *models.MealRecord, dishes []repositories.MealRecordDishRequest, usages []repositories.PantryUsage) ([]repositories.PantryUsage, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// 持有同一把写锁，记录和库存扣减对其他请求来说是原子的
+	if err := r.insert(mealRecord, dishes); err != nil {
+		return nil, err
+	}
+	return r.store.consumePantry(mealRecord.HouseholdID, usages), nil
+}
+
+// insert 写入用餐记录及其关联，调用方需持有写锁
+func (r *MemoryMealRecordRepository) insert(mealRecord *models.MealRecord, dishes []repositories.MealRecordDishRequest) error {
+	if err := r.checkDishes(dishes); err != nil {
+		return err
+	}
+
+	mealRecord.ID = r.store.nextID("meal_records")
+	if mealRecord.CreatedAt.IsZero() {
+		mealRecord.CreatedAt = time.Now()
+	}
+	r.save(mealRecord)
+
+	// 与 GORM 一致，创建时一并保存照片
+	for i := range mealRecord.Photos {
+		photo := &mealRecord.Photos[i]
+		photo.ID = r.store.nextID("meal_record_photos")
+		photo.MealRecordID = mealRecord.ID
+		if photo.CreatedAt.IsZero() {
+			photo.CreatedAt = mealRecord.CreatedAt
+		}
+		stored := *photo
+		r.store.mealRecordPhotos[stored.ID] = &stored
+	}
+
+	// 与 GORM 一致，创建时一并保存参与者
+	r.setParticipants(mealRecord.ID, mealRecord.Participants)
+
+	// 创建菜品关联
+	for _, item := range dishes {
+		r.addDish(mealRecord.ID, item)
+	}
+	return nil
+}
+
+// setParticipants 替换记录的参与者，调用方需持有写锁
+func (r *MemoryMealRecordRepository) setParticipants(mealRecordID uint, participants []models.MealRecordParticipant) {
+	for id, participant := range r.store.mealRecordParticipants {
+		if participant.MealRecordID == mealRecordID {
+			delete(r.store.mealRecordParticipants, id)
+		}
+	}
+	for i := range participants {
+		participant := &participants[i]
+		participant.ID = r.store.nextID("meal_record_participants")
+		participant.MealRecordID = mealRecordID
+		stored := *participant
+		stored.User = nil
+		r.store.mealRecordParticipants[stored.ID] = &stored
+	}
+}
+
+// checkDishes 模拟外键约束，调用方需持有锁
+func (r *MemoryMealRecordRepository) checkDishes(dishes []repositories.MealRecordDishRequest) error {
+	for _, item := range dishes {
+		if _, ok := r.store.dishes[item.DishID]; !ok {
+			return fmt.Errorf("菜品 %d 不存在", item.DishID)
+		}
+	}
+	return nil
+}
+
+// addDish 添加菜品行，调用方需持有写锁
+func (r *MemoryMealRecordRepository) addDish(mealRecordID uint, item repositories.MealRecordDishRequest) {
+	id := r.store.nextID("meal_record_dishes")
+	r.store.mealRecordDishes[id] = &models.MealRecordDish{
+		ID:           id,
+		MealRecordID: mealRecordID,
+		DishID:       item.DishID,
+		Quantity:     item.Quantity,
+		UnitPrice:    item.UnitPrice,
+	}
+}
+
+// save 保存用餐记录副本（不含关联），调用方需持有写锁
+func (r *MemoryMealRecordRepository) save(mealRecord *models.MealRecord) {
+	stored := *mealRecord
+	stored.User = nil
+	stored.Dishes = nil
+	stored.Comments = nil
+	stored.Photos = nil
+	stored.Participants = nil
+	r.store.mealRecords[stored.ID] = &stored
+}
+
+func (r *MemoryMealRecordRepository) GetByID(ctx context.Context, id uint) (*models.MealRecord, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	record, ok := r.store.mealRecords[id]
+	if !ok || record.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.store.loadMealRecord(record), nil
+}
+
+func (r *MemoryMealRecordRepository) UpdateWithDishes(ctx context.Context, mealRecord *models.MealRecord, dishes []repositories.MealRecordDishRequest, participants []models.MealRecordParticipant) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.mealRecords[mealRecord.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return gorm.ErrRecordNotFound
+	}
+	if err := r.checkDishes(dishes); err != nil {
+		return err
+	}
+	r.save(mealRecord)
+
+	if dishes != nil {
+		r.replaceDishes(mealRecord.ID, dishes)
+	}
+	if participants != nil {
+		r.setParticipants(mealRecord.ID, participants)
+	}
+	return nil
+}
+
+// replaceDishes 替换记录的菜品行，已有菜品的行保留原ID，调用方需持有写锁
+func (r *MemoryMealRecordRepository) replaceDishes(mealRecordID uint, dishes []repositories.MealRecordDishRequest) {
+	byDish := make(map[uint]*models.MealRecordDish)
+	for _, item := range r.store.sortedMealRecordDishes(mealRecordID) {
+		byDish[item.DishID] = item
+	}
+
+	for _, item := range dishes {
+		if current, ok := byDish[item.DishID]; ok {
+			delete(byDish, item.DishID)
+			current.Quantity = item.Quantity
+			current.UnitPrice = item.UnitPrice
+			continue
+		}
+		r.addDish(mealRecordID, item)
+	}
+
+	// 删除不再包含的菜品行，关联到这些行的照片解除关联，对这些行的评分一并删除
+	for _, removed := range byDish {
+		for _, photo := range r.store.mealRecordPhotos {
+			if photo.MealRecordDishID != nil && *photo.MealRecordDishID == removed.ID {
+				photo.MealRecordDishID = nil
+			}
+		}
+		r.deleteLineRatings(removed.ID)
+		delete(r.store.mealRecordDishes, removed.ID)
+	}
+}
+
+// deleteLineRatings 删除对菜品行的评分，调用方需持有写锁
+func (r *MemoryMealRecordRepository) deleteLineRatings(mealRecordDishID uint) {
+	for id, rating := range r.store.dishRatings {
+		if rating.MealRecordDishID != nil && *rating.MealRecordDishID == mealRecordDishID {
+			delete(r.store.dishRatings, id)
+		}
+	}
+}
+
+func (r *MemoryMealRecordRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if record, ok := r.store.mealRecords[id]; ok && !record.DeletedAt.Valid {
+		record.DeletedAt = softDeleted()
+		// 记录删除后无法再按用餐记录删除评分，对其菜品行的评分一并删除
+		for _, line := range r.store.sortedMealRecordDishes(id) {
+			r.deleteLineRatings(line.ID)
+		}
+	}
+	return nil
+}
+
+// filter 返回满足条件且未删除的用餐记录，按用餐时间倒序排列，调用方需持有读锁
+func (r *MemoryMealRecordRepository) filter(match func(*models.MealRecord) bool) []*models.MealRecord {
+	var records []*models.MealRecord
+	for _, record := range r.store.mealRecords {
+		if !record.DeletedAt.Valid && match(record) {
+			records = append(records, record)
+		}
+	}
+	sortByCreatedDesc(records,
+		func(m *models.MealRecord) time.Time { return m.EatenAt },
+		func(m *models.MealRecord) uint { return m.ID })
+	return records
+}
+
+// hasParticipant 记录是否包含满足条件的参与者，调用方需持有读锁
+func (r *MemoryMealRecordRepository) hasParticipant(mealRecordID uint, match func(*models.MealRecordParticipant) bool) bool {
+	for _, participant := range r.store.mealRecordParticipants {
+		if participant.MealRecordID == mealRecordID && match(participant) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryMealRecordRepository) List(ctx context.Context, householdID uint, filter repositories.MealRecordFilter, offset, limit int) ([]*models.MealRecord, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	matched := r.filter(func(m *models.MealRecord) bool {
+		switch {
+		case m.HouseholdID != householdID:
+			return false
+		case filter.From != nil && m.EatenAt.Before(*filter.From):
+			return false
+		case filter.To != nil && !m.EatenAt.Before(*filter.To):
+			return false
+		case filter.MealType != "" && m.MealType != filter.MealType:
+			return false
+		}
+		if filter.ParticipantID != 0 && !r.hasParticipant(m.ID, func(p *models.MealRecordParticipant) bool {
+			return p.UserID != nil && *p.UserID == filter.ParticipantID
+		}) {
+			return false
+		}
+		if filter.Guest != "" && !r.hasParticipant(m.ID, func(p *models.MealRecordParticipant) bool {
+			return p.GuestName == filter.Guest
+		}) {
+			return false
+		}
+		return true
+	})
+	if filter.Ascending {
+		for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+			matched[i], matched[j] = matched[j], matched[i]
+		}
+	}
+
+	records := []*models.MealRecord{}
+	for _, record := range paginate(matched, offset, limit) {
+		records = append(records, r.store.loadMealRecord(record))
+	}
+	return records, int64(len(matched)), nil
+}
+
+func (r *MemoryMealRecordRepository) GetByUser(ctx context.Context, userID uint) ([]*models.MealRecord, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var records []*models.MealRecord
+	for _, record := range r.filter(func(m *models.MealRecord) bool { return m.UserID == userID }) {
+		records = append(records, r.store.loadMealRecord(record))
+	}
+	return records, nil
+}
+
+func (r *MemoryMealRecordRepository) AddComment(ctx context.Context, comment *models.MealRecordComment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.mealRecords[comment.MealRecordID]; !ok {
+		return fmt.Errorf("用餐记录 %d 不存在", comment.MealRecordID)
+	}
+
+	comment.ID = r.store.nextID("meal_record_comments")
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now()
+	}
+	stored := *comment
+	stored.User = nil
+	r.store.mealRecordComments[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryMealRecordRepository) GetComment(ctx context.Context, id uint) (*models.MealRecordComment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comment, ok := r.store.mealRecordComments[id]
+	if !ok || comment.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	c := *comment
+	c.User = r.store.loadUser(c.UserID)
+	return &c, nil
+}
+
+func (r *MemoryMealRecordRepository) ListComments(ctx context.Context, mealRecordID uint) ([]*models.MealRecordComment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comments := []*models.MealRecordComment{}
+	for _, comment := range r.store.mealRecordComments {
+		if comment.MealRecordID != mealRecordID || comment.DeletedAt.Valid {
+			continue
+		}
+		c := *comment
+		c.User = r.store.loadUser(c.UserID)
+		comments = append(comments, &c)
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+	return comments, nil
+}
+
+func (r *MemoryMealRecordRepository) DeleteComment(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if comment, ok := r.store.mealRecordComments[id]; ok && !comment.DeletedAt.Valid {
+		comment.DeletedAt = softDeleted()
+	}
+	return nil
+}
+
+func (r *MemoryMealRecordRepository) AddPhoto(ctx context.Context, photo *models.MealRecordPhoto) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.mealRecords[photo.MealRecordID]; !ok {
+		return fmt.Errorf("用餐记录 %d 不存在", photo.MealRecordID)
+	}
+
+	photo.SortOrder = 0
+	if photos := r.store.sortedMealRecordPhotos(photo.MealRecordID); len(photos) > 0 {
+		photo.SortOrder = photos[len(photos)-1].SortOrder + 1
+	}
+	photo.ID = r.store.nextID("meal_record_photos")
+	if photo.CreatedAt.IsZero() {
+		photo.CreatedAt = time.Now()
+	}
+	stored := *photo
+	r.store.mealRecordPhotos[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryMealRecordRepository) GetPhoto(ctx context.Context, id uint) (*models.MealRecordPhoto, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	photo, ok := r.store.mealRecordPhotos[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	p := *photo
+	return &p, nil
+}
+
+func (r *MemoryMealRecordRepository) UpdatePhoto(ctx context.Context, photo *models.MealRecordPhoto) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.mealRecordPhotos[photo.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stored := *photo
+	r.store.mealRecordPhotos[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryMealRecordRepository) DeletePhoto(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.mealRecordPhotos, id)
+	return nil
+}
+
+func (r *MemoryMealRecordRepository) ReorderPhotos(ctx context.Context, mealRecordID uint, photoIDs []uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var existing []uint
+	for _, photo := range r.store.sortedMealRecordPhotos(mealRecordID) {
+		existing = append(existing, photo.ID)
+	}
+	if err := checkPhotoOrder(existing, photoIDs); err != nil {
+		return err
+	}
+
+	for i, photoID := range photoIDs {
+		r.store.mealRecordPhotos[photoID].SortOrder = i
+	}
+	return nil
+}
+
+func (r *MemoryMealRecordRepository) MigrateImageURLs(ctx context.Context) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	migrated := 0
+	for _, record := range r.store.mealRecords {
+		if record.ImageURL == "" {
+			continue
+		}
+
+		sortOrder := 0
+		if photos := r.store.sortedMealRecordPhotos(record.ID); len(photos) > 0 {
+			sortOrder = photos[0].SortOrder - 1
+		}
+		id := r.store.nextID("meal_record_photos")
+		r.store.mealRecordPhotos[id] = &models.MealRecordPhoto{
+			ID:           id,
+			MealRecordID: record.ID,
+			UserID:       record.UserID,
+			URL:          record.ImageURL,
+			SortOrder:    sortOrder,
+			CreatedAt:    record.CreatedAt,
+		}
+		record.ImageURL = ""
+		migrated++
+	}
+	return migrated, nil
+}
@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+)
+
+type MemoryPantryRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryPantryRepository(store *MemoryStore) repositories.PantryRepository {
+	return &MemoryPantryRepository{store: store}
+}
+
+// sortPantryItems 先到期的在前，没有保质期的排在最后
+func sortPantryItems(items []*models.PantryItem) {
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if (a.ExpiryDate == "") != (b.ExpiryDate == "") {
+			return b.ExpiryDate == ""
+		}
+		if a.ExpiryDate != b.ExpiryDate {
+			return a.ExpiryDate < b.ExpiryDate
+		}
+		if a.PurchaseDate != b.PurchaseDate {
+			return a.PurchaseDate < b.PurchaseDate
+		}
+		return a.ID < b.ID
+	})
+}
+
+// pantryItems 返回家庭中满足条件的未删除库存，按消耗顺序排列，调用方需持有读锁
+func (s *MemoryStore) pantryItems(householdID uint, match func(*models.PantryItem) bool) []*models.PantryItem {
+	var items []*models.PantryItem
+	for _, item := range s.pantry {
+		if !item.DeletedAt.Valid && item.HouseholdID == householdID && match(item) {
+			items = append(items, item)
+		}
+	}
+	sortPantryItems(items)
+	return items
+}
+
+// loadPantryItem 返回库存副本并加载食材，调用方需持有读锁
+func (s *MemoryStore) loadPantryItem(item *models.PantryItem) *models.PantryItem {
+	p := *item
+	p.Ingredient = nil
+	if ingredient, ok := s.ingredients[p.IngredientID]; ok && !ingredient.DeletedAt.Valid {
+		ing := *ingredient
+		ing.DishIngredients = nil
+		p.Ingredient = &ing
+	}
+	return &p
+}
+
+// consumePantry 按先到期先用的顺序扣减库存，调用方需持有写锁
+func (s *MemoryStore) consumePantry(householdID uint, usages []repositories.PantryUsage) []repositories.PantryUsage {
+	result := make([]repositories.PantryUsage, 0, len(usages))
+	for _, usage := range usages {
+		remaining := usage.Required
+		items := s.pantryItems(householdID, func(p *models.PantryItem) bool { return p.IngredientID == usage.IngredientID })
+		for _, item := range items {
+			if remaining <= 0 {
+				break
+			}
+			if item.Quantity <= remaining {
+				remaining = models.RoundQuantity(remaining - item.Quantity)
+				item.DeletedAt = softDeleted()
+				continue
+			}
+			item.Quantity = models.RoundQuantity(item.Quantity - remaining)
+			item.UpdatedAt = time.Now()
+			remaining = 0
+		}
+		usage.Deducted = models.RoundQuantity(usage.Required - remaining)
+		result = append(result, usage)
+	}
+	return result
+}
+
+func (r *MemoryPantryRepository) Create(ctx context.Context, item *models.PantryItem) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.ingredients[item.IngredientID]; !ok {
+		return fmt.Errorf("添加库存失败: 食材 %d 不存在", item.IngredientID)
+	}
+
+	now := time.Now()
+	item.ID = r.store.nextID("pantry_items")
+	item.CreatedAt = now
+	item.UpdatedAt = now
+	stored := *item
+	stored.Ingredient = nil
+	r.store.pantry[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryPantryRepository) GetByID(ctx context.Context, id uint) (*models.PantryItem, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	item, ok := r.store.pantry[id]
+	if !ok || item.DeletedAt.Valid {
+		return nil, fmt.Errorf("库存不存在")
+	}
+	return r.store.loadPantryItem(item), nil
+}
+
+func (r *MemoryPantryRepository) Update(ctx context.Context, item *models.PantryItem) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.pantry[item.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return fmt.Errorf("更新库存失败: 库存不存在")
+	}
+	item.UpdatedAt = time.Now()
+	stored := *item
+	stored.Ingredient = nil
+	r.store.pantry[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryPantryRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if item, ok := r.store.pantry[id]; ok && !item.DeletedAt.Valid {
+		item.DeletedAt = softDeleted()
+	}
+	return nil
+}
+
+func (r *MemoryPantryRepository) List(ctx context.Context, householdID, ingredientID uint, location string, offset, limit int) ([]*models.PantryItem, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	matched := r.store.pantryItems(householdID, func(p *models.PantryItem) bool {
+		return (ingredientID == 0 || p.IngredientID == ingredientID) && (location == "" || p.Location == location)
+	})
+
+	items := []*models.PantryItem{}
+	for _, item := range paginate(matched, offset, limit) {
+		items = append(items, r.store.loadPantryItem(item))
+	}
+	return items, int64(len(matched)), nil
+}
+
+func (r *MemoryPantryRepository) ListExpiring(ctx context.Context, householdID uint, date string) ([]*models.PantryItem, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	items := []*models.PantryItem{}
+	for _, item := range r.store.pantryItems(householdID, func(p *models.PantryItem) bool { return p.ExpiresBy(date) }) {
+		items = append(items, r.store.loadPantryItem(item))
+	}
+	return items, nil
+}
+
+func (r *MemoryPantryRepository) StockLevels(ctx context.Context, householdID uint) (map[uint]float64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	levels := make(map[uint]float64)
+	for _, item := range r.store.pantryItems(householdID, func(*models.PantryItem) bool { return true }) {
+		levels[item.IngredientID] = models.RoundQuantity(levels[item.IngredientID] + item.Quantity)
+	}
+	return levels, nil
+}
+
+func (r *MemoryPantryRepository) Consume(ctx context.Context, householdID uint, usages []repositories.PantryUsage) ([]repositories.PantryUsage, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.consumePantry(householdID, usages), nil
+}
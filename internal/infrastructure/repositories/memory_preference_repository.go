@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+)
+
+type MemoryPreferenceRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryPreferenceRepository(store *MemoryStore) repositories.PreferenceRepository {
+	return &MemoryPreferenceRepository{store: store}
+}
+
+// findRating 按用户、菜品和菜品行查找评分，调用方需持有锁
+func (s *MemoryStore) findRating(userID, dishID uint, mealRecordDishID *uint) *models.DishRating {
+	for _, rating := range s.dishRatings {
+		if rating.UserID != userID || rating.DishID != dishID {
+			continue
+		}
+		if mealRecordDishID == nil && rating.MealRecordDishID == nil ||
+			mealRecordDishID != nil && rating.MealRecordDishID != nil && *mealRecordDishID == *rating.MealRecordDishID {
+			return rating
+		}
+	}
+	return nil
+}
+
+// dishStats 统计菜品的评分和被吃过的次数，ids 为 nil 时统计全部菜品，调用方需持有读锁
+func (s *MemoryStore) dishStats(ids []uint) map[uint]models.DishStats {
+	var wanted map[uint]bool
+	if ids != nil {
+		wanted = make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			wanted[id] = true
+		}
+	}
+
+	stats := make(map[uint]models.DishStats)
+	totals := make(map[uint]int)
+	for _, rating := range s.dishRatings {
+		if wanted != nil && !wanted[rating.DishID] {
+			continue
+		}
+		st := stats[rating.DishID]
+		st.RatingCount++
+		stats[rating.DishID] = st
+		totals[rating.DishID] += rating.Stars
+	}
+	for dishID, total := range totals {
+		st := stats[dishID]
+		st.AverageRating = models.RoundRating(float64(total) / float64(st.RatingCount))
+		stats[dishID] = st
+	}
+
+	for _, mrd := range s.mealRecordDishes {
+		if wanted != nil && !wanted[mrd.DishID] {
+			continue
+		}
+		if record, ok := s.mealRecords[mrd.MealRecordID]; ok && !record.DeletedAt.Valid {
+			st := stats[mrd.DishID]
+			st.TimesEaten++
+			stats[mrd.DishID] = st
+		}
+	}
+	return stats
+}
+
+// sortDishes 按评分或被吃过的次数排序，保持原有的创建时间倒序作为次序，调用方需持有读锁
+func (s *MemoryStore) sortDishes(dishes []*models.Dish, order string) {
+	if order != repositories.DishSortRating && order != repositories.DishSortPopularity {
+		return
+	}
+	stats := s.dishStats(nil)
+	sort.SliceStable(dishes, func(i, j int) bool {
+		a, b := stats[dishes[i].ID], stats[dishes[j].ID]
+		if order == repositories.DishSortPopularity {
+			return a.TimesEaten > b.TimesEaten
+		}
+		if a.AverageRating != b.AverageRating {
+			return a.AverageRating > b.AverageRating
+		}
+		return a.RatingCount > b.RatingCount
+	})
+}
+
+func (r *MemoryPreferenceRepository) SaveRating(ctx context.Context, rating *models.DishRating) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	if existing := r.store.findRating(rating.UserID, rating.DishID, rating.MealRecordDishID); existing != nil {
+		rating.ID = existing.ID
+		rating.CreatedAt = existing.CreatedAt
+	} else {
+		rating.ID = r.store.nextID("dish_ratings")
+		rating.CreatedAt = now
+	}
+	rating.UpdatedAt = now
+
+	stored := *rating
+	stored.User = nil
+	r.store.dishRatings[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryPreferenceRepository) GetRating(ctx context.Context, userID, dishID uint, mealRecordDishID *uint) (*models.DishRating, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rating := r.store.findRating(userID, dishID, mealRecordDishID)
+	if rating == nil {
+		return nil, fmt.Errorf("评分不存在")
+	}
+	result := *rating
+	return &result, nil
+}
+
+func (r *MemoryPreferenceRepository) DeleteRating(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.dishRatings[id]; !ok {
+		return fmt.Errorf("评分不存在")
+	}
+	delete(r.store.dishRatings, id)
+	return nil
+}
+
+func (r *MemoryPreferenceRepository) ListRatings(ctx context.Context, dishID uint, offset, limit int) ([]*models.DishRating, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var matched []*models.DishRating
+	for _, rating := range r.store.dishRatings {
+		if rating.DishID == dishID {
+			matched = append(matched, rating)
+		}
+	}
+	sortByCreatedDesc(matched,
+		func(rt *models.DishRating) time.Time { return rt.UpdatedAt },
+		func(rt *models.DishRating) uint { return rt.ID })
+
+	ratings := []*models.DishRating{}
+	for _, rating := range paginate(matched, offset, limit) {
+		rt := *rating
+		rt.User = r.store.loadUser(rt.UserID)
+		ratings = append(ratings, &rt)
+	}
+	return ratings, int64(len(matched)), nil
+}
+
+func (r *MemoryPreferenceRepository) DishStats(ctx context.Context, dishIDs []uint) (map[uint]models.DishStats, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if len(dishIDs) == 0 {
+		return make(map[uint]models.DishStats), nil
+	}
+	return r.store.dishStats(dishIDs), nil
+}
+
+func (r *MemoryPreferenceRepository) AddFavorite(ctx context.Context, userID, dishID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	favorites := r.store.dishFavorites[userID]
+	if favorites == nil {
+		favorites = make(map[uint]time.Time)
+		r.store.dishFavorites[userID] = favorites
+	}
+	if _, ok := favorites[dishID]; !ok {
+		favorites[dishID] = time.Now()
+	}
+	return nil
+}
+
+func (r *MemoryPreferenceRepository) RemoveFavorite(ctx context.Context, userID, dishID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.dishFavorites[userID][dishID]; !ok {
+		return fmt.Errorf("未收藏该菜品")
+	}
+	delete(r.store.dishFavorites[userID], dishID)
+	return nil
+}
+
+func (r *MemoryPreferenceRepository) ListFavorites(ctx context.Context, userID uint) ([]*models.DishFavorite, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	favorites := []*models.DishFavorite{}
+	for dishID, createdAt := range r.store.dishFavorites[userID] {
+		dish, ok := r.store.dishes[dishID]
+		if !ok || dish.DeletedAt.Valid {
+			continue
+		}
+		favorites = append(favorites, &models.DishFavorite{
+			UserID:    userID,
+			DishID:    dishID,
+			CreatedAt: createdAt,
+			Dish:      r.store.loadDish(dish, false),
+		})
+	}
+	sortByCreatedDesc(favorites,
+		func(f *models.DishFavorite) time.Time { return f.CreatedAt },
+		func(f *models.DishFavorite) uint { return f.DishID })
+	return favorites, nil
+}
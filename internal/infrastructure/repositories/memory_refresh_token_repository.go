@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+)
+
+type MemoryRefreshTokenRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryRefreshTokenRepository(store *MemoryStore) repositories.RefreshTokenRepository {
+	return &MemoryRefreshTokenRepository{store: store}
+}
+
+func (r *MemoryRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.refreshTokens {
+		if existing.TokenHash == token.TokenHash {
+			return fmt.Errorf("创建刷新令牌失败: 令牌重复")
+		}
+	}
+
+	token.ID = r.store.nextID("refresh_tokens")
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	stored := *token
+	r.store.refreshTokens[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryRefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, token := range r.store.refreshTokens {
+		if token.TokenHash == tokenHash {
+			t := *token
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("刷新令牌不存在")
+}
+
+func (r *MemoryRefreshTokenRepository) MarkUsed(ctx context.Context, id uint) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.refreshTokens[id]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return true, nil
+}
+
+func (r *MemoryRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.store.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRefreshTokenRepository) RevokeByUser(ctx context.Context, userID uint) ([]string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	families := []string{}
+	for _, token := range r.store.refreshTokens {
+		if token.UserID != userID || token.RevokedAt != nil {
+			continue
+		}
+		if token.ExpiresAt.After(now) && !seen[token.FamilyID] {
+			seen[token.FamilyID] = true
+			families = append(families, token.FamilyID)
+		}
+		token.RevokedAt = &now
+	}
+	return families, nil
+}
+
+// MemoryTokenRevocationStore 内存吊销存储
+type MemoryTokenRevocationStore struct {
+	store *MemoryStore
+}
+
+func NewMemoryTokenRevocationStore(store *MemoryStore) repositories.TokenRevocationStore {
+	return &MemoryTokenRevocationStore{store: store}
+}
+
+func (s *MemoryTokenRevocationStore) Revoke(ctx context.Context, key string, expiresAt time.Time) error {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	now := time.Now()
+	for k, exp := range s.store.revokedTokens {
+		if exp.Before(now) {
+			delete(s.store.revokedTokens, k)
+		}
+	}
+	s.store.revokedTokens[key] = expiresAt
+	return nil
+}
+
+func (s *MemoryTokenRevocationStore) IsRevoked(ctx context.Context, keys ...string) (bool, error) {
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	now := time.Now()
+	for _, key := range keys {
+		if exp, ok := s.store.revokedTokens[key]; ok && exp.After(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
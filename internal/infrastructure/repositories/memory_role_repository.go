@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MemoryRoleRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryRoleRepository(store *MemoryStore) repositories.RoleRepository {
+	return &MemoryRoleRepository{store: store}
+}
+
+// checkUnique 模拟角色名称的唯一索引
+func (r *MemoryRoleRepository) checkUnique(role *models.Role) error {
+	for _, existing := range r.store.roles {
+		if existing.ID != role.ID && existing.Name == role.Name {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	return nil
+}
+
+// copyRole 返回角色副本，权限切片不与存储共享
+func copyRole(role *models.Role) *models.Role {
+	c := *role
+	c.Permissions = append([]string{}, role.Permissions...)
+	return &c
+}
+
+func (r *MemoryRoleRepository) Create(ctx context.Context, role *models.Role) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.checkUnique(role); err != nil {
+		return fmt.Errorf("创建角色失败: %w", err)
+	}
+
+	now := time.Now()
+	role.ID = r.store.nextID("roles")
+	role.CreatedAt = now
+	role.UpdatedAt = now
+	r.store.roles[role.ID] = copyRole(role)
+	return nil
+}
+
+func (r *MemoryRoleRepository) GetByID(ctx context.Context, id uint) (*models.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	role, ok := r.store.roles[id]
+	if !ok {
+		return nil, fmt.Errorf("角色不存在")
+	}
+	return copyRole(role), nil
+}
+
+func (r *MemoryRoleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, role := range r.store.roles {
+		if role.Name == name {
+			return copyRole(role), nil
+		}
+	}
+	return nil, fmt.Errorf("角色不存在")
+}
+
+func (r *MemoryRoleRepository) Update(ctx context.Context, role *models.Role) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.roles[role.ID]; !ok {
+		return fmt.Errorf("更新角色失败: 角色不存在")
+	}
+	if err := r.checkUnique(role); err != nil {
+		return fmt.Errorf("更新角色失败: %w", err)
+	}
+
+	role.UpdatedAt = time.Now()
+	r.store.roles[role.ID] = copyRole(role)
+	return nil
+}
+
+func (r *MemoryRoleRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.roles[id]; !ok {
+		return fmt.Errorf("角色不存在")
+	}
+	delete(r.store.roles, id)
+	return nil
+}
+
+func (r *MemoryRoleRepository) List(ctx context.Context) ([]*models.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	roles := []*models.Role{}
+	for _, role := range r.store.roles {
+		roles = append(roles, copyRole(role))
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (r *MemoryRoleRepository) CountUsers(ctx context.Context, name string) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, user := range r.store.users {
+		if !user.DeletedAt.Valid && user.Role == name {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+)
+
+type MemoryShoppingListRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryShoppingListRepository(store *MemoryStore) repositories.ShoppingListRepository {
+	return &MemoryShoppingListRepository{store: store}
+}
+
+// loadList 返回未删除清单的副本（不含条目），调用方需持有读锁
+func (r *MemoryShoppingListRepository) loadList(id uint) *models.ShoppingList {
+	list, ok := r.store.shoppingLists[id]
+	if !ok || list.DeletedAt.Valid {
+		return nil
+	}
+	l := *list
+	l.Items = nil
+	return &l
+}
+
+func (r *MemoryShoppingListRepository) Create(ctx context.Context, list *models.ShoppingList) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	list.ID = r.store.nextID("shopping_lists")
+	list.CreatedAt = now
+	list.UpdatedAt = now
+	stored := *list
+	stored.Items = nil
+	r.store.shoppingLists[stored.ID] = &stored
+
+	for i := range list.Items {
+		item := &list.Items[i]
+		item.ID = r.store.nextID("shopping_list_items")
+		item.ShoppingListID = list.ID
+		storedItem := *item
+		r.store.shoppingListItems[storedItem.ID] = &storedItem
+	}
+	return nil
+}
+
+func (r *MemoryShoppingListRepository) GetByID(ctx context.Context, id uint) (*models.ShoppingList, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	list := r.loadList(id)
+	if list == nil {
+		return nil, fmt.Errorf("购物清单不存在")
+	}
+	for _, item := range r.store.shoppingListItems {
+		if item.ShoppingListID == id {
+			list.Items = append(list.Items, *item)
+		}
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		if list.Items[i].SortOrder != list.Items[j].SortOrder {
+			return list.Items[i].SortOrder < list.Items[j].SortOrder
+		}
+		return list.Items[i].ID < list.Items[j].ID
+	})
+	return list, nil
+}
+
+func (r *MemoryShoppingListRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if list, ok := r.store.shoppingLists[id]; ok && !list.DeletedAt.Valid {
+		list.DeletedAt = softDeleted()
+	}
+	return nil
+}
+
+func (r *MemoryShoppingListRepository) List(ctx context.Context, householdID uint, offset, limit int) ([]*models.ShoppingList, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var matched []*models.ShoppingList
+	for _, list := range r.store.shoppingLists {
+		if !list.DeletedAt.Valid && list.HouseholdID == householdID {
+			matched = append(matched, list)
+		}
+	}
+	sortByCreatedDesc(matched,
+		func(l *models.ShoppingList) time.Time { return l.CreatedAt },
+		func(l *models.ShoppingList) uint { return l.ID })
+
+	lists := []*models.ShoppingList{}
+	for _, list := range paginate(matched, offset, limit) {
+		lists = append(lists, r.loadList(list.ID))
+	}
+	return lists, int64(len(matched)), nil
+}
+
+func (r *MemoryShoppingListRepository) UpdateItem(ctx context.Context, item *models.ShoppingListItem) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.shoppingListItems[item.ID]
+	if !ok {
+		return fmt.Errorf("购物清单条目不存在")
+	}
+	stored.Checked = item.Checked
+	stored.CheckedAt = item.CheckedAt
+	return nil
+}
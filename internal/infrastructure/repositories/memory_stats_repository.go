@@ -0,0 +1,273 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+)
+
+type MemoryStatsRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryStatsRepository(store *MemoryStore) repositories.StatsRepository {
+	return &MemoryStatsRepository{store: store}
+}
+
+// statsMeals 家庭中符合条件的未删除用餐记录，按用餐时间升序，调用方需持有读锁
+func (s *MemoryStore) statsMeals(householdID uint, filter repositories.StatsFilter) []*models.MealRecord {
+	var records []*models.MealRecord
+	for _, record := range s.mealRecords {
+		switch {
+		case record.DeletedAt.Valid || record.HouseholdID != householdID:
+			continue
+		case filter.From != nil && record.EatenAt.Before(*filter.From):
+			continue
+		case filter.To != nil && !record.EatenAt.Before(*filter.To):
+			continue
+		case filter.MealType != "" && record.MealType != filter.MealType:
+			continue
+		case filter.UserID != 0 && record.UserID != filter.UserID:
+			continue
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].EatenAt.Equal(records[j].EatenAt) {
+			return records[i].EatenAt.Before(records[j].EatenAt)
+		}
+		return records[i].ID < records[j].ID
+	})
+	return records
+}
+
+// statsMealDishes 按用餐记录分组的菜品行，同一记录内按ID升序，调用方需持有读锁
+func (s *MemoryStore) statsMealDishes(records []*models.MealRecord) map[uint][]*models.MealRecordDish {
+	wanted := make(map[uint]bool, len(records))
+	for _, record := range records {
+		wanted[record.ID] = true
+	}
+	lines := make(map[uint][]*models.MealRecordDish)
+	for _, line := range s.mealRecordDishes {
+		if wanted[line.MealRecordID] {
+			lines[line.MealRecordID] = append(lines[line.MealRecordID], line)
+		}
+	}
+	for _, list := range lines {
+		sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	}
+	return lines
+}
+
+func (r *MemoryStatsRepository) Summary(ctx context.Context, householdID uint, filter repositories.StatsFilter) (*models.SpendingSummary, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	records := r.store.statsMeals(householdID, filter)
+	var summary models.SpendingSummary
+	for _, record := range records {
+		summary.TotalSpent += record.TotalPrice
+		summary.MealCount++
+	}
+	dishes := make(map[uint]bool)
+	for _, lines := range r.store.statsMealDishes(records) {
+		for _, line := range lines {
+			dishes[line.DishID] = true
+		}
+	}
+	summary.DishCount = int64(len(dishes))
+
+	summary.TotalSpent = models.RoundPrice(summary.TotalSpent)
+	if summary.MealCount > 0 {
+		summary.AveragePerMeal = models.RoundPrice(summary.TotalSpent / float64(summary.MealCount))
+	}
+	return &summary, nil
+}
+
+func (r *MemoryStatsRepository) Spending(ctx context.Context, householdID uint, filter repositories.StatsFilter, period string) ([]models.SpendingBucket, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var layout string
+	switch period {
+	case repositories.StatsPeriodDay, repositories.StatsPeriodWeek:
+		layout = "2006-01-02"
+	case repositories.StatsPeriodMonth:
+		layout = "2006-01"
+	default:
+		return nil, fmt.Errorf("不支持的统计周期")
+	}
+
+	buckets := []models.SpendingBucket{}
+	for _, record := range r.store.statsMeals(householdID, filter) {
+		t := record.EatenAt.Local() // 与数据库一样按本地日期分组
+		if period == repositories.StatsPeriodWeek {
+			t = t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+		}
+		key := t.Format(layout)
+		// 记录按时间升序，同一时段总是连续出现
+		if len(buckets) == 0 || buckets[len(buckets)-1].Period != key {
+			buckets = append(buckets, models.SpendingBucket{Period: key})
+		}
+		bucket := &buckets[len(buckets)-1]
+		bucket.TotalSpent += record.TotalPrice
+		bucket.MealCount++
+	}
+	for i := range buckets {
+		buckets[i].TotalSpent = models.RoundPrice(buckets[i].TotalSpent)
+		buckets[i].AveragePerMeal = models.RoundPrice(buckets[i].TotalSpent / float64(buckets[i].MealCount))
+	}
+	return buckets, nil
+}
+
+func (r *MemoryStatsRepository) TopDishes(ctx context.Context, householdID uint, filter repositories.StatsFilter, limit int) ([]models.DishFrequency, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	byDish := make(map[uint]*models.DishFrequency)
+	for _, lines := range r.store.statsMealDishes(r.store.statsMeals(householdID, filter)) {
+		counted := make(map[uint]bool)
+		for _, line := range lines {
+			dish, ok := byDish[line.DishID]
+			if !ok {
+				dish = &models.DishFrequency{DishID: line.DishID}
+				if d, exists := r.store.dishes[line.DishID]; exists {
+					dish.Name = d.Name
+				}
+				byDish[line.DishID] = dish
+			}
+			if !counted[line.DishID] {
+				dish.TimesEaten++
+				counted[line.DishID] = true
+			}
+			dish.Quantity += int64(line.Quantity)
+			dish.TotalSpent += float64(line.Quantity) * line.UnitPrice
+		}
+	}
+
+	dishes := make([]models.DishFrequency, 0, len(byDish))
+	for _, dish := range byDish {
+		dish.TotalSpent = models.RoundPrice(dish.TotalSpent)
+		dishes = append(dishes, *dish)
+	}
+	sort.Slice(dishes, func(i, j int) bool {
+		if dishes[i].TimesEaten != dishes[j].TimesEaten {
+			return dishes[i].TimesEaten > dishes[j].TimesEaten
+		}
+		if dishes[i].Quantity != dishes[j].Quantity {
+			return dishes[i].Quantity > dishes[j].Quantity
+		}
+		return dishes[i].DishID < dishes[j].DishID
+	})
+	return paginate(dishes, 0, limit), nil
+}
+
+func (r *MemoryStatsRepository) TopCategories(ctx context.Context, householdID uint, filter repositories.StatsFilter, limit int) ([]models.CategoryFrequency, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	byCategory := make(map[uint]*models.CategoryFrequency) // 未分类的键为 0
+	dishes := make(map[uint]map[uint]bool)
+	for _, lines := range r.store.statsMealDishes(r.store.statsMeals(householdID, filter)) {
+		for _, line := range lines {
+			var categoryID *uint
+			if d, ok := r.store.dishes[line.DishID]; ok && d.CategoryID != nil {
+				id := *d.CategoryID
+				categoryID = &id
+			}
+			var key uint
+			if categoryID != nil {
+				key = *categoryID
+			}
+
+			category, ok := byCategory[key]
+			if !ok {
+				category = &models.CategoryFrequency{CategoryID: categoryID, Name: uncategorizedName}
+				if categoryID != nil {
+					category.Name = ""
+					if c, exists := r.store.categories[key]; exists {
+						category.Name = c.Name
+					}
+				}
+				byCategory[key] = category
+				dishes[key] = make(map[uint]bool)
+			}
+			category.TimesEaten++
+			category.TotalSpent += float64(line.Quantity) * line.UnitPrice
+			dishes[key][line.DishID] = true
+		}
+	}
+
+	categories := make([]models.CategoryFrequency, 0, len(byCategory))
+	for key, category := range byCategory {
+		category.DishCount = int64(len(dishes[key]))
+		category.TotalSpent = models.RoundPrice(category.TotalSpent)
+		categories = append(categories, *category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].TimesEaten != categories[j].TimesEaten {
+			return categories[i].TimesEaten > categories[j].TimesEaten
+		}
+		// 与 SQL 中 NULL 排在最前保持一致
+		if categories[i].CategoryID == nil || categories[j].CategoryID == nil {
+			return categories[i].CategoryID == nil && categories[j].CategoryID != nil
+		}
+		return *categories[i].CategoryID < *categories[j].CategoryID
+	})
+	return paginate(categories, 0, limit), nil
+}
+
+func (r *MemoryStatsRepository) VarietyStreak(ctx context.Context, householdID uint, filter repositories.StatsFilter) (models.VarietyStreak, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	records := r.store.statsMeals(householdID, filter)
+	lines := r.store.statsMealDishes(records)
+	var entries []models.MealDishEntry
+	for _, record := range records {
+		for _, line := range lines[record.ID] {
+			entries = append(entries, models.MealDishEntry{MealRecordID: record.ID, EatenAt: record.EatenAt, DishID: line.DishID})
+		}
+	}
+	return models.LongestVarietyStreak(entries), nil
+}
+
+func (r *MemoryStatsRepository) Members(ctx context.Context, householdID uint, filter repositories.StatsFilter) ([]models.MemberStats, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	records := r.store.statsMeals(householdID, filter)
+	participants := make(map[uint][]*models.MealRecordParticipant)
+	for _, participant := range r.store.mealRecordParticipants {
+		participants[participant.MealRecordID] = append(participants[participant.MealRecordID], participant)
+	}
+
+	username := func(id uint) string {
+		if user, ok := r.store.users[id]; ok {
+			return user.Username
+		}
+		return ""
+	}
+
+	var participated, recorded []models.MemberStats
+	for _, record := range records {
+		recorded = append(recorded, models.MemberStats{UserID: record.UserID, Username: username(record.UserID), Recorded: 1})
+
+		list := participants[record.ID]
+		for _, participant := range list {
+			if participant.UserID == nil {
+				continue
+			}
+			participated = append(participated, models.MemberStats{
+				UserID:    *participant.UserID,
+				Username:  username(*participant.UserID),
+				MealCount: 1,
+				Spent:     record.TotalPrice / float64(len(list)),
+			})
+		}
+	}
+	return mergeMemberStats(participated, recorded), nil
+}
@@ -0,0 +1,319 @@
+package repositories
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"foodcook/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// MemoryStore 内存存储，所有内存仓储共享同一份数据，
+// 以便实现跨表的查询（预加载、使用情况检查等）
+type MemoryStore struct {
+	mu sync.RWMutex
+
+	users            map[uint]*models.User
+	categories       map[uint]*models.Category
+	dishes           map[uint]*models.Dish
+	ingredients      map[uint]*models.Ingredient
+	dishIngredients  map[uint]*models.DishIngredient
+	dishSteps        map[uint]*models.DishStep
+	conversions      map[uint]*models.IngredientUnitConversion
+	tags             map[uint]*models.Tag
+	dishTags         map[uint][]uint // 菜品ID -> 标签ID
+	dishRatings      map[uint]*models.DishRating
+	dishFavorites    map[uint]map[uint]time.Time // 用户ID -> 菜品ID -> 收藏时间
+	priceHistory     map[uint]*models.IngredientPriceHistory
+	mealRecords      map[uint]*models.MealRecord
+	mealRecordDishes map[uint]*models.MealRecordDish
+	refreshTokens    map[uint]*models.RefreshToken
+	revokedTokens    map[string]time.Time
+
+	households           map[uint]*models.Household
+	householdMembers     map[uint]*models.HouseholdMember
+	householdInvitations map[uint]*models.HouseholdInvitation
+	mealRecordComments   map[uint]*models.MealRecordComment
+	mealRecordPhotos     map[uint]*models.MealRecordPhoto
+
+	mealRecordParticipants map[uint]*models.MealRecordParticipant
+
+	mealPlans          map[uint]*models.MealPlan
+	mealPlanSlots      map[uint]*models.MealPlanSlot
+	mealPlanSlotDishes map[uint]*models.MealPlanSlotDish
+
+	shoppingLists     map[uint]*models.ShoppingList
+	shoppingListItems map[uint]*models.ShoppingListItem
+	pantry            map[uint]*models.PantryItem
+
+	budgets      map[uint]*models.Budget
+	budgetLimits map[uint]*models.BudgetCategoryLimit
+
+	roles   map[uint]*models.Role
+	uploads map[uint]*models.Upload
+
+	// 各表的自增ID
+	sequences map[string]uint
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:            make(map[uint]*models.User),
+		categories:       make(map[uint]*models.Category),
+		dishes:           make(map[uint]*models.Dish),
+		ingredients:      make(map[uint]*models.Ingredient),
+		dishIngredients:  make(map[uint]*models.DishIngredient),
+		dishSteps:        make(map[uint]*models.DishStep),
+		conversions:      make(map[uint]*models.IngredientUnitConversion),
+		tags:             make(map[uint]*models.Tag),
+		dishTags:         make(map[uint][]uint),
+		dishRatings:      make(map[uint]*models.DishRating),
+		dishFavorites:    make(map[uint]map[uint]time.Time),
+		priceHistory:     make(map[uint]*models.IngredientPriceHistory),
+		mealRecords:      make(map[uint]*models.MealRecord),
+		mealRecordDishes: make(map[uint]*models.MealRecordDish),
+		refreshTokens:    make(map[uint]*models.RefreshToken),
+		revokedTokens:    make(map[string]time.Time),
+
+		households:           make(map[uint]*models.Household),
+		householdMembers:     make(map[uint]*models.HouseholdMember),
+		householdInvitations: make(map[uint]*models.HouseholdInvitation),
+		mealRecordComments:   make(map[uint]*models.MealRecordComment),
+		mealRecordPhotos:     make(map[uint]*models.MealRecordPhoto),
+
+		mealRecordParticipants: make(map[uint]*models.MealRecordParticipant),
+
+		mealPlans:          make(map[uint]*models.MealPlan),
+		mealPlanSlots:      make(map[uint]*models.MealPlanSlot),
+		mealPlanSlotDishes: make(map[uint]*models.MealPlanSlotDish),
+
+		shoppingLists:     make(map[uint]*models.ShoppingList),
+		shoppingListItems: make(map[uint]*models.ShoppingListItem),
+		pantry:            make(map[uint]*models.PantryItem),
+
+		budgets:      make(map[uint]*models.Budget),
+		budgetLimits: make(map[uint]*models.BudgetCategoryLimit),
+
+		roles:   make(map[uint]*models.Role),
+		uploads: make(map[uint]*models.Upload),
+
+		sequences: make(map[string]uint),
+	}
+}
+
+// nextID 生成指定表的下一个自增ID，调用方需持有写锁
+func (s *MemoryStore) nextID(table string) uint {
+	s.sequences[table]++
+	return s.sequences[table]
+}
+
+// softDeleted 生成软删除标记
+func softDeleted() gorm.DeletedAt {
+	return gorm.DeletedAt{Time: time.Now(), Valid: true}
+}
+
+// paginate 按 offset/limit 截取结果，limit 小于 0 表示不限制
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// sortByCreatedDesc 按创建时间倒序排列，时间相同时按ID倒序
+func sortByCreatedDesc[T any](items []T, createdAt func(T) time.Time, id func(T) uint) {
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, tj := createdAt(items[i]), createdAt(items[j])
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return id(items[i]) > id(items[j])
+	})
+}
+
+// 以下 load* 方法返回存储对象的副本并填充关联，调用方需持有读锁
+
+func (s *MemoryStore) loadCategory(id *uint) *models.Category {
+	if id == nil {
+		return nil
+	}
+	category, ok := s.categories[*id]
+	if !ok || category.DeletedAt.Valid {
+		return nil
+	}
+	c := *category
+	return &c
+}
+
+// activeCategories 返回未删除的分类
+func (s *MemoryStore) activeCategories() []*models.Category {
+	categories := make([]*models.Category, 0, len(s.categories))
+	for _, category := range s.categories {
+		if !category.DeletedAt.Valid {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+func (s *MemoryStore) loadDish(dish *models.Dish, withIngredients bool) *models.Dish {
+	d := *dish
+	d.Category = s.loadCategory(d.CategoryID)
+	d.Ingredients = nil
+	d.Steps = nil
+	d.MealRecords = nil
+	d.Tags = s.dishTagList(d.ID)
+
+	if withIngredients {
+		for _, di := range s.sortedDishIngredients(d.ID) {
+			item := *di
+			if ingredient, ok := s.ingredients[item.IngredientID]; ok && !ingredient.DeletedAt.Valid {
+				ing := *ingredient
+				ing.DishIngredients = nil
+				ing.Conversions = s.ingredientConversions(ing.ID)
+				item.Ingredient = &ing
+			}
+			d.Ingredients = append(d.Ingredients, item)
+		}
+	}
+	return &d
+}
+
+// dishTagList 按名称顺序返回菜品的标签
+func (s *MemoryStore) dishTagList(dishID uint) []models.Tag {
+	var tags []models.Tag
+	for _, tagID := range s.dishTags[dishID] {
+		if tag, ok := s.tags[tagID]; ok {
+			tags = append(tags, *tag)
+		}
+	}
+	sort.Slice(tags, func(a, b int) bool { return tags[a].Name < tags[b].Name })
+	return tags
+}
+
+// ingredientConversions 按ID顺序返回食材的换算关系
+func (s *MemoryStore) ingredientConversions(ingredientID uint) []models.IngredientUnitConversion {
+	var conversions []models.IngredientUnitConversion
+	for _, conv := range s.conversions {
+		if conv.IngredientID == ingredientID {
+			conversions = append(conversions, *conv)
+		}
+	}
+	sort.Slice(conversions, func(a, b int) bool { return conversions[a].ID < conversions[b].ID })
+	return conversions
+}
+
+func (s *MemoryStore) loadUser(id uint) *models.User {
+	user, ok := s.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil
+	}
+	u := *user
+	u.MealRecords = nil
+	return &u
+}
+
+func (s *MemoryStore) loadMealRecord(record *models.MealRecord) *models.MealRecord {
+	m := *record
+	m.User = s.loadUser(m.UserID)
+	m.Dishes = nil
+	m.Comments = nil
+	m.Photos = nil
+	m.Participants = nil
+
+	for _, photo := range s.sortedMealRecordPhotos(m.ID) {
+		m.Photos = append(m.Photos, *photo)
+	}
+
+	var participants []*models.MealRecordParticipant
+	for _, participant := range s.mealRecordParticipants {
+		if participant.MealRecordID == m.ID {
+			participants = append(participants, participant)
+		}
+	}
+	sort.Slice(participants, func(i, j int) bool { return participants[i].ID < participants[j].ID })
+	for _, participant := range participants {
+		p := *participant
+		if p.UserID != nil {
+			p.User = s.loadUser(*p.UserID)
+		}
+		m.Participants = append(m.Participants, p)
+	}
+
+	for _, mrd := range s.sortedMealRecordDishes(m.ID) {
+		item := *mrd
+		if dish, ok := s.dishes[item.DishID]; ok && !dish.DeletedAt.Valid {
+			d := *dish
+			d.Category = nil
+			d.Ingredients = nil
+			d.MealRecords = nil
+			item.Dish = &d
+		}
+		m.Dishes = append(m.Dishes, item)
+	}
+	return &m
+}
+
+// sortedMealRecordPhotos 按排序值返回记录的照片，调用方需持有读锁
+func (s *MemoryStore) sortedMealRecordPhotos(mealRecordID uint) []*models.MealRecordPhoto {
+	var photos []*models.MealRecordPhoto
+	for _, photo := range s.mealRecordPhotos {
+		if photo.MealRecordID == mealRecordID {
+			photos = append(photos, photo)
+		}
+	}
+	sort.Slice(photos, func(i, j int) bool {
+		if photos[i].SortOrder != photos[j].SortOrder {
+			return photos[i].SortOrder < photos[j].SortOrder
+		}
+		return photos[i].ID < photos[j].ID
+	})
+	return photos
+}
+
+func (s *MemoryStore) sortedDishSteps(dishID uint) []models.DishStep {
+	var steps []models.DishStep
+	for _, step := range s.dishSteps {
+		if step.DishID == dishID {
+			steps = append(steps, *step)
+		}
+	}
+	sort.Slice(steps, func(i, j int) bool {
+		if steps[i].SortOrder != steps[j].SortOrder {
+			return steps[i].SortOrder < steps[j].SortOrder
+		}
+		return steps[i].ID < steps[j].ID
+	})
+	return steps
+}
+
+func (s *MemoryStore) sortedDishIngredients(dishID uint) []*models.DishIngredient {
+	var items []*models.DishIngredient
+	for _, di := range s.dishIngredients {
+		if di.DishID == dishID {
+			items = append(items, di)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
+
+func (s *MemoryStore) sortedMealRecordDishes(mealRecordID uint) []*models.MealRecordDish {
+	var items []*models.MealRecordDish
+	for _, mrd := range s.mealRecordDishes {
+		if mrd.MealRecordID == mealRecordID {
+			items = append(items, mrd)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+)
+
+type MemoryTagRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryTagRepository(store *MemoryStore) repositories.TagRepository {
+	return &MemoryTagRepository{store: store}
+}
+
+// checkUnique 模拟名称的唯一索引，调用方需持有锁
+func (r *MemoryTagRepository) checkUnique(tag *models.Tag) error {
+	for _, existing := range r.store.tags {
+		if existing.ID != tag.ID && existing.Name == tag.Name {
+			return fmt.Errorf("标签名称已存在")
+		}
+	}
+	return nil
+}
+
+func (r *MemoryTagRepository) Create(ctx context.Context, tag *models.Tag) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.checkUnique(tag); err != nil {
+		return fmt.Errorf("创建标签失败: %w", err)
+	}
+	tag.ID = r.store.nextID("tags")
+	if tag.CreatedAt.IsZero() {
+		tag.CreatedAt = time.Now()
+	}
+
+	stored := *tag
+	r.store.tags[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryTagRepository) GetByID(ctx context.Context, id uint) (*models.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tag, ok := r.store.tags[id]
+	if !ok {
+		return nil, fmt.Errorf("标签不存在")
+	}
+	t := *tag
+	return &t, nil
+}
+
+func (r *MemoryTagRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tags := []models.Tag{}
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if tag, ok := r.store.tags[id]; ok && !seen[id] {
+			seen[id] = true
+			tags = append(tags, *tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (r *MemoryTagRepository) GetByName(ctx context.Context, name string) (*models.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, tag := range r.store.tags {
+		if tag.Name == name {
+			t := *tag
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("标签不存在")
+}
+
+func (r *MemoryTagRepository) Update(ctx context.Context, tag *models.Tag) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.tags[tag.ID]; !ok {
+		return fmt.Errorf("更新标签失败: 标签不存在")
+	}
+	if err := r.checkUnique(tag); err != nil {
+		return fmt.Errorf("更新标签失败: %w", err)
+	}
+
+	stored := *tag
+	r.store.tags[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryTagRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.tags[id]; !ok {
+		return fmt.Errorf("标签不存在")
+	}
+	delete(r.store.tags, id)
+
+	for dishID, tagIDs := range r.store.dishTags {
+		kept := tagIDs[:0]
+		for _, tagID := range tagIDs {
+			if tagID != id {
+				kept = append(kept, tagID)
+			}
+		}
+		r.store.dishTags[dishID] = kept
+	}
+	return nil
+}
+
+func (r *MemoryTagRepository) List(ctx context.Context) ([]*models.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tags := []*models.Tag{}
+	for _, tag := range r.store.tags {
+		t := *tag
+		tags = append(tags, &t)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (r *MemoryTagRepository) Cloud(ctx context.Context) ([]repositories.TagUsage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[uint]int64)
+	for dishID, tagIDs := range r.store.dishTags {
+		if dish, ok := r.store.dishes[dishID]; !ok || dish.DeletedAt.Valid {
+			continue
+		}
+		for _, tagID := range tagIDs {
+			counts[tagID]++
+		}
+	}
+
+	usages := []repositories.TagUsage{}
+	for _, tag := range r.store.tags {
+		usages = append(usages, repositories.TagUsage{Tag: *tag, DishCount: counts[tag.ID]})
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].DishCount != usages[j].DishCount {
+			return usages[i].DishCount > usages[j].DishCount
+		}
+		return usages[i].Name < usages[j].Name
+	})
+	return usages, nil
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+)
+
+type MemoryUploadRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryUploadRepository(store *MemoryStore) repositories.UploadRepository {
+	return &MemoryUploadRepository{store: store}
+}
+
+func (r *MemoryUploadRepository) Create(ctx context.Context, upload *models.Upload) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	upload.ID = r.store.nextID("uploads")
+	if upload.CreatedAt.IsZero() {
+		upload.CreatedAt = time.Now()
+	}
+	stored := *upload
+	r.store.uploads[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryUploadRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.uploads, id)
+	return nil
+}
+
+// referencedURLs 收集被引用的图片地址（包括软删除的记录），调用方需持有读锁
+func (r *MemoryUploadRepository) referencedURLs() map[string]bool {
+	urls := make(map[string]bool)
+	for _, dish := range r.store.dishes {
+		urls[dish.ImageURL] = true
+	}
+	for _, step := range r.store.dishSteps {
+		urls[step.ImageURL] = true
+	}
+	for _, record := range r.store.mealRecords {
+		urls[record.ImageURL] = true
+	}
+	for _, photo := range r.store.mealRecordPhotos {
+		urls[photo.URL] = true
+	}
+	for _, user := range r.store.users {
+		urls[user.AvatarURL] = true
+	}
+	for _, category := range r.store.categories {
+		urls[category.Icon] = true
+	}
+	return urls
+}
+
+func (r *MemoryUploadRepository) ListOrphans(ctx context.Context, before time.Time, limit int) ([]*models.Upload, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	referenced := r.referencedURLs()
+	uploads := []*models.Upload{}
+	for _, upload := range r.store.uploads {
+		if upload.CreatedAt.Before(before) && !referenced[upload.URL] && !referenced[upload.ThumbnailURL] {
+			u := *upload
+			uploads = append(uploads, &u)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool { return uploads[i].ID < uploads[j].ID })
+	return paginate(uploads, 0, limit), nil
+}
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MemoryUserRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryUserRepository(store *MemoryStore) repositories.UserRepository {
+	return &MemoryUserRepository{store: store}
+}
+
+// checkUnique 模拟用户名、邮箱的唯一索引（软删除的记录同样占用索引）
+func (r *MemoryUserRepository) checkUnique(user *models.User) error {
+	for _, existing := range r.store.users {
+		if existing.ID == user.ID {
+			continue
+		}
+		if existing.Username == user.Username || existing.Email == user.Email {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	return nil
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.checkUnique(user); err != nil {
+		return fmt.Errorf("创建用户失败: %w", err)
+	}
+
+	now := time.Now()
+	user.ID = r.store.nextID("users")
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	user.UpdatedAt = now
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+
+	stored := *user
+	stored.MealRecords = nil
+	r.store.users[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryUserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, fmt.Errorf("用户不存在")
+	}
+	u := *user
+	return &u, nil
+}
+
+func (r *MemoryUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.findOne(func(u *models.User) bool { return u.Username == username })
+}
+
+func (r *MemoryUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.findOne(func(u *models.User) bool { return u.Email == email })
+}
+
+func (r *MemoryUserRepository) findOne(match func(*models.User) bool) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if !user.DeletedAt.Valid && match(user) {
+			u := *user
+			return &u, nil
+		}
+	}
+	return nil, fmt.Errorf("用户不存在")
+}
+
+func (r *MemoryUserRepository) Update(ctx context.Context, user *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.users[user.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return fmt.Errorf("用户不存在")
+	}
+	if err := r.checkUnique(user); err != nil {
+		return fmt.Errorf("更新用户失败: %w", err)
+	}
+
+	user.UpdatedAt = time.Now()
+	stored := *user
+	stored.MealRecords = nil
+	r.store.users[stored.ID] = &stored
+	return nil
+}
+
+func (r *MemoryUserRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return fmt.Errorf("用户不存在")
+	}
+	user.DeletedAt = softDeleted()
+	return nil
+}
+
+func (r *MemoryUserRepository) List(ctx context.Context, offset, limit int) ([]*models.User, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var users []*models.User
+	for _, user := range r.store.users {
+		if user.DeletedAt.Valid {
+			continue
+		}
+		u := *user
+		users = append(users, &u)
+	}
+	sortByCreatedDesc(users,
+		func(u *models.User) time.Time { return u.CreatedAt },
+		func(u *models.User) uint { return u.ID })
+
+	return paginate(users, offset, limit), int64(len(users)), nil
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MySQLBudgetRepository struct {
+	db *gorm.DB
+}
+
+func NewMySQLBudgetRepository(db *gorm.DB) repositories.BudgetRepository {
+	return &MySQLBudgetRepository{db: db}
+}
+
+// budgetScope 按家庭和成员定位预算
+func budgetScope(db *gorm.DB, householdID uint, userID *uint) *gorm.DB {
+	db = db.Where("household_id = ?", householdID)
+	if userID == nil {
+		return db.Where("user_id IS NULL")
+	}
+	return db.Where("user_id = ?", *userID)
+}
+
+// orderLimits 分类限额按ID升序预加载
+func orderLimits(db *gorm.DB) *gorm.DB {
+	return db.Order("id ASC")
+}
+
+func (r *MySQLBudgetRepository) Save(ctx context.Context, budget *models.Budget) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var existing models.Budget
+	result := budgetScope(tx, budget.HouseholdID, budget.UserID).Where("month = ?", budget.Month).First(&existing)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		tx.Rollback()
+		return fmt.Errorf("查询预算失败: %w", result.Error)
+	}
+	if result.Error == nil {
+		budget.ID = existing.ID
+		budget.CreatedAt = existing.CreatedAt
+	}
+
+	limits := budget.CategoryLimits
+	if err := tx.Omit("CategoryLimits").Save(budget).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("保存预算失败: %w", err)
+	}
+
+	if err := tx.Where("budget_id = ?", budget.ID).Delete(&models.BudgetCategoryLimit{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("删除分类限额失败: %w", err)
+	}
+	for i := range limits {
+		limits[i].ID = 0
+		limits[i].BudgetID = budget.ID
+	}
+	if len(limits) > 0 {
+		if err := tx.Omit("Category").Create(&limits).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("保存分类限额失败: %w", err)
+		}
+	}
+	budget.CategoryLimits = limits
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+func (r *MySQLBudgetRepository) Find(ctx context.Context, householdID uint, userID *uint, month string) (*models.Budget, error) {
+	var budget models.Budget
+	result := budgetScope(r.db.WithContext(ctx), householdID, userID).
+		Preload("CategoryLimits", orderLimits).Preload("CategoryLimits.Category").
+		Where("month = ?", month).
+		First(&budget)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("预算不存在")
+		}
+		return nil, fmt.Errorf("查询预算失败: %w", result.Error)
+	}
+	return &budget, nil
+}
+
+func (r *MySQLBudgetRepository) Delete(ctx context.Context, id uint) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("budget_id = ?", id).Delete(&models.BudgetCategoryLimit{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("删除分类限额失败: %w", err)
+	}
+	result := tx.Delete(&models.Budget{}, id)
+	if result.Error != nil {
+		tx.Rollback()
+		return fmt.Errorf("删除预算失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return fmt.Errorf("预算不存在")
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+func (r *MySQLBudgetRepository) List(ctx context.Context, householdID uint, userID *uint, offset, limit int) ([]*models.Budget, int64, error) {
+	var budgets []*models.Budget
+	var total int64
+
+	query := budgetScope(r.db.WithContext(ctx).Model(&models.Budget{}), householdID, userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询预算总数失败: %w", err)
+	}
+
+	result := query.Preload("CategoryLimits", orderLimits).Preload("CategoryLimits.Category").
+		Offset(offset).Limit(limit).Order("month DESC").Find(&budgets)
+	if result.Error != nil {
+		return nil, 0, fmt.Errorf("查询预算列表失败: %w", result.Error)
+	}
+	return budgets, total, nil
+}
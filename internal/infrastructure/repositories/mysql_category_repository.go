@@ -57,9 +57,58 @@ func (r *MySQLCategoryRepository) Delete(ctx context.Context, id uint) error {
 	return nil
 }
 
+func (r *MySQLCategoryRepository) DeleteWithReassign(ctx context.Context, id, targetID uint) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("开始事务失败: %w", tx.Error)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 移动菜品
+	if err := tx.Model(&models.Dish{}).Where("category_id = ?", id).Update("category_id", targetID).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("移动分类菜品失败: %w", err)
+	}
+
+	// 移动下级分类
+	if err := tx.Model(&models.Category{}).Where("parent_id = ?", id).Update("parent_id", targetID).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("移动下级分类失败: %w", err)
+	}
+
+	result := tx.Delete(&models.Category{}, id)
+	if result.Error != nil {
+		tx.Rollback()
+		return fmt.Errorf("删除分类失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return fmt.Errorf("分类不存在")
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+func (r *MySQLCategoryRepository) CountUsage(ctx context.Context, id uint) (int64, int64, error) {
+	var dishes, children int64
+	if err := r.db.WithContext(ctx).Model(&models.Dish{}).Where("category_id = ?", id).Count(&dishes).Error; err != nil {
+		return 0, 0, fmt.Errorf("统计分类菜品失败: %w", err)
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+		return 0, 0, fmt.Errorf("统计下级分类失败: %w", err)
+	}
+	return dishes, children, nil
+}
+
 func (r *MySQLCategoryRepository) List(ctx context.Context) ([]*models.Category, error) {
 	var categories []*models.Category
-	result := r.db.WithContext(ctx).Order("name ASC").Find(&categories)
+	result := r.db.WithContext(ctx).Order("sort_order ASC, name ASC, id ASC").Find(&categories)
 	if result.Error != nil {
 		return nil, fmt.Errorf("查询分类列表失败: %w", result.Error)
 	}
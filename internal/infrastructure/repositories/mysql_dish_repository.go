@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"foodcook/internal/domain/models"
 	"foodcook/internal/domain/repositories"
@@ -18,15 +19,11 @@ func NewMySQLDishRepository(db *gorm.DB) repositories.DishRepository {
 	return &MySQLDishRepository{db: db}
 }
 
-func (r *MySQLDishRepository) List(ctx context.Context, offset, limit int, categoryID *uint) ([]*models.Dish, int64, error) {
+func (r *MySQLDishRepository) List(ctx context.Context, filter repositories.DishFilter, offset, limit int) ([]*models.Dish, int64, error) {
 	var dishes []*models.Dish
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&models.Dish{}).Preload("Category").Preload("Ingredients.Ingredient")
-
-	if categoryID != nil {
-		query = query.Where("category_id = ?", *categoryID)
-	}
+	query := r.applyFilter(r.db.WithContext(ctx).Model(&models.Dish{}).Preload("Category").Preload("Tags").Preload("Ingredients.Ingredient"), filter)
 
 	// 查询总数
 	if err := query.Count(&total).Error; err != nil {
@@ -34,7 +31,7 @@ func (r *MySQLDishRepository) List(ctx context.Context, offset, limit int, categ
 	}
 
 	// 查询菜品列表
-	result := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&dishes)
+	result := query.Offset(offset).Limit(limit).Order(dishOrder(filter.Sort)).Find(&dishes)
 	if result.Error != nil {
 		return nil, 0, fmt.Errorf("查询菜品列表失败: %w", result.Error)
 	}
@@ -42,9 +39,73 @@ func (r *MySQLDishRepository) List(ctx context.Context, offset, limit int, categ
 	return dishes, total, nil
 }
 
+// dishOrder 返回列表和搜索的排序子句，评分和次数相同时按创建时间倒序
+func dishOrder(sort string) string {
+	switch sort {
+	case repositories.DishSortRating:
+		return "(SELECT COALESCE(AVG(dish_ratings.stars), 0) FROM dish_ratings WHERE dish_ratings.dish_id = dishes.id) DESC, " +
+			"(SELECT COUNT(*) FROM dish_ratings WHERE dish_ratings.dish_id = dishes.id) DESC, dishes.created_at DESC"
+	case repositories.DishSortPopularity:
+		return "(SELECT COUNT(*) FROM meal_record_dishes JOIN meal_records ON meal_records.id = meal_record_dishes.meal_record_id " +
+			"AND meal_records.deleted_at IS NULL WHERE meal_record_dishes.dish_id = dishes.id) DESC, dishes.created_at DESC"
+	}
+	return "created_at DESC"
+}
+
+// applyFilter 添加列表和搜索共用的筛选条件
+func (r *MySQLDishRepository) applyFilter(query *gorm.DB, filter repositories.DishFilter) *gorm.DB {
+	if filter.CategoryID != nil {
+		if filter.IncludeDescendants {
+			// 分类数量很少，读出全部上下级关系后在内存中展开
+			var categories []*models.Category
+			if err := r.db.WithContext(query.Statement.Context).Select("id", "parent_id").Find(&categories).Error; err != nil {
+				query.AddError(fmt.Errorf("查询下级分类失败: %w", err))
+			}
+			query = query.Where("category_id IN ?", models.CategoryDescendantIDs(categories, *filter.CategoryID))
+		} else {
+			query = query.Where("category_id = ?", *filter.CategoryID)
+		}
+	}
+	if len(filter.ExcludeAllergens) > 0 {
+		// 过敏原以 JSON 数组保存在食材上，按带引号的标识匹配
+		conditions := make([]string, 0, len(filter.ExcludeAllergens))
+		args := make([]interface{}, 0, len(filter.ExcludeAllergens))
+		for _, allergen := range filter.ExcludeAllergens {
+			conditions = append(conditions, "ingredients.allergens LIKE ?")
+			args = append(args, `%"`+allergen+`"%`)
+		}
+		containing := r.db.Model(&models.DishIngredient{}).Select("dish_ingredients.dish_id").
+			Joins("JOIN ingredients ON ingredients.id = dish_ingredients.ingredient_id AND ingredients.deleted_at IS NULL").
+			Where("("+strings.Join(conditions, " OR ")+")", args...)
+		query = query.Where("id NOT IN (?)", containing)
+	}
+	for _, diet := range filter.Diets {
+		switch diet {
+		case models.DietVegetarian:
+			query = query.Where("vegetarian = ?", true)
+		case models.DietHalal:
+			query = query.Where("halal = ?", true)
+		}
+	}
+	if filter.MaxSpicyLevel != nil {
+		query = query.Where("spicy_level <= ?", *filter.MaxSpicyLevel)
+	}
+	if len(filter.TagIDs) > 0 {
+		tagged := r.db.Model(&models.DishTag{}).Select("dish_id").Where("tag_id IN ?", filter.TagIDs)
+		if filter.MatchAllTags {
+			tagged = tagged.Group("dish_id").Having("COUNT(DISTINCT tag_id) = ?", len(filter.TagIDs))
+		}
+		query = query.Where("id IN (?)", tagged)
+	}
+	return query
+}
+
 func (r *MySQLDishRepository) GetByID(ctx context.Context, id uint) (*models.Dish, error) {
 	var dish models.Dish
-	result := r.db.WithContext(ctx).Preload("Category").Preload("Ingredients.Ingredient").First(&dish, id)
+	result := r.db.WithContext(ctx).Preload("Category").Preload("Tags").Preload("Ingredients.Ingredient.Conversions").
+		Preload("Steps", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order ASC, id ASC")
+		}).First(&dish, id)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("菜品不存在")
@@ -62,7 +123,7 @@ func (r *MySQLDishRepository) Create(ctx context.Context, dish *models.Dish) err
 	return nil
 }
 
-func (r *MySQLDishRepository) CreateWithIngredients(ctx context.Context, dish *models.Dish, ingredients []repositories.DishIngredientRequest) error {
+func (r *MySQLDishRepository) CreateWithIngredients(ctx context.Context, dish *models.Dish, ingredients []repositories.DishIngredientRequest, steps []repositories.DishStepRequest) error {
 	// 开始事务
 	tx := r.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
@@ -75,7 +136,7 @@ func (r *MySQLDishRepository) CreateWithIngredients(ctx context.Context, dish *m
 	}()
 
 	// 创建菜品
-	if err := tx.Create(dish).Error; err != nil {
+	if err := tx.Omit("Steps", "Tags").Create(dish).Error; err != nil {
 		tx.Rollback()
 		return fmt.Errorf("创建菜品失败: %w", err)
 	}
@@ -93,12 +154,56 @@ func (r *MySQLDishRepository) CreateWithIngredients(ctx context.Context, dish *m
 		}
 	}
 
+	// 创建菜谱步骤
+	if err := createSteps(tx, dish, steps); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 创建标签关联
+	if err := replaceTags(tx, dish); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// 提交事务
 	return tx.Commit().Error
 }
 
+// createSteps 按顺序创建菜谱步骤并填充到 dish.Steps
+func createSteps(tx *gorm.DB, dish *models.Dish, steps []repositories.DishStepRequest) error {
+	dish.Steps = nil
+	for i, step := range steps {
+		dishStep := models.DishStep{
+			DishID:       dish.ID,
+			SortOrder:    i,
+			Content:      step.Content,
+			TimerSeconds: step.TimerSeconds,
+			ImageURL:     step.ImageURL,
+		}
+		if err := tx.Create(&dishStep).Error; err != nil {
+			return fmt.Errorf("创建菜谱步骤失败: %w", err)
+		}
+		dish.Steps = append(dish.Steps, dishStep)
+	}
+	return nil
+}
+
+// replaceTags 把菜品的标签关联替换为 dish.Tags
+func replaceTags(tx *gorm.DB, dish *models.Dish) error {
+	if err := tx.Where("dish_id = ?", dish.ID).Delete(&models.DishTag{}).Error; err != nil {
+		return fmt.Errorf("删除旧标签关联失败: %w", err)
+	}
+	for _, tag := range dish.Tags {
+		if err := tx.Create(&models.DishTag{DishID: dish.ID, TagID: tag.ID}).Error; err != nil {
+			return fmt.Errorf("创建标签关联失败: %w", err)
+		}
+	}
+	return nil
+}
+
 func (r *MySQLDishRepository) Update(ctx context.Context, dish *models.Dish) error {
-	result := r.db.WithContext(ctx).Save(dish)
+	result := r.db.WithContext(ctx).Omit("Tags").Save(dish)
 	if result.Error != nil {
 		return fmt.Errorf("更新菜品失败: %w", result.Error)
 	}
@@ -108,7 +213,7 @@ func (r *MySQLDishRepository) Update(ctx context.Context, dish *models.Dish) err
 	return nil
 }
 
-func (r *MySQLDishRepository) UpdateWithIngredients(ctx context.Context, dish *models.Dish, ingredients []repositories.DishIngredientRequest) error {
+func (r *MySQLDishRepository) UpdateWithIngredients(ctx context.Context, dish *models.Dish, ingredients []repositories.DishIngredientRequest, steps []repositories.DishStepRequest) error {
 	// 开始事务
 	tx := r.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
@@ -120,31 +225,51 @@ func (r *MySQLDishRepository) UpdateWithIngredients(ctx context.Context, dish *m
 		}
 	}()
 
-	// 更新菜品
-	if err := tx.Save(dish).Error; err != nil {
+	// 更新菜品，食材关联和步骤在下面单独替换
+	if err := tx.Omit("Ingredients", "Steps", "Tags").Save(dish).Error; err != nil {
 		tx.Rollback()
 		return fmt.Errorf("更新菜品失败: %w", err)
 	}
 
-	// 删除旧的食材关联
-	if err := tx.Where("dish_id = ?", dish.ID).Delete(&models.DishIngredient{}).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("删除旧食材关联失败: %w", err)
+	if ingredients != nil {
+		// 删除旧的食材关联
+		if err := tx.Where("dish_id = ?", dish.ID).Delete(&models.DishIngredient{}).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("删除旧食材关联失败: %w", err)
+		}
+
+		// 创建新的食材关联
+		for _, ingredient := range ingredients {
+			dishIngredient := &models.DishIngredient{
+				DishID:       dish.ID,
+				IngredientID: ingredient.IngredientID,
+				Quantity:     ingredient.Quantity,
+			}
+			if err := tx.Create(dishIngredient).Error; err != nil {
+				tx.Rollback()
+				return fmt.Errorf("创建食材关联失败: %w", err)
+			}
+		}
 	}
 
-	// 创建新的食材关联
-	for _, ingredient := range ingredients {
-		dishIngredient := &models.DishIngredient{
-			DishID:       dish.ID,
-			IngredientID: ingredient.IngredientID,
-			Quantity:     ingredient.Quantity,
+	// 替换菜谱步骤
+	if steps != nil {
+		if err := tx.Where("dish_id = ?", dish.ID).Delete(&models.DishStep{}).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("删除旧菜谱步骤失败: %w", err)
 		}
-		if err := tx.Create(dishIngredient).Error; err != nil {
+		if err := createSteps(tx, dish, steps); err != nil {
 			tx.Rollback()
-			return fmt.Errorf("创建食材关联失败: %w", err)
+			return err
 		}
 	}
 
+	// 替换标签关联
+	if err := replaceTags(tx, dish); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// 提交事务
 	return tx.Commit().Error
 }
@@ -169,12 +294,13 @@ func (r *MySQLDishRepository) GetByCategory(ctx context.Context, categoryID uint
 	return dishes, nil
 }
 
-func (r *MySQLDishRepository) Search(ctx context.Context, keyword string, offset, limit int) ([]*models.Dish, int64, error) {
+func (r *MySQLDishRepository) Search(ctx context.Context, keyword string, filter repositories.DishFilter, offset, limit int) ([]*models.Dish, int64, error) {
 	var dishes []*models.Dish
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&models.Dish{}).Preload("Category").
-		Where("name LIKE ? OR description LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	query := r.db.WithContext(ctx).Model(&models.Dish{}).Preload("Category").Preload("Tags").Preload("Ingredients.Ingredient").
+		Where("(name LIKE ? OR description LIKE ?)", "%"+keyword+"%", "%"+keyword+"%")
+	query = r.applyFilter(query, filter)
 
 	// 查询总数
 	if err := query.Count(&total).Error; err != nil {
@@ -182,7 +308,7 @@ func (r *MySQLDishRepository) Search(ctx context.Context, keyword string, offset
 	}
 
 	// 查询搜索结果
-	result := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&dishes)
+	result := query.Offset(offset).Limit(limit).Order(dishOrder(filter.Sort)).Find(&dishes)
 	if result.Error != nil {
 		return nil, 0, fmt.Errorf("搜索菜品失败: %w", result.Error)
 	}
@@ -202,3 +328,17 @@ func (r *MySQLDishRepository) IsUsedInMealRecords(ctx context.Context, dishID ui
 	}
 	return count > 0, nil
 }
+
+func (r *MySQLDishRepository) RecalculateAutoPrices(ctx context.Context, ingredientID uint) (int64, error) {
+	cost := gorm.Expr("(SELECT COALESCE(ROUND(SUM(di.quantity * i.price), 2), 0) FROM dish_ingredients di " +
+		"JOIN ingredients i ON i.id = di.ingredient_id AND i.deleted_at IS NULL WHERE di.dish_id = dishes.id)")
+	using := r.db.Model(&models.DishIngredient{}).Select("dish_id").Where("ingredient_id = ?", ingredientID)
+
+	result := r.db.WithContext(ctx).Model(&models.Dish{}).
+		Where("auto_price = ? AND id IN (?)", true, using).
+		Update("price", cost)
+	if result.Error != nil {
+		return 0, fmt.Errorf("重新计算菜品价格失败: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
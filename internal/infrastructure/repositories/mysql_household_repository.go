@@ -0,0 +1,273 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MySQLHouseholdRepository struct {
+	db *gorm.DB
+}
+
+func NewMySQLHouseholdRepository(db *gorm.DB) repositories.HouseholdRepository {
+	return &MySQLHouseholdRepository{db: db}
+}
+
+func (r *MySQLHouseholdRepository) Create(ctx context.Context, household *models.Household) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("开始事务失败: %w", tx.Error)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := createHousehold(tx, household); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("创建家庭失败: %w", err)
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+// createHousehold 在事务中创建家庭和所有者成员
+func createHousehold(tx *gorm.DB, household *models.Household) error {
+	household.Members = nil
+	if err := tx.Create(household).Error; err != nil {
+		return err
+	}
+	owner := &models.HouseholdMember{
+		HouseholdID: household.ID,
+		UserID:      household.OwnerID,
+		Role:        models.HouseholdRoleOwner,
+	}
+	return tx.Create(owner).Error
+}
+
+func (r *MySQLHouseholdRepository) GetByID(ctx context.Context, id uint) (*models.Household, error) {
+	var household models.Household
+	result := r.db.WithContext(ctx).Preload("Members.User").First(&household, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("家庭不存在")
+		}
+		return nil, fmt.Errorf("查询家庭失败: %w", result.Error)
+	}
+	return &household, nil
+}
+
+func (r *MySQLHouseholdRepository) Update(ctx context.Context, household *models.Household) error {
+	result := r.db.WithContext(ctx).Omit("Members").Save(household)
+	if result.Error != nil {
+		return fmt.Errorf("更新家庭失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLHouseholdRepository) Delete(ctx context.Context, id uint) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("开始事务失败: %w", tx.Error)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result := tx.Delete(&models.Household{}, id)
+	if result.Error != nil {
+		tx.Rollback()
+		return fmt.Errorf("删除家庭失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return fmt.Errorf("家庭不存在")
+	}
+
+	// 删除成员和邀请码
+	if err := tx.Where("household_id = ?", id).Delete(&models.HouseholdMember{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("删除家庭成员失败: %w", err)
+	}
+	if err := tx.Where("household_id = ?", id).Delete(&models.HouseholdInvitation{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("删除邀请码失败: %w", err)
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+func (r *MySQLHouseholdRepository) EnsurePersonal(ctx context.Context, userID uint, name string) (*models.Household, error) {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", tx.Error)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var household models.Household
+	result := tx.Where("owner_id = ? AND is_personal = ?", userID, true).First(&household)
+	if result.Error == gorm.ErrRecordNotFound {
+		household = models.Household{Name: name, OwnerID: userID, IsPersonal: true}
+		if err := createHousehold(tx, &household); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("创建个人家庭失败: %w", err)
+		}
+	} else if result.Error != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("查询个人家庭失败: %w", result.Error)
+	}
+
+	// 迁移尚未归属家庭的用餐记录
+	err := tx.Model(&models.MealRecord{}).
+		Where("user_id = ? AND household_id = 0", userID).
+		Update("household_id", household.ID).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("迁移用餐记录失败: %w", err)
+	}
+
+	// 提交事务
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &household, nil
+}
+
+func (r *MySQLHouseholdRepository) ListMemberships(ctx context.Context, userID uint) ([]*models.HouseholdMember, error) {
+	var members []*models.HouseholdMember
+	err := r.db.WithContext(ctx).
+		Joins("Household").
+		Where("household_members.user_id = ?", userID).
+		Order("household_members.created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询家庭列表失败: %w", err)
+	}
+	return members, nil
+}
+
+func (r *MySQLHouseholdRepository) GetMember(ctx context.Context, householdID, userID uint) (*models.HouseholdMember, error) {
+	var member models.HouseholdMember
+	result := r.db.WithContext(ctx).
+		Joins("Household").
+		Where("household_members.household_id = ? AND household_members.user_id = ?", householdID, userID).
+		First(&member)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("不是该家庭成员")
+		}
+		return nil, fmt.Errorf("查询家庭成员失败: %w", result.Error)
+	}
+	return &member, nil
+}
+
+func (r *MySQLHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID, userID uint, role string) error {
+	result := r.db.WithContext(ctx).Model(&models.HouseholdMember{}).
+		Where("household_id = ? AND user_id = ?", householdID, userID).
+		Update("role", role)
+	if result.Error != nil {
+		return fmt.Errorf("更新成员角色失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("成员不存在")
+	}
+	return nil
+}
+
+func (r *MySQLHouseholdRepository) RemoveMember(ctx context.Context, householdID, userID uint) error {
+	result := r.db.WithContext(ctx).
+		Where("household_id = ? AND user_id = ?", householdID, userID).
+		Delete(&models.HouseholdMember{})
+	if result.Error != nil {
+		return fmt.Errorf("移除成员失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("成员不存在")
+	}
+	return nil
+}
+
+func (r *MySQLHouseholdRepository) CreateInvitation(ctx context.Context, invitation *models.HouseholdInvitation) error {
+	result := r.db.WithContext(ctx).Create(invitation)
+	if result.Error != nil {
+		return fmt.Errorf("创建邀请码失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*models.HouseholdInvitation, error) {
+	var invitation models.HouseholdInvitation
+	result := r.db.WithContext(ctx).Joins("Household").Where("household_invitations.code = ?", code).First(&invitation)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("邀请码不存在")
+		}
+		return nil, fmt.Errorf("查询邀请码失败: %w", result.Error)
+	}
+	return &invitation, nil
+}
+
+func (r *MySQLHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID, userID uint) (*models.HouseholdMember, error) {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", tx.Error)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var invitation models.HouseholdInvitation
+	if err := tx.First(&invitation, invitationID).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("查询邀请码失败: %w", err)
+	}
+
+	// 条件更新防止并发使用超过次数
+	result := tx.Model(&models.HouseholdInvitation{}).
+		Where("id = ? AND used_count < max_uses", invitationID).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("使用邀请码失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return nil, fmt.Errorf("邀请码已用完")
+	}
+
+	member := &models.HouseholdMember{
+		HouseholdID: invitation.HouseholdID,
+		UserID:      userID,
+		Role:        invitation.Role,
+	}
+	if err := tx.Create(member).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("加入家庭失败: %w", err)
+	}
+
+	// 提交事务
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return member, nil
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"foodcook/internal/domain/models"
 	"foodcook/internal/domain/repositories"
@@ -18,20 +19,132 @@ func NewMySQLIngredientRepository(db *gorm.DB) repositories.IngredientRepository
 }
 
 func (r *MySQLIngredientRepository) Create(ctx context.Context, ingredient *models.Ingredient) error {
-	return r.db.WithContext(ctx).Create(ingredient).Error
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Create(ingredient).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := recordPrice(tx, ingredient); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// recordPrice 追加一条价格记录
+func recordPrice(tx *gorm.DB, ingredient *models.Ingredient) error {
+	return tx.Create(&models.IngredientPriceHistory{
+		IngredientID: ingredient.ID,
+		Price:        ingredient.Price,
+		Unit:         ingredient.Unit,
+	}).Error
 }
 
 func (r *MySQLIngredientRepository) GetByID(ctx context.Context, id uint) (*models.Ingredient, error) {
 	var ingredient models.Ingredient
-	err := r.db.WithContext(ctx).First(&ingredient, id).Error
+	err := r.db.WithContext(ctx).Preload("Conversions").First(&ingredient, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &ingredient, nil
 }
 
-func (r *MySQLIngredientRepository) Update(ctx context.Context, ingredient *models.Ingredient) error {
-	return r.db.WithContext(ctx).Save(ingredient).Error
+func (r *MySQLIngredientRepository) Update(ctx context.Context, ingredient *models.Ingredient, conversions []models.IngredientUnitConversion) error {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := saveIngredient(tx, ingredient, conversions); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *MySQLIngredientRepository) ChangeUnit(ctx context.Context, ingredient *models.Ingredient, factor float64, conversions []models.IngredientUnitConversion) error {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := saveIngredient(tx, ingredient, conversions); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	quantity := gorm.Expr("ROUND(quantity * ?, 4)", factor)
+	if err := tx.Model(&models.DishIngredient{}).Where("ingredient_id = ?", ingredient.ID).Update("quantity", quantity).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	err := tx.Model(&models.PantryItem{}).Where("ingredient_id = ?", ingredient.ID).
+		Updates(map[string]interface{}{"quantity": quantity, "unit": ingredient.Unit}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// saveIngredient 在事务中保存食材，价格或单位变化时追加一条价格记录，conversions 不为 nil 时替换换算关系
+func saveIngredient(tx *gorm.DB, ingredient *models.Ingredient, conversions []models.IngredientUnitConversion) error {
+	var current models.Ingredient
+	if err := tx.Select("id", "price", "unit").First(&current, ingredient.ID).Error; err != nil {
+		return err
+	}
+
+	// 换算关系单独替换
+	if err := tx.Omit("Conversions").Save(ingredient).Error; err != nil {
+		return err
+	}
+	if conversions != nil {
+		if err := replaceConversions(tx, ingredient.ID, conversions); err != nil {
+			return err
+		}
+	}
+
+	if models.RoundPrice(current.Price) != models.RoundPrice(ingredient.Price) || current.Unit != ingredient.Unit {
+		return recordPrice(tx, ingredient)
+	}
+	return nil
+}
+
+// replaceConversions 用新的换算关系替换食材原有的换算关系
+func replaceConversions(tx *gorm.DB, ingredientID uint, conversions []models.IngredientUnitConversion) error {
+	if err := tx.Where("ingredient_id = ?", ingredientID).Delete(&models.IngredientUnitConversion{}).Error; err != nil {
+		return err
+	}
+	for i := range conversions {
+		conversions[i].ID = 0
+		conversions[i].IngredientID = ingredientID
+		if err := tx.Create(&conversions[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *MySQLIngredientRepository) Delete(ctx context.Context, id uint) error {
@@ -48,7 +161,7 @@ func (r *MySQLIngredientRepository) List(ctx context.Context, offset, limit int)
 	}
 
 	// 获取分页数据
-	if err := r.db.WithContext(ctx).Offset(offset).Limit(limit).Find(&ingredients).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Conversions").Offset(offset).Limit(limit).Find(&ingredients).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -63,3 +176,56 @@ func (r *MySQLIngredientRepository) IsUsedInDishes(ctx context.Context, ingredie
 	}
 	return count > 0, nil
 }
+
+func (r *MySQLIngredientRepository) IsUsedInPantry(ctx context.Context, ingredientID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.PantryItem{}).Where("ingredient_id = ?", ingredientID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *MySQLIngredientRepository) SetNutrition(ctx context.Context, nutrition map[uint]models.Nutrition) error {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for id, n := range nutrition {
+		err := tx.Model(&models.Ingredient{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"nutrition_calories": n.Calories,
+			"nutrition_protein":  n.Protein,
+			"nutrition_fat":      n.Fat,
+			"nutrition_carbs":    n.Carbs,
+			"nutrition_fiber":    n.Fiber,
+			"nutrition_sodium":   n.Sodium,
+		}).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *MySQLIngredientRepository) PriceHistory(ctx context.Context, ingredientID uint, from, to *time.Time) ([]*models.IngredientPriceHistory, error) {
+	var history []*models.IngredientPriceHistory
+	query := r.db.WithContext(ctx).Where("ingredient_id = ?", ingredientID)
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+	if err := query.Order("created_at ASC, id ASC").Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
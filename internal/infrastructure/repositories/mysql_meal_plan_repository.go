@@ -0,0 +1,264 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MySQLMealPlanRepository struct {
+	db *gorm.DB
+}
+
+func NewMySQLMealPlanRepository(db *gorm.DB) repositories.MealPlanRepository {
+	return &MySQLMealPlanRepository{db: db}
+}
+
+// mealTypeOrder 同一天内餐次的排列顺序
+var mealTypeOrder = map[string]int{
+	models.MealTypeBreakfast: 0,
+	models.MealTypeLunch:     1,
+	models.MealTypeSnack:     2,
+	models.MealTypeDinner:    3,
+	models.MealTypeCustom:    4,
+}
+
+// planSlotLess 餐次按日期和餐次顺序排列
+func planSlotLess(a, b *models.MealPlanSlot) bool {
+	if a.Date != b.Date {
+		return a.Date < b.Date
+	}
+	if mealTypeOrder[a.MealType] != mealTypeOrder[b.MealType] {
+		return mealTypeOrder[a.MealType] < mealTypeOrder[b.MealType]
+	}
+	return a.ID < b.ID
+}
+
+func (r *MySQLMealPlanRepository) Create(ctx context.Context, plan *models.MealPlan) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("开始事务失败: %w", tx.Error)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	slots := plan.Slots
+	plan.Slots = nil
+	if err := tx.Create(plan).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("创建用餐计划失败: %w", err)
+	}
+
+	for i := range slots {
+		slots[i].MealPlanID = plan.ID
+		if err := createSlot(tx, &slots[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("创建计划餐次失败: %w", err)
+		}
+	}
+	plan.Slots = slots
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+// createSlot 在事务中创建餐次及其菜品
+func createSlot(tx *gorm.DB, slot *models.MealPlanSlot) error {
+	dishes := slot.Dishes
+	slot.Dishes = nil
+	if err := tx.Create(slot).Error; err != nil {
+		return err
+	}
+	for i := range dishes {
+		dishes[i].ID = 0
+		dishes[i].SlotID = slot.ID
+		if err := tx.Omit("Dish").Create(&dishes[i]).Error; err != nil {
+			return err
+		}
+	}
+	slot.Dishes = dishes
+	return nil
+}
+
+func (r *MySQLMealPlanRepository) GetByID(ctx context.Context, id uint) (*models.MealPlan, error) {
+	var plan models.MealPlan
+	result := r.db.WithContext(ctx).Preload("Slots.Dishes.Dish").First(&plan, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("用餐计划不存在")
+		}
+		return nil, fmt.Errorf("查询用餐计划失败: %w", result.Error)
+	}
+	sort.Slice(plan.Slots, func(i, j int) bool { return planSlotLess(&plan.Slots[i], &plan.Slots[j]) })
+	return &plan, nil
+}
+
+func (r *MySQLMealPlanRepository) Update(ctx context.Context, plan *models.MealPlan) error {
+	result := r.db.WithContext(ctx).Omit("Slots").Save(plan)
+	if result.Error != nil {
+		return fmt.Errorf("更新用餐计划失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLMealPlanRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.MealPlan{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除用餐计划失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLMealPlanRepository) List(ctx context.Context, householdID uint, from, to string, offset, limit int) ([]*models.MealPlan, int64, error) {
+	var plans []*models.MealPlan
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.MealPlan{}).Where("household_id = ?", householdID)
+	if from != "" {
+		query = query.Where("end_date >= ?", from)
+	}
+	if to != "" {
+		query = query.Where("start_date <= ?", to)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计用餐计划失败: %w", err)
+	}
+
+	if err := query.Offset(offset).Limit(limit).Order("start_date DESC, id DESC").Find(&plans).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询用餐计划失败: %w", err)
+	}
+	return plans, total, nil
+}
+
+func (r *MySQLMealPlanRepository) CreateSlots(ctx context.Context, slots []*models.MealPlanSlot) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("开始事务失败: %w", tx.Error)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, slot := range slots {
+		if err := createSlot(tx, slot); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("创建计划餐次失败: %w", err)
+		}
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+func (r *MySQLMealPlanRepository) GetSlot(ctx context.Context, id uint) (*models.MealPlanSlot, error) {
+	var slot models.MealPlanSlot
+	result := r.db.WithContext(ctx).Preload("Dishes.Dish").First(&slot, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("计划餐次不存在")
+		}
+		return nil, fmt.Errorf("查询计划餐次失败: %w", result.Error)
+	}
+	return &slot, nil
+}
+
+func (r *MySQLMealPlanRepository) UpdateSlot(ctx context.Context, slot *models.MealPlanSlot) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("开始事务失败: %w", tx.Error)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Omit("Dishes").Save(slot).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("更新计划餐次失败: %w", err)
+	}
+
+	// 替换菜品
+	if err := tx.Where("slot_id = ?", slot.ID).Delete(&models.MealPlanSlotDish{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("删除餐次菜品失败: %w", err)
+	}
+	for i := range slot.Dishes {
+		dish := &slot.Dishes[i]
+		dish.ID = 0
+		dish.SlotID = slot.ID
+		if err := tx.Omit("Dish").Create(dish).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("创建餐次菜品失败: %w", err)
+		}
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+func (r *MySQLMealPlanRepository) DeleteSlot(ctx context.Context, id uint) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("开始事务失败: %w", tx.Error)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("slot_id = ?", id).Delete(&models.MealPlanSlotDish{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("删除餐次菜品失败: %w", err)
+	}
+	if err := tx.Delete(&models.MealPlanSlot{}, id).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("删除计划餐次失败: %w", err)
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+func (r *MySQLMealPlanRepository) ListSlots(ctx context.Context, householdID uint, from, to string) ([]*models.MealPlanSlot, error) {
+	var slots []*models.MealPlanSlot
+	plans := r.db.Model(&models.MealPlan{}).Select("id").Where("household_id = ?", householdID)
+	err := r.db.WithContext(ctx).Preload("Dishes.Dish").
+		Where("meal_plan_id IN (?) AND date >= ? AND date <= ?", plans, from, to).
+		Find(&slots).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询计划餐次失败: %w", err)
+	}
+	sort.Slice(slots, func(i, j int) bool { return planSlotLess(slots[i], slots[j]) })
+	return slots, nil
+}
+
+func (r *MySQLMealPlanRepository) MarkSlotEaten(ctx context.Context, slotID, mealRecordID uint) error {
+	// 只更新尚未标记的餐次，避免并发请求重复生成记录
+	result := r.db.WithContext(ctx).Model(&models.MealPlanSlot{}).
+		Where("id = ? AND meal_record_id IS NULL", slotID).
+		Update("meal_record_id", mealRecordID)
+	if result.Error != nil {
+		return fmt.Errorf("标记餐次失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrSlotEaten
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"fmt"
 
 	"foodcook/internal/domain/models"
 	"foodcook/internal/domain/repositories"
@@ -17,7 +18,7 @@ func NewMySQLMealRecordRepository(db *gorm.DB) repositories.MealRecordRepository
 	return &MySQLMealRecordRepository{db: db}
 }
 
-func (r *MySQLMealRecordRepository) Create(ctx context.Context, mealRecord *models.MealRecord, dishIDs []uint) error {
+func (r *MySQLMealRecordRepository) Create(ctx context.Context, mealRecord *models.MealRecord, dishes []repositories.MealRecordDishRequest) error {
 	// 开始事务
 	tx := r.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
@@ -29,57 +30,242 @@ func (r *MySQLMealRecordRepository) Create(ctx context.Context, mealRecord *mode
 		}
 	}()
 
+	if err := createMealRecord(tx, mealRecord, dishes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+func (r *MySQLMealRecordRepository) CreateWithPantry(ctx context.Context, mealRecord *models.MealRecord, dishes []repositories.MealRecordDishRequest, usages []repositories.PantryUsage) ([]repositories.PantryUsage, error) {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := createMealRecord(tx, mealRecord, dishes); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 扣减库存
+	result, err := consumePantry(tx, mealRecord.HouseholdID, usages)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 提交事务
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// createMealRecord 在事务中创建用餐记录和菜品关联
+func createMealRecord(tx *gorm.DB, mealRecord *models.MealRecord, dishes []repositories.MealRecordDishRequest) error {
 	// 创建用餐记录
 	if err := tx.Create(mealRecord).Error; err != nil {
-		tx.Rollback()
 		return err
 	}
 
 	// 创建菜品关联
-	for _, dishID := range dishIDs {
+	for _, item := range dishes {
 		mealRecordDish := &models.MealRecordDish{
 			MealRecordID: mealRecord.ID,
-			DishID:       dishID,
-			Quantity:     1, // 默认数量为1
+			DishID:       item.DishID,
+			Quantity:     item.Quantity,
+			UnitPrice:    item.UnitPrice,
 		}
 		if err := tx.Create(mealRecordDish).Error; err != nil {
-			tx.Rollback()
 			return err
 		}
 	}
-
-	// 提交事务
-	return tx.Commit().Error
+	return nil
 }
 
 func (r *MySQLMealRecordRepository) GetByID(ctx context.Context, id uint) (*models.MealRecord, error) {
 	var mealRecord models.MealRecord
-	err := r.db.WithContext(ctx).Preload("Dishes.Dish").First(&mealRecord, id).Error
+	err := r.db.WithContext(ctx).Preload("User").Preload("Dishes.Dish").Preload("Photos", orderPhotos).Preload("Participants.User").First(&mealRecord, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &mealRecord, nil
 }
 
-func (r *MySQLMealRecordRepository) Update(ctx context.Context, mealRecord *models.MealRecord) error {
-	return r.db.WithContext(ctx).Save(mealRecord).Error
+func (r *MySQLMealRecordRepository) UpdateWithDishes(ctx context.Context, mealRecord *models.MealRecord, dishes []repositories.MealRecordDishRequest, participants []models.MealRecordParticipant) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 更新用餐记录本身，菜品行和参与者单独处理
+	if err := tx.Omit("User", "Dishes", "Comments", "Photos", "Participants").Save(mealRecord).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if dishes != nil {
+		if err := replaceMealRecordDishes(tx, mealRecord.ID, dishes); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if participants != nil {
+		if err := replaceMealRecordParticipants(tx, mealRecord.ID, participants); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+// replaceMealRecordDishes 替换记录的菜品行，已有菜品的行保留原ID
+func replaceMealRecordDishes(tx *gorm.DB, mealRecordID uint, dishes []repositories.MealRecordDishRequest) error {
+	var existing []models.MealRecordDish
+	if err := tx.Where("meal_record_id = ?", mealRecordID).Find(&existing).Error; err != nil {
+		return err
+	}
+	byDish := make(map[uint]models.MealRecordDish, len(existing))
+	for _, item := range existing {
+		byDish[item.DishID] = item
+	}
+
+	for _, item := range dishes {
+		if current, ok := byDish[item.DishID]; ok {
+			delete(byDish, item.DishID)
+			err := tx.Model(&models.MealRecordDish{}).Where("id = ?", current.ID).
+				Updates(map[string]interface{}{"quantity": item.Quantity, "unit_price": item.UnitPrice}).Error
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		mealRecordDish := &models.MealRecordDish{
+			MealRecordID: mealRecordID,
+			DishID:       item.DishID,
+			Quantity:     item.Quantity,
+			UnitPrice:    item.UnitPrice,
+		}
+		if err := tx.Create(mealRecordDish).Error; err != nil {
+			return err
+		}
+	}
+
+	// 删除不再包含的菜品行，关联到这些行的照片解除关联，对这些行的评分一并删除
+	for _, removed := range byDish {
+		if err := tx.Model(&models.MealRecordPhoto{}).Where("meal_record_dish_id = ?", removed.ID).
+			Update("meal_record_dish_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("meal_record_dish_id = ?", removed.ID).Delete(&models.DishRating{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.MealRecordDish{}, removed.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceMealRecordParticipants 删除记录原有的参与者后逐个创建
+func replaceMealRecordParticipants(tx *gorm.DB, mealRecordID uint, participants []models.MealRecordParticipant) error {
+	if err := tx.Where("meal_record_id = ?", mealRecordID).Delete(&models.MealRecordParticipant{}).Error; err != nil {
+		return err
+	}
+
+	for i := range participants {
+		participant := &participants[i]
+		participant.ID = 0
+		participant.MealRecordID = mealRecordID
+		if err := tx.Omit("User").Create(participant).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *MySQLMealRecordRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&models.MealRecord{}, id).Error
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 记录删除后无法再按用餐记录删除评分，对其菜品行的评分一并删除
+	lines := tx.Model(&models.MealRecordDish{}).Select("id").Where("meal_record_id = ?", id)
+	if err := tx.Where("meal_record_dish_id IN (?)", lines).Delete(&models.DishRating{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Delete(&models.MealRecord{}, id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 提交事务
+	return tx.Commit().Error
 }
 
-func (r *MySQLMealRecordRepository) List(ctx context.Context, userID uint, offset, limit int) ([]*models.MealRecord, int64, error) {
+func (r *MySQLMealRecordRepository) List(ctx context.Context, householdID uint, filter repositories.MealRecordFilter, offset, limit int) ([]*models.MealRecord, int64, error) {
 	var mealRecords []*models.MealRecord
 	var total int64
 
+	query := r.db.WithContext(ctx).Model(&models.MealRecord{}).Where("household_id = ?", householdID)
+	if filter.From != nil {
+		query = query.Where("eaten_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("eaten_at < ?", *filter.To)
+	}
+	if filter.MealType != "" {
+		query = query.Where("meal_type = ?", filter.MealType)
+	}
+	if filter.ParticipantID != 0 {
+		query = query.Where("id IN (?)", r.db.Model(&models.MealRecordParticipant{}).
+			Select("meal_record_id").Where("user_id = ?", filter.ParticipantID))
+	}
+	if filter.Guest != "" {
+		query = query.Where("id IN (?)", r.db.Model(&models.MealRecordParticipant{}).
+			Select("meal_record_id").Where("guest_name = ?", filter.Guest))
+	}
+
 	// 获取总数
-	if err := r.db.WithContext(ctx).Model(&models.MealRecord{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// 获取分页数据，包含菜品信息
-	if err := r.db.WithContext(ctx).Preload("Dishes.Dish").Where("user_id = ?", userID).Offset(offset).Limit(limit).Order("created_at DESC").Find(&mealRecords).Error; err != nil {
+	order := "eaten_at DESC, id DESC"
+	if filter.Ascending {
+		order = "eaten_at ASC, id ASC"
+	}
+
+	// 获取分页数据，包含菜品和记录人信息
+	if err := query.Preload("User").Preload("Dishes.Dish").Preload("Photos", orderPhotos).Preload("Participants.User").
+		Offset(offset).Limit(limit).Order(order).Find(&mealRecords).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -88,9 +274,196 @@ func (r *MySQLMealRecordRepository) List(ctx context.Context, userID uint, offse
 
 func (r *MySQLMealRecordRepository) GetByUser(ctx context.Context, userID uint) ([]*models.MealRecord, error) {
 	var mealRecords []*models.MealRecord
-	err := r.db.WithContext(ctx).Preload("Dishes.Dish").Where("user_id = ?", userID).Order("created_at DESC").Find(&mealRecords).Error
+	err := r.db.WithContext(ctx).Preload("Dishes.Dish").Preload("Photos", orderPhotos).Preload("Participants.User").Where("user_id = ?", userID).Order("eaten_at DESC, id DESC").Find(&mealRecords).Error
 	if err != nil {
 		return nil, err
 	}
 	return mealRecords, nil
 }
+
+func (r *MySQLMealRecordRepository) AddComment(ctx context.Context, comment *models.MealRecordComment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+func (r *MySQLMealRecordRepository) GetComment(ctx context.Context, id uint) (*models.MealRecordComment, error) {
+	var comment models.MealRecordComment
+	err := r.db.WithContext(ctx).Preload("User").First(&comment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *MySQLMealRecordRepository) ListComments(ctx context.Context, mealRecordID uint) ([]*models.MealRecordComment, error) {
+	var comments []*models.MealRecordComment
+	err := r.db.WithContext(ctx).Preload("User").Where("meal_record_id = ?", mealRecordID).Order("created_at ASC").Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *MySQLMealRecordRepository) DeleteComment(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.MealRecordComment{}, id).Error
+}
+
+// orderPhotos 照片按排序值升序预加载
+func orderPhotos(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC, id ASC")
+}
+
+func (r *MySQLMealRecordRepository) AddPhoto(ctx context.Context, photo *models.MealRecordPhoto) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var maxOrder *int
+	if err := tx.Model(&models.MealRecordPhoto{}).Where("meal_record_id = ?", photo.MealRecordID).
+		Select("MAX(sort_order)").Scan(&maxOrder).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	photo.SortOrder = 0
+	if maxOrder != nil {
+		photo.SortOrder = *maxOrder + 1
+	}
+
+	if err := tx.Create(photo).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+func (r *MySQLMealRecordRepository) GetPhoto(ctx context.Context, id uint) (*models.MealRecordPhoto, error) {
+	var photo models.MealRecordPhoto
+	err := r.db.WithContext(ctx).First(&photo, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
+
+func (r *MySQLMealRecordRepository) UpdatePhoto(ctx context.Context, photo *models.MealRecordPhoto) error {
+	return r.db.WithContext(ctx).Save(photo).Error
+}
+
+func (r *MySQLMealRecordRepository) DeletePhoto(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.MealRecordPhoto{}, id).Error
+}
+
+func (r *MySQLMealRecordRepository) ReorderPhotos(ctx context.Context, mealRecordID uint, photoIDs []uint) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var existing []uint
+	if err := tx.Model(&models.MealRecordPhoto{}).Where("meal_record_id = ?", mealRecordID).Pluck("id", &existing).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := checkPhotoOrder(existing, photoIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for i, photoID := range photoIDs {
+		if err := tx.Model(&models.MealRecordPhoto{}).Where("id = ?", photoID).Update("sort_order", i).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+// checkPhotoOrder 检查新顺序是否恰好包含记录的全部照片
+func checkPhotoOrder(existing, photoIDs []uint) error {
+	if len(existing) != len(photoIDs) {
+		return fmt.Errorf("照片数量不匹配")
+	}
+	remaining := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		remaining[id] = true
+	}
+	for _, id := range photoIDs {
+		if !remaining[id] {
+			return fmt.Errorf("照片 %d 不属于该记录或重复", id)
+		}
+		delete(remaining, id)
+	}
+	return nil
+}
+
+func (r *MySQLMealRecordRepository) MigrateImageURLs(ctx context.Context) (int, error) {
+	var mealRecords []*models.MealRecord
+	if err := r.db.WithContext(ctx).Unscoped().Where("image_url IS NOT NULL AND image_url <> ''").Find(&mealRecords).Error; err != nil {
+		return 0, err
+	}
+
+	for _, mealRecord := range mealRecords {
+		if err := r.migrateImageURL(ctx, mealRecord); err != nil {
+			return 0, fmt.Errorf("迁移用餐记录 %d 的图片失败: %w", mealRecord.ID, err)
+		}
+	}
+	return len(mealRecords), nil
+}
+
+// migrateImageURL 在事务中把一条记录的 ImageURL 插入为第一张照片并清空原字段
+func (r *MySQLMealRecordRepository) migrateImageURL(ctx context.Context, mealRecord *models.MealRecord) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var minOrder *int
+	if err := tx.Model(&models.MealRecordPhoto{}).Where("meal_record_id = ?", mealRecord.ID).
+		Select("MIN(sort_order)").Scan(&minOrder).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	photo := &models.MealRecordPhoto{
+		MealRecordID: mealRecord.ID,
+		UserID:       mealRecord.UserID,
+		URL:          mealRecord.ImageURL,
+		CreatedAt:    mealRecord.CreatedAt,
+	}
+	if minOrder != nil {
+		photo.SortOrder = *minOrder - 1
+	}
+	if err := tx.Create(photo).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Unscoped().Model(&models.MealRecord{}).Where("id = ?", mealRecord.ID).Update("image_url", "").Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
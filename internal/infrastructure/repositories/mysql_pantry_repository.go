@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MySQLPantryRepository struct {
+	db *gorm.DB
+}
+
+func NewMySQLPantryRepository(db *gorm.DB) repositories.PantryRepository {
+	return &MySQLPantryRepository{db: db}
+}
+
+// pantryConsumeOrder 先用快到期的，没有保质期的最后用
+const pantryConsumeOrder = "expiry_date = '' ASC, expiry_date ASC, purchase_date ASC, id ASC"
+
+func (r *MySQLPantryRepository) Create(ctx context.Context, item *models.PantryItem) error {
+	result := r.db.WithContext(ctx).Omit("Ingredient").Create(item)
+	if result.Error != nil {
+		return fmt.Errorf("添加库存失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLPantryRepository) GetByID(ctx context.Context, id uint) (*models.PantryItem, error) {
+	var item models.PantryItem
+	result := r.db.WithContext(ctx).Preload("Ingredient").First(&item, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("库存不存在")
+		}
+		return nil, fmt.Errorf("查询库存失败: %w", result.Error)
+	}
+	return &item, nil
+}
+
+func (r *MySQLPantryRepository) Update(ctx context.Context, item *models.PantryItem) error {
+	result := r.db.WithContext(ctx).Omit("Ingredient").Save(item)
+	if result.Error != nil {
+		return fmt.Errorf("更新库存失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLPantryRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.PantryItem{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除库存失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLPantryRepository) List(ctx context.Context, householdID, ingredientID uint, location string, offset, limit int) ([]*models.PantryItem, int64, error) {
+	var items []*models.PantryItem
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.PantryItem{}).Where("household_id = ?", householdID)
+	if ingredientID != 0 {
+		query = query.Where("ingredient_id = ?", ingredientID)
+	}
+	if location != "" {
+		query = query.Where("location = ?", location)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计库存失败: %w", err)
+	}
+
+	if err := query.Preload("Ingredient").Offset(offset).Limit(limit).Order(pantryConsumeOrder).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询库存失败: %w", err)
+	}
+	return items, total, nil
+}
+
+func (r *MySQLPantryRepository) ListExpiring(ctx context.Context, householdID uint, date string) ([]*models.PantryItem, error) {
+	var items []*models.PantryItem
+	err := r.db.WithContext(ctx).Preload("Ingredient").
+		Where("household_id = ? AND expiry_date <> '' AND expiry_date <= ?", householdID, date).
+		Order(pantryConsumeOrder).Find(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询临期库存失败: %w", err)
+	}
+	return items, nil
+}
+
+func (r *MySQLPantryRepository) StockLevels(ctx context.Context, householdID uint) (map[uint]float64, error) {
+	var rows []struct {
+		IngredientID uint
+		Quantity     float64
+	}
+	err := r.db.WithContext(ctx).Model(&models.PantryItem{}).
+		Select("ingredient_id, SUM(quantity) AS quantity").
+		Where("household_id = ?", householdID).
+		Group("ingredient_id").Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计库存失败: %w", err)
+	}
+
+	levels := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		levels[row.IngredientID] = models.RoundQuantity(row.Quantity)
+	}
+	return levels, nil
+}
+
+func (r *MySQLPantryRepository) Consume(ctx context.Context, householdID uint, usages []repositories.PantryUsage) ([]repositories.PantryUsage, error) {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", tx.Error)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result, err := consumePantry(tx, householdID, usages)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("扣减库存失败: %w", err)
+	}
+
+	// 提交事务
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// consumePantry 在事务中按先到期先用的顺序扣减库存，返回每种食材实际扣减的数量
+func consumePantry(tx *gorm.DB, householdID uint, usages []repositories.PantryUsage) ([]repositories.PantryUsage, error) {
+	result := make([]repositories.PantryUsage, 0, len(usages))
+	for _, usage := range usages {
+		var items []*models.PantryItem
+		err := tx.Where("household_id = ? AND ingredient_id = ?", householdID, usage.IngredientID).
+			Order(pantryConsumeOrder).Find(&items).Error
+		if err != nil {
+			return nil, err
+		}
+
+		remaining := usage.Required
+		for _, item := range items {
+			if remaining <= 0 {
+				break
+			}
+			if item.Quantity <= remaining {
+				remaining = models.RoundQuantity(remaining - item.Quantity)
+				if err := tx.Delete(&models.PantryItem{}, item.ID).Error; err != nil {
+					return nil, err
+				}
+				continue
+			}
+			left := models.RoundQuantity(item.Quantity - remaining)
+			remaining = 0
+			if err := tx.Model(&models.PantryItem{}).Where("id = ?", item.ID).Update("quantity", left).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		usage.Deducted = models.RoundQuantity(usage.Required - remaining)
+		result = append(result, usage)
+	}
+	return result, nil
+}
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MySQLPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewMySQLPreferenceRepository(db *gorm.DB) repositories.PreferenceRepository {
+	return &MySQLPreferenceRepository{db: db}
+}
+
+// ratingScope 按用户、菜品和菜品行定位评分
+func ratingScope(db *gorm.DB, userID, dishID uint, mealRecordDishID *uint) *gorm.DB {
+	db = db.Where("user_id = ? AND dish_id = ?", userID, dishID)
+	if mealRecordDishID == nil {
+		return db.Where("meal_record_dish_id IS NULL")
+	}
+	return db.Where("meal_record_dish_id = ?", *mealRecordDishID)
+}
+
+func (r *MySQLPreferenceRepository) SaveRating(ctx context.Context, rating *models.DishRating) error {
+	var existing models.DishRating
+	result := ratingScope(r.db.WithContext(ctx), rating.UserID, rating.DishID, rating.MealRecordDishID).First(&existing)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return fmt.Errorf("查询评分失败: %w", result.Error)
+	}
+
+	if result.Error == nil {
+		rating.ID = existing.ID
+		rating.CreatedAt = existing.CreatedAt
+		if err := r.db.WithContext(ctx).Omit("User").Save(rating).Error; err != nil {
+			return fmt.Errorf("更新评分失败: %w", err)
+		}
+		return nil
+	}
+
+	if err := r.db.WithContext(ctx).Omit("User").Create(rating).Error; err != nil {
+		return fmt.Errorf("保存评分失败: %w", err)
+	}
+	return nil
+}
+
+func (r *MySQLPreferenceRepository) GetRating(ctx context.Context, userID, dishID uint, mealRecordDishID *uint) (*models.DishRating, error) {
+	var rating models.DishRating
+	result := ratingScope(r.db.WithContext(ctx), userID, dishID, mealRecordDishID).First(&rating)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("评分不存在")
+		}
+		return nil, fmt.Errorf("查询评分失败: %w", result.Error)
+	}
+	return &rating, nil
+}
+
+func (r *MySQLPreferenceRepository) DeleteRating(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.DishRating{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除评分失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("评分不存在")
+	}
+	return nil
+}
+
+func (r *MySQLPreferenceRepository) ListRatings(ctx context.Context, dishID uint, offset, limit int) ([]*models.DishRating, int64, error) {
+	var ratings []*models.DishRating
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.DishRating{}).Where("dish_id = ?", dishID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询评分总数失败: %w", err)
+	}
+
+	result := query.Preload("User").Offset(offset).Limit(limit).Order("updated_at DESC, id DESC").Find(&ratings)
+	if result.Error != nil {
+		return nil, 0, fmt.Errorf("查询评分列表失败: %w", result.Error)
+	}
+	return ratings, total, nil
+}
+
+func (r *MySQLPreferenceRepository) DishStats(ctx context.Context, dishIDs []uint) (map[uint]models.DishStats, error) {
+	stats := make(map[uint]models.DishStats, len(dishIDs))
+	if len(dishIDs) == 0 {
+		return stats, nil
+	}
+
+	var ratings []struct {
+		DishID        uint
+		AverageRating float64
+		RatingCount   int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.DishRating{}).
+		Select("dish_id, AVG(stars) AS average_rating, COUNT(*) AS rating_count").
+		Where("dish_id IN ?", dishIDs).
+		Group("dish_id").
+		Scan(&ratings).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计菜品评分失败: %w", err)
+	}
+	for _, row := range ratings {
+		s := stats[row.DishID]
+		s.AverageRating = models.RoundRating(row.AverageRating)
+		s.RatingCount = row.RatingCount
+		stats[row.DishID] = s
+	}
+
+	var eaten []struct {
+		DishID     uint
+		TimesEaten int64
+	}
+	err = r.db.WithContext(ctx).Model(&models.MealRecordDish{}).
+		Select("meal_record_dishes.dish_id, COUNT(*) AS times_eaten").
+		Joins("JOIN meal_records ON meal_records.id = meal_record_dishes.meal_record_id AND meal_records.deleted_at IS NULL").
+		Where("meal_record_dishes.dish_id IN ?", dishIDs).
+		Group("meal_record_dishes.dish_id").
+		Scan(&eaten).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计菜品用餐次数失败: %w", err)
+	}
+	for _, row := range eaten {
+		s := stats[row.DishID]
+		s.TimesEaten = row.TimesEaten
+		stats[row.DishID] = s
+	}
+	return stats, nil
+}
+
+func (r *MySQLPreferenceRepository) AddFavorite(ctx context.Context, userID, dishID uint) error {
+	favorite := &models.DishFavorite{UserID: userID, DishID: dishID}
+	if err := r.db.WithContext(ctx).Omit("Dish").Clauses(clause.OnConflict{DoNothing: true}).Create(favorite).Error; err != nil {
+		return fmt.Errorf("收藏菜品失败: %w", err)
+	}
+	return nil
+}
+
+func (r *MySQLPreferenceRepository) RemoveFavorite(ctx context.Context, userID, dishID uint) error {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND dish_id = ?", userID, dishID).Delete(&models.DishFavorite{})
+	if result.Error != nil {
+		return fmt.Errorf("取消收藏失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("未收藏该菜品")
+	}
+	return nil
+}
+
+func (r *MySQLPreferenceRepository) ListFavorites(ctx context.Context, userID uint) ([]*models.DishFavorite, error) {
+	var favorites []*models.DishFavorite
+	result := r.db.WithContext(ctx).
+		Joins("JOIN dishes ON dishes.id = dish_favorites.dish_id AND dishes.deleted_at IS NULL").
+		Preload("Dish.Category").Preload("Dish.Tags").
+		Where("dish_favorites.user_id = ?", userID).
+		Order("dish_favorites.created_at DESC").
+		Find(&favorites)
+	if result.Error != nil {
+		return nil, fmt.Errorf("查询收藏列表失败: %w", result.Error)
+	}
+	return favorites, nil
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MySQLRefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewMySQLRefreshTokenRepository(db *gorm.DB) repositories.RefreshTokenRepository {
+	return &MySQLRefreshTokenRepository{db: db}
+}
+
+func (r *MySQLRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	result := r.db.WithContext(ctx).Create(token)
+	if result.Error != nil {
+		return fmt.Errorf("创建刷新令牌失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLRefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	result := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("刷新令牌不存在")
+		}
+		return nil, fmt.Errorf("查询刷新令牌失败: %w", result.Error)
+	}
+	return &token, nil
+}
+
+func (r *MySQLRefreshTokenRepository) MarkUsed(ctx context.Context, id uint) (bool, error) {
+	// 条件更新保证并发刷新时只有一个请求能使用该令牌
+	result := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("更新刷新令牌失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *MySQLRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	result := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("吊销刷新令牌失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLRefreshTokenRepository) RevokeByUser(ctx context.Context, userID uint) ([]string, error) {
+	var families []string
+	err := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Distinct().Pluck("family_id", &families).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询刷新令牌失败: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return nil, fmt.Errorf("吊销刷新令牌失败: %w", result.Error)
+	}
+	return families, nil
+}
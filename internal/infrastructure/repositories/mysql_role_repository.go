@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MySQLRoleRepository struct {
+	db *gorm.DB
+}
+
+func NewMySQLRoleRepository(db *gorm.DB) repositories.RoleRepository {
+	return &MySQLRoleRepository{db: db}
+}
+
+func (r *MySQLRoleRepository) Create(ctx context.Context, role *models.Role) error {
+	result := r.db.WithContext(ctx).Create(role)
+	if result.Error != nil {
+		return fmt.Errorf("创建角色失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLRoleRepository) GetByID(ctx context.Context, id uint) (*models.Role, error) {
+	var role models.Role
+	result := r.db.WithContext(ctx).First(&role, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("角色不存在")
+		}
+		return nil, fmt.Errorf("查询角色失败: %w", result.Error)
+	}
+	return &role, nil
+}
+
+func (r *MySQLRoleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	result := r.db.WithContext(ctx).Where("name = ?", name).First(&role)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("角色不存在")
+		}
+		return nil, fmt.Errorf("查询角色失败: %w", result.Error)
+	}
+	return &role, nil
+}
+
+func (r *MySQLRoleRepository) Update(ctx context.Context, role *models.Role) error {
+	result := r.db.WithContext(ctx).Save(role)
+	if result.Error != nil {
+		return fmt.Errorf("更新角色失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLRoleRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.Role{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除角色失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("角色不存在")
+	}
+	return nil
+}
+
+func (r *MySQLRoleRepository) List(ctx context.Context) ([]*models.Role, error) {
+	var roles []*models.Role
+	result := r.db.WithContext(ctx).Order("name ASC").Find(&roles)
+	if result.Error != nil {
+		return nil, fmt.Errorf("查询角色列表失败: %w", result.Error)
+	}
+	return roles, nil
+}
+
+func (r *MySQLRoleRepository) CountUsers(ctx context.Context, name string) (int64, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&models.User{}).Where("role = ?", name).Count(&count)
+	if result.Error != nil {
+		return 0, fmt.Errorf("统计角色用户失败: %w", result.Error)
+	}
+	return count, nil
+}
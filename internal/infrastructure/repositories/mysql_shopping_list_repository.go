@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MySQLShoppingListRepository struct {
+	db *gorm.DB
+}
+
+func NewMySQLShoppingListRepository(db *gorm.DB) repositories.ShoppingListRepository {
+	return &MySQLShoppingListRepository{db: db}
+}
+
+func (r *MySQLShoppingListRepository) Create(ctx context.Context, list *models.ShoppingList) error {
+	// 清单和条目在同一事务中创建
+	result := r.db.WithContext(ctx).Create(list)
+	if result.Error != nil {
+		return fmt.Errorf("创建购物清单失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLShoppingListRepository) GetByID(ctx context.Context, id uint) (*models.ShoppingList, error) {
+	var list models.ShoppingList
+	result := r.db.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC, id ASC")
+	}).First(&list, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("购物清单不存在")
+		}
+		return nil, fmt.Errorf("查询购物清单失败: %w", result.Error)
+	}
+	return &list, nil
+}
+
+func (r *MySQLShoppingListRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.ShoppingList{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除购物清单失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLShoppingListRepository) List(ctx context.Context, householdID uint, offset, limit int) ([]*models.ShoppingList, int64, error) {
+	var lists []*models.ShoppingList
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.ShoppingList{}).Where("household_id = ?", householdID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计购物清单失败: %w", err)
+	}
+
+	if err := query.Offset(offset).Limit(limit).Order("created_at DESC, id DESC").Find(&lists).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询购物清单失败: %w", err)
+	}
+	return lists, total, nil
+}
+
+func (r *MySQLShoppingListRepository) UpdateItem(ctx context.Context, item *models.ShoppingListItem) error {
+	result := r.db.WithContext(ctx).Model(&models.ShoppingListItem{}).Where("id = ?", item.ID).
+		Updates(map[string]interface{}{"checked": item.Checked, "checked_at": item.CheckedAt})
+	if result.Error != nil {
+		return fmt.Errorf("更新购物清单条目失败: %w", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MySQLStatsRepository struct {
+	db *gorm.DB
+}
+
+func NewMySQLStatsRepository(db *gorm.DB) repositories.StatsRepository {
+	return &MySQLStatsRepository{db: db}
+}
+
+// mysqlPeriods 各时间粒度的分组表达式，周按周一所在日期分组
+var mysqlPeriods = map[string]string{
+	repositories.StatsPeriodDay:   "DATE_FORMAT(meal_records.eaten_at, '%Y-%m-%d')",
+	repositories.StatsPeriodWeek:  "DATE_FORMAT(DATE_SUB(meal_records.eaten_at, INTERVAL WEEKDAY(meal_records.eaten_at) DAY), '%Y-%m-%d')",
+	repositories.StatsPeriodMonth: "DATE_FORMAT(meal_records.eaten_at, '%Y-%m')",
+}
+
+// uncategorizedName 未分类菜品在统计中的名称
+const uncategorizedName = "未分类"
+
+// meals 家庭中符合条件的未删除用餐记录
+func (r *MySQLStatsRepository) meals(ctx context.Context, householdID uint, filter repositories.StatsFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.MealRecord{}).Where("meal_records.household_id = ?", householdID)
+	if filter.From != nil {
+		query = query.Where("meal_records.eaten_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("meal_records.eaten_at < ?", *filter.To)
+	}
+	if filter.MealType != "" {
+		query = query.Where("meal_records.meal_type = ?", filter.MealType)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("meal_records.user_id = ?", filter.UserID)
+	}
+	return query
+}
+
+// mealDishes 符合条件的用餐记录中的菜品行
+func (r *MySQLStatsRepository) mealDishes(ctx context.Context, householdID uint, filter repositories.StatsFilter) *gorm.DB {
+	return r.meals(ctx, householdID, filter).
+		Joins("JOIN meal_record_dishes ON meal_record_dishes.meal_record_id = meal_records.id")
+}
+
+func (r *MySQLStatsRepository) Summary(ctx context.Context, householdID uint, filter repositories.StatsFilter) (*models.SpendingSummary, error) {
+	var summary models.SpendingSummary
+	err := r.meals(ctx, householdID, filter).
+		Select("COALESCE(SUM(meal_records.total_price), 0) AS total_spent, COUNT(*) AS meal_count").
+		Scan(&summary).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计花费失败: %w", err)
+	}
+
+	err = r.mealDishes(ctx, householdID, filter).
+		Select("COUNT(DISTINCT meal_record_dishes.dish_id)").
+		Scan(&summary.DishCount).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计菜品数失败: %w", err)
+	}
+
+	summary.TotalSpent = models.RoundPrice(summary.TotalSpent)
+	if summary.MealCount > 0 {
+		summary.AveragePerMeal = models.RoundPrice(summary.TotalSpent / float64(summary.MealCount))
+	}
+	return &summary, nil
+}
+
+func (r *MySQLStatsRepository) Spending(ctx context.Context, householdID uint, filter repositories.StatsFilter, period string) ([]models.SpendingBucket, error) {
+	return r.spending(ctx, householdID, filter, mysqlPeriods[period])
+}
+
+// spending 按分组表达式汇总花费，SQLite 仓储传入自己方言的表达式
+func (r *MySQLStatsRepository) spending(ctx context.Context, householdID uint, filter repositories.StatsFilter, bucket string) ([]models.SpendingBucket, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("不支持的统计周期")
+	}
+
+	buckets := []models.SpendingBucket{}
+	err := r.meals(ctx, householdID, filter).
+		Select(bucket + " AS period, SUM(meal_records.total_price) AS total_spent, COUNT(*) AS meal_count").
+		Group("period").
+		Order("period ASC").
+		Scan(&buckets).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计花费失败: %w", err)
+	}
+	for i := range buckets {
+		buckets[i].TotalSpent = models.RoundPrice(buckets[i].TotalSpent)
+		buckets[i].AveragePerMeal = models.RoundPrice(buckets[i].TotalSpent / float64(buckets[i].MealCount))
+	}
+	return buckets, nil
+}
+
+func (r *MySQLStatsRepository) TopDishes(ctx context.Context, householdID uint, filter repositories.StatsFilter, limit int) ([]models.DishFrequency, error) {
+	dishes := []models.DishFrequency{}
+	err := r.mealDishes(ctx, householdID, filter).
+		Joins("JOIN dishes ON dishes.id = meal_record_dishes.dish_id").
+		Select("meal_record_dishes.dish_id, dishes.name, COUNT(DISTINCT meal_records.id) AS times_eaten, " +
+			"SUM(meal_record_dishes.quantity) AS quantity, SUM(meal_record_dishes.quantity * meal_record_dishes.unit_price) AS total_spent").
+		Group("meal_record_dishes.dish_id, dishes.name").
+		Order("times_eaten DESC, quantity DESC, meal_record_dishes.dish_id ASC").
+		Limit(limit).
+		Scan(&dishes).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计常吃的菜品失败: %w", err)
+	}
+	for i := range dishes {
+		dishes[i].TotalSpent = models.RoundPrice(dishes[i].TotalSpent)
+	}
+	return dishes, nil
+}
+
+func (r *MySQLStatsRepository) TopCategories(ctx context.Context, householdID uint, filter repositories.StatsFilter, limit int) ([]models.CategoryFrequency, error) {
+	categories := []models.CategoryFrequency{}
+	err := r.mealDishes(ctx, householdID, filter).
+		Joins("JOIN dishes ON dishes.id = meal_record_dishes.dish_id").
+		Joins("LEFT JOIN categories ON categories.id = dishes.category_id").
+		Select("dishes.category_id, COALESCE(categories.name, '') AS name, COUNT(*) AS times_eaten, " +
+			"COUNT(DISTINCT meal_record_dishes.dish_id) AS dish_count, SUM(meal_record_dishes.quantity * meal_record_dishes.unit_price) AS total_spent").
+		Group("dishes.category_id, categories.name").
+		Order("times_eaten DESC, dishes.category_id ASC").
+		Limit(limit).
+		Scan(&categories).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计常吃的分类失败: %w", err)
+	}
+	for i := range categories {
+		if categories[i].CategoryID == nil {
+			categories[i].Name = uncategorizedName
+		}
+		categories[i].TotalSpent = models.RoundPrice(categories[i].TotalSpent)
+	}
+	return categories, nil
+}
+
+func (r *MySQLStatsRepository) VarietyStreak(ctx context.Context, householdID uint, filter repositories.StatsFilter) (models.VarietyStreak, error) {
+	// 只查询记录ID、用餐时间和菜品ID，区间在内存中计算
+	var entries []models.MealDishEntry
+	err := r.mealDishes(ctx, householdID, filter).
+		Select("meal_records.id AS meal_record_id, meal_records.eaten_at, meal_record_dishes.dish_id").
+		Order("meal_records.eaten_at ASC, meal_records.id ASC").
+		Scan(&entries).Error
+	if err != nil {
+		return models.VarietyStreak{}, fmt.Errorf("查询用餐菜品失败: %w", err)
+	}
+	return models.LongestVarietyStreak(entries), nil
+}
+
+func (r *MySQLStatsRepository) Members(ctx context.Context, householdID uint, filter repositories.StatsFilter) ([]models.MemberStats, error) {
+	// 每餐的参与人数，花费按人数平摊
+	counts := r.db.WithContext(ctx).Model(&models.MealRecordParticipant{}).
+		Select("meal_record_id, COUNT(*) AS participant_count").
+		Group("meal_record_id")
+
+	var participated []models.MemberStats
+	err := r.meals(ctx, householdID, filter).
+		Joins("JOIN meal_record_participants ON meal_record_participants.meal_record_id = meal_records.id").
+		Joins("JOIN (?) AS participant_counts ON participant_counts.meal_record_id = meal_records.id", counts).
+		Joins("JOIN users ON users.id = meal_record_participants.user_id").
+		Select("meal_record_participants.user_id, users.username, COUNT(*) AS meal_count, " +
+			"SUM(meal_records.total_price / participant_counts.participant_count) AS spent").
+		Group("meal_record_participants.user_id, users.username").
+		Scan(&participated).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计成员用餐失败: %w", err)
+	}
+
+	var recorded []models.MemberStats
+	err = r.meals(ctx, householdID, filter).
+		Joins("JOIN users ON users.id = meal_records.user_id").
+		Select("meal_records.user_id, users.username, COUNT(*) AS recorded").
+		Group("meal_records.user_id, users.username").
+		Scan(&recorded).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计成员记录失败: %w", err)
+	}
+
+	return mergeMemberStats(participated, recorded), nil
+}
+
+// mergeMemberStats 合并成员参与和记录的统计，按平摊花费倒序
+func mergeMemberStats(participated, recorded []models.MemberStats) []models.MemberStats {
+	index := make(map[uint]int) // 用户ID -> members 中的下标
+	members := []models.MemberStats{}
+	for _, list := range [][]models.MemberStats{participated, recorded} {
+		for _, row := range list {
+			i, ok := index[row.UserID]
+			if !ok {
+				i = len(members)
+				index[row.UserID] = i
+				members = append(members, models.MemberStats{UserID: row.UserID, Username: row.Username})
+			}
+			member := &members[i]
+			member.MealCount += row.MealCount
+			member.Spent += row.Spent
+			member.Recorded += row.Recorded
+		}
+	}
+
+	for i := range members {
+		members[i].Spent = models.RoundPrice(members[i].Spent)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Spent != members[j].Spent {
+			return members[i].Spent > members[j].Spent
+		}
+		return members[i].UserID < members[j].UserID
+	})
+	return members
+}
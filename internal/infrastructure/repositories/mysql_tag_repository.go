@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MySQLTagRepository struct {
+	db *gorm.DB
+}
+
+func NewMySQLTagRepository(db *gorm.DB) repositories.TagRepository {
+	return &MySQLTagRepository{db: db}
+}
+
+func (r *MySQLTagRepository) Create(ctx context.Context, tag *models.Tag) error {
+	result := r.db.WithContext(ctx).Create(tag)
+	if result.Error != nil {
+		return fmt.Errorf("创建标签失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLTagRepository) GetByID(ctx context.Context, id uint) (*models.Tag, error) {
+	var tag models.Tag
+	result := r.db.WithContext(ctx).First(&tag, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("标签不存在")
+		}
+		return nil, fmt.Errorf("查询标签失败: %w", result.Error)
+	}
+	return &tag, nil
+}
+
+func (r *MySQLTagRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Order("name ASC").Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *MySQLTagRepository) GetByName(ctx context.Context, name string) (*models.Tag, error) {
+	var tag models.Tag
+	result := r.db.WithContext(ctx).Where("name = ?", name).First(&tag)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("标签不存在")
+		}
+		return nil, fmt.Errorf("查询标签失败: %w", result.Error)
+	}
+	return &tag, nil
+}
+
+func (r *MySQLTagRepository) Update(ctx context.Context, tag *models.Tag) error {
+	result := r.db.WithContext(ctx).Save(tag)
+	if result.Error != nil {
+		return fmt.Errorf("更新标签失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLTagRepository) Delete(ctx context.Context, id uint) error {
+	// 开始事务
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("开始事务失败: %w", tx.Error)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("tag_id = ?", id).Delete(&models.DishTag{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("删除标签关联失败: %w", err)
+	}
+
+	result := tx.Delete(&models.Tag{}, id)
+	if result.Error != nil {
+		tx.Rollback()
+		return fmt.Errorf("删除标签失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return fmt.Errorf("标签不存在")
+	}
+
+	// 提交事务
+	return tx.Commit().Error
+}
+
+func (r *MySQLTagRepository) List(ctx context.Context) ([]*models.Tag, error) {
+	var tags []*models.Tag
+	result := r.db.WithContext(ctx).Order("name ASC").Find(&tags)
+	if result.Error != nil {
+		return nil, fmt.Errorf("查询标签列表失败: %w", result.Error)
+	}
+	return tags, nil
+}
+
+func (r *MySQLTagRepository) Cloud(ctx context.Context) ([]repositories.TagUsage, error) {
+	usages := []repositories.TagUsage{}
+	err := r.db.WithContext(ctx).Model(&models.Tag{}).
+		Select("tags.id, tags.name, tags.color, tags.created_at, COUNT(dishes.id) AS dish_count").
+		Joins("LEFT JOIN dish_tags ON dish_tags.tag_id = tags.id").
+		Joins("LEFT JOIN dishes ON dishes.id = dish_tags.dish_id AND dishes.deleted_at IS NULL").
+		Group("tags.id, tags.name, tags.color, tags.created_at").
+		Order("dish_count DESC, tags.name ASC").
+		Scan(&usages).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计标签使用次数失败: %w", err)
+	}
+	return usages, nil
+}
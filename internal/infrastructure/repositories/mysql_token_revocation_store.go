@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLTokenRevocationStore 未启用 Redis 时使用数据库保存吊销记录
+type MySQLTokenRevocationStore struct {
+	db *gorm.DB
+}
+
+func NewMySQLTokenRevocationStore(db *gorm.DB) repositories.TokenRevocationStore {
+	return &MySQLTokenRevocationStore{db: db}
+}
+
+func (s *MySQLTokenRevocationStore) Revoke(ctx context.Context, key string, expiresAt time.Time) error {
+	// 顺带清理已过期的记录
+	if err := s.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.RevokedToken{}).Error; err != nil {
+		return fmt.Errorf("清理吊销记录失败: %w", err)
+	}
+
+	record := &models.RevokedToken{Key: key, ExpiresAt: expiresAt}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(record).Error; err != nil {
+		return fmt.Errorf("保存吊销记录失败: %w", err)
+	}
+	return nil
+}
+
+func (s *MySQLTokenRevocationStore) IsRevoked(ctx context.Context, keys ...string) (bool, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.RevokedToken{}).
+		Where("`key` IN ? AND expires_at > ?", keys, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("查询吊销记录失败: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+type MySQLUploadRepository struct {
+	db *gorm.DB
+}
+
+func NewMySQLUploadRepository(db *gorm.DB) repositories.UploadRepository {
+	return &MySQLUploadRepository{db: db}
+}
+
+func (r *MySQLUploadRepository) Create(ctx context.Context, upload *models.Upload) error {
+	result := r.db.WithContext(ctx).Create(upload)
+	if result.Error != nil {
+		return fmt.Errorf("保存上传记录失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLUploadRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.Upload{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除上传记录失败: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *MySQLUploadRepository) ListOrphans(ctx context.Context, before time.Time, limit int) ([]*models.Upload, error) {
+	db := r.db.WithContext(ctx)
+
+	// 软删除的记录可能被恢复，其引用同样保留；原图和缩略图任一被引用都不清理
+	query := db.Where("created_at < ?", before)
+	for _, ref := range uploadReferences() {
+		referenced := db.Unscoped().Model(ref.model).Select(ref.column).Where(ref.column + " IS NOT NULL AND " + ref.column + " <> ''")
+		query = query.Where("url NOT IN (?) AND thumbnail_url NOT IN (?)", referenced, referenced)
+	}
+
+	var uploads []*models.Upload
+	if err := query.Order("id ASC").Limit(limit).Find(&uploads).Error; err != nil {
+		return nil, fmt.Errorf("查询未引用的上传失败: %w", err)
+	}
+	return uploads, nil
+}
+
+// uploadReference 引用上传 URL 的字段
+type uploadReference struct {
+	model  interface{}
+	column string
+}
+
+func uploadReferences() []uploadReference {
+	return []uploadReference{
+		{&models.Dish{}, "image_url"},
+		{&models.DishStep{}, "image_url"},
+		{&models.MealRecord{}, "image_url"},
+		{&models.MealRecordPhoto{}, "url"},
+		{&models.User{}, "avatar_url"},
+		{&models.Category{}, "icon"},
+	}
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodcook/internal/domain/repositories"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const revokedTokenPrefix = "foodcook:revoked:"
+
+// RedisTokenRevocationStore 使用 Redis 保存吊销记录，记录随令牌过期自动删除
+type RedisTokenRevocationStore struct {
+	client *redis.Client
+}
+
+func NewRedisTokenRevocationStore(client *redis.Client) repositories.TokenRevocationStore {
+	return &RedisTokenRevocationStore{client: client}
+}
+
+func (s *RedisTokenRevocationStore) Revoke(ctx context.Context, key string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	if err := s.client.Set(ctx, revokedTokenPrefix+key, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("保存吊销记录失败: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisTokenRevocationStore) IsRevoked(ctx context.Context, keys ...string) (bool, error) {
+	if len(keys) == 0 {
+		return false, nil
+	}
+
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = revokedTokenPrefix + key
+	}
+
+	count, err := s.client.Exists(ctx, redisKeys...).Result()
+	if err != nil {
+		return false, fmt.Errorf("查询吊销记录失败: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"context"
+
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// SQLite 仓储与 MySQL 仓储共用同一套 GORM 查询，仅在方言不兼容时单独覆盖方法
+
+type SQLiteUserRepository struct {
+	*MySQLUserRepository
+}
+
+func NewSQLiteUserRepository(db *gorm.DB) repositories.UserRepository {
+	return &SQLiteUserRepository{MySQLUserRepository: &MySQLUserRepository{db: db}}
+}
+
+type SQLiteDishRepository struct {
+	*MySQLDishRepository
+}
+
+func NewSQLiteDishRepository(db *gorm.DB) repositories.DishRepository {
+	return &SQLiteDishRepository{MySQLDishRepository: &MySQLDishRepository{db: db}}
+}
+
+type SQLiteIngredientRepository struct {
+	*MySQLIngredientRepository
+}
+
+func NewSQLiteIngredientRepository(db *gorm.DB) repositories.IngredientRepository {
+	return &SQLiteIngredientRepository{MySQLIngredientRepository: &MySQLIngredientRepository{db: db}}
+}
+
+type SQLiteMealRecordRepository struct {
+	*MySQLMealRecordRepository
+}
+
+func NewSQLiteMealRecordRepository(db *gorm.DB) repositories.MealRecordRepository {
+	return &SQLiteMealRecordRepository{MySQLMealRecordRepository: &MySQLMealRecordRepository{db: db}}
+}
+
+type SQLiteTagRepository struct {
+	*MySQLTagRepository
+}
+
+func NewSQLiteTagRepository(db *gorm.DB) repositories.TagRepository {
+	return &SQLiteTagRepository{MySQLTagRepository: &MySQLTagRepository{db: db}}
+}
+
+type SQLitePreferenceRepository struct {
+	*MySQLPreferenceRepository
+}
+
+func NewSQLitePreferenceRepository(db *gorm.DB) repositories.PreferenceRepository {
+	return &SQLitePreferenceRepository{MySQLPreferenceRepository: &MySQLPreferenceRepository{db: db}}
+}
+
+type SQLiteCategoryRepository struct {
+	*MySQLCategoryRepository
+}
+
+func NewSQLiteCategoryRepository(db *gorm.DB) repositories.CategoryRepository {
+	return &SQLiteCategoryRepository{MySQLCategoryRepository: &MySQLCategoryRepository{db: db}}
+}
+
+type SQLiteRefreshTokenRepository struct {
+	*MySQLRefreshTokenRepository
+}
+
+func NewSQLiteRefreshTokenRepository(db *gorm.DB) repositories.RefreshTokenRepository {
+	return &SQLiteRefreshTokenRepository{MySQLRefreshTokenRepository: &MySQLRefreshTokenRepository{db: db}}
+}
+
+type SQLiteTokenRevocationStore struct {
+	*MySQLTokenRevocationStore
+}
+
+func NewSQLiteTokenRevocationStore(db *gorm.DB) repositories.TokenRevocationStore {
+	return &SQLiteTokenRevocationStore{MySQLTokenRevocationStore: &MySQLTokenRevocationStore{db: db}}
+}
+
+type SQLiteHouseholdRepository struct {
+	*MySQLHouseholdRepository
+}
+
+func NewSQLiteHouseholdRepository(db *gorm.DB) repositories.HouseholdRepository {
+	return &SQLiteHouseholdRepository{MySQLHouseholdRepository: &MySQLHouseholdRepository{db: db}}
+}
+
+type SQLiteRoleRepository struct {
+	*MySQLRoleRepository
+}
+
+func NewSQLiteRoleRepository(db *gorm.DB) repositories.RoleRepository {
+	return &SQLiteRoleRepository{MySQLRoleRepository: &MySQLRoleRepository{db: db}}
+}
+
+type SQLiteUploadRepository struct {
+	*MySQLUploadRepository
+}
+
+func NewSQLiteUploadRepository(db *gorm.DB) repositories.UploadRepository {
+	return &SQLiteUploadRepository{MySQLUploadRepository: &MySQLUploadRepository{db: db}}
+}
+
+type SQLiteMealPlanRepository struct {
+	*MySQLMealPlanRepository
+}
+
+func NewSQLiteMealPlanRepository(db *gorm.DB) repositories.MealPlanRepository {
+	return &SQLiteMealPlanRepository{MySQLMealPlanRepository: &MySQLMealPlanRepository{db: db}}
+}
+
+type SQLiteShoppingListRepository struct {
+	*MySQLShoppingListRepository
+}
+
+func NewSQLiteShoppingListRepository(db *gorm.DB) repositories.ShoppingListRepository {
+	return &SQLiteShoppingListRepository{MySQLShoppingListRepository: &MySQLShoppingListRepository{db: db}}
+}
+
+type SQLitePantryRepository struct {
+	*MySQLPantryRepository
+}
+
+func NewSQLitePantryRepository(db *gorm.DB) repositories.PantryRepository {
+	return &SQLitePantryRepository{MySQLPantryRepository: &MySQLPantryRepository{db: db}}
+}
+
+type SQLiteStatsRepository struct {
+	*MySQLStatsRepository
+}
+
+func NewSQLiteStatsRepository(db *gorm.DB) repositories.StatsRepository {
+	return &SQLiteStatsRepository{MySQLStatsRepository: &MySQLStatsRepository{db: db}}
+}
+
+// sqlitePeriods SQLite 没有 DATE_FORMAT 和 WEEKDAY，改用 strftime 和日期修饰符。
+// 带时区的时间按 UTC 计算，需要 'localtime' 换算为本地日期
+var sqlitePeriods = map[string]string{
+	repositories.StatsPeriodDay:   "strftime('%Y-%m-%d', meal_records.eaten_at, 'localtime')",
+	repositories.StatsPeriodWeek:  "date(meal_records.eaten_at, 'localtime', '-' || ((CAST(strftime('%w', meal_records.eaten_at, 'localtime') AS INTEGER) + 6) % 7) || ' days')",
+	repositories.StatsPeriodMonth: "strftime('%Y-%m', meal_records.eaten_at, 'localtime')",
+}
+
+func (r *SQLiteStatsRepository) Spending(ctx context.Context, householdID uint, filter repositories.StatsFilter, period string) ([]models.SpendingBucket, error) {
+	return r.spending(ctx, householdID, filter, sqlitePeriods[period])
+}
+
+type SQLiteBudgetRepository struct {
+	*MySQLBudgetRepository
+}
+
+func NewSQLiteBudgetRepository(db *gorm.DB) repositories.BudgetRepository {
+	return &SQLiteBudgetRepository{MySQLBudgetRepository: &MySQLBudgetRepository{db: db}}
+}
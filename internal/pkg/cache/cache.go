@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// KeyPrefix 所有缓存键的统一前缀
+const KeyPrefix = "foodcook:"
+
+// Cache 缓存接口，Redis 不可用时使用进程内 LRU 实现
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// DeletePrefix 删除所有以 prefix 开头的键
+	DeletePrefix(ctx context.Context, prefix string) error
+	Stats() Stats
+}
+
+// Stats 缓存命中统计
+type Stats struct {
+	Backend string `json:"backend"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int64  `json:"entries"`
+}
+
+// counter 命中/未命中计数器，供各缓存实现复用
+type counter struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func (c *counter) record(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// GetJSON 读取缓存并反序列化到 dest，出错时按未命中处理
+func GetJSON(ctx context.Context, c Cache, key string, dest interface{}) bool {
+	data, ok, err := c.Get(ctx, key)
+	if err != nil {
+		logrus.Warnf("cache get %s failed: %v", key, err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		logrus.Warnf("cache decode %s failed: %v", key, err)
+		return false
+	}
+	return true
+}
+
+// SetJSON 序列化 value 并写入缓存，失败只记录日志
+func SetJSON(ctx context.Context, c Cache, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		logrus.Warnf("cache encode %s failed: %v", key, err)
+		return
+	}
+	if err := c.Set(ctx, key, data, ttl); err != nil {
+		logrus.Warnf("cache set %s failed: %v", key, err)
+	}
+}
+
+// Invalidate 删除指定前缀的缓存，失败只记录日志
+func Invalidate(ctx context.Context, c Cache, prefixes ...string) {
+	for _, prefix := range prefixes {
+		if err := c.DeletePrefix(ctx, prefix); err != nil {
+			logrus.Warnf("cache invalidate %s failed: %v", prefix, err)
+		}
+	}
+}
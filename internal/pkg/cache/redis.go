@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache 基于 Redis 的缓存
+type RedisCache struct {
+	counter
+
+	client *redis.Client
+}
+
+func NewRedisCache(client *redis.Client) *RedisCache {
+	return &RedisCache{client: client}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := c.client.Get(ctx, KeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		c.record(false)
+		return nil, false, nil
+	}
+	if err != nil {
+		c.record(false)
+		return nil, false, err
+	}
+	c.record(true)
+	return data, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, KeyPrefix+key, value, ttl).Err()
+}
+
+func (c *RedisCache) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := c.client.Scan(ctx, 0, KeyPrefix+prefix+"*", 100).Iterator()
+
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) >= 100 {
+			if err := c.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return c.client.Del(ctx, keys...).Err()
+	}
+	return nil
+}
+
+func (c *RedisCache) Stats() Stats {
+	stats := Stats{
+		Backend: "redis",
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: -1,
+	}
+
+	// DBSize 统计的是整个 Redis 库的键数量
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if size, err := c.client.DBSize(ctx).Result(); err == nil {
+		stats.Entries = size
+	}
+	return stats
+}
@@ -13,6 +13,7 @@ type Config struct {
 	App      AppConfig      `mapstructure:"app"`
 	Database DatabaseConfig `mapstructure:"database"`
 	Redis    RedisConfig    `mapstructure:"redis"`
+	Cache    CacheConfig    `mapstructure:"cache"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Upload   UploadConfig   `mapstructure:"upload"`
 	CORS     CORSConfig     `mapstructure:"cors"`
@@ -25,7 +26,16 @@ type AppConfig struct {
 	Mode    string `mapstructure:"mode"`
 }
 
+// 数据库驱动
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+	DriverMemory = "memory"
+)
+
 type DatabaseConfig struct {
+	Driver          string `mapstructure:"driver"` // mysql, sqlite, memory
+	Path            string `mapstructure:"path"`   // sqlite 数据库文件路径
 	Host            string `mapstructure:"host"`
 	Port            int    `mapstructure:"port"`
 	User            string `mapstructure:"user"`
@@ -40,6 +50,7 @@ type DatabaseConfig struct {
 }
 
 type RedisConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
 	Password string `mapstructure:"password"`
@@ -47,15 +58,44 @@ type RedisConfig struct {
 	PoolSize int    `mapstructure:"pool_size"`
 }
 
+type CacheConfig struct {
+	Enabled   bool `mapstructure:"enabled"`
+	TTL       int  `mapstructure:"ttl"`        // 缓存过期时间（秒）
+	LocalSize int  `mapstructure:"local_size"` // Redis 不可用时本地 LRU 缓存容量
+}
+
 type JWTConfig struct {
-	Secret      string `mapstructure:"secret"`
-	ExpireHours int    `mapstructure:"expire_hours"`
+	Secret              string `mapstructure:"secret"`
+	AccessExpireMinutes int    `mapstructure:"access_expire_minutes"`
+	RefreshExpireHours  int    `mapstructure:"refresh_expire_hours"`
 }
 
+// 上传文件存储后端
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+)
+
 type UploadConfig struct {
-	MaxSize      int64    `mapstructure:"max_size"`
-	AllowedTypes []string `mapstructure:"allowed_types"`
-	UploadPath   string   `mapstructure:"upload_path"`
+	MaxSize           int64    `mapstructure:"max_size"`
+	AllowedTypes      []string `mapstructure:"allowed_types"`
+	UploadPath        string   `mapstructure:"upload_path"`
+	Storage           string   `mapstructure:"storage"`             // local, s3
+	BaseURL           string   `mapstructure:"base_url"`            // 访问上传文件的 URL 前缀
+	ThumbnailWidth    int      `mapstructure:"thumbnail_width"`     // 缩略图最大宽度（像素）
+	CacheMaxAge       int      `mapstructure:"cache_max_age"`       // 浏览器缓存时间（秒）
+	OrphanTTLHours    int      `mapstructure:"orphan_ttl_hours"`    // 未被引用的上传保留时间
+	GCIntervalMinutes int      `mapstructure:"gc_interval_minutes"` // 清理间隔，0 表示不清理
+	S3                S3Config `mapstructure:"s3"`
+}
+
+type S3Config struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	UseSSL    bool   `mapstructure:"use_ssl"`
 }
 
 type CORSConfig struct {
@@ -93,6 +133,12 @@ func LoadConfig() error {
 	}
 
 	// 从环境变量覆盖数据库配置
+	if driver := os.Getenv("DB_DRIVER"); driver != "" {
+		GlobalConfig.Database.Driver = driver
+	}
+	if path := os.Getenv("DB_PATH"); path != "" {
+		GlobalConfig.Database.Path = path
+	}
 	if host := os.Getenv("DB_HOST"); host != "" {
 		GlobalConfig.Database.Host = host
 	}
@@ -134,6 +180,8 @@ func setDefaults() {
 	viper.SetDefault("app.port", 8080)
 	viper.SetDefault("app.mode", "debug")
 
+	viper.SetDefault("database.driver", DriverMySQL)
+	viper.SetDefault("database.path", "./data/foodcook.db")
 	viper.SetDefault("database.host", "127.0.0.1")
 	viper.SetDefault("database.port", 3306)
 	viper.SetDefault("database.user", "root")
@@ -146,18 +194,36 @@ func setDefaults() {
 	viper.SetDefault("database.max_open_conns", 100)
 	viper.SetDefault("database.conn_max_lifetime", 3600)
 
+	viper.SetDefault("redis.enabled", true)
 	viper.SetDefault("redis.host", "127.0.0.1")
 	viper.SetDefault("redis.port", 6379)
 	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", 0)
 	viper.SetDefault("redis.pool_size", 10)
 
+	viper.SetDefault("cache.enabled", true)
+	viper.SetDefault("cache.ttl", 300)
+	viper.SetDefault("cache.local_size", 1024)
+
 	viper.SetDefault("jwt.secret", "your-secret-key-change-in-production")
-	viper.SetDefault("jwt.expire_hours", 24)
+	viper.SetDefault("jwt.access_expire_minutes", 15)
+	viper.SetDefault("jwt.refresh_expire_hours", 720)
 
 	viper.SetDefault("upload.max_size", 10485760)
-	viper.SetDefault("upload.allowed_types", []string{"image/jpeg", "image/png", "image/gif"})
+	viper.SetDefault("upload.allowed_types", []string{"image/jpeg", "image/png", "image/gif", "image/webp"})
 	viper.SetDefault("upload.upload_path", "./uploads")
+	viper.SetDefault("upload.storage", StorageLocal)
+	viper.SetDefault("upload.base_url", "/uploads")
+	viper.SetDefault("upload.thumbnail_width", 320)
+	viper.SetDefault("upload.cache_max_age", 2592000)
+	viper.SetDefault("upload.orphan_ttl_hours", 24)
+	viper.SetDefault("upload.gc_interval_minutes", 60)
+	viper.SetDefault("upload.s3.endpoint", "127.0.0.1:9000")
+	viper.SetDefault("upload.s3.region", "us-east-1")
+	viper.SetDefault("upload.s3.bucket", "foodcook")
+	viper.SetDefault("upload.s3.access_key", "minioadmin")
+	viper.SetDefault("upload.s3.secret_key", "minioadmin")
+	viper.SetDefault("upload.s3.use_ssl", false)
 
 	viper.SetDefault("cors.allowed_origins", []string{"*"})
 	viper.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"})
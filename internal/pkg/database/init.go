@@ -1,9 +1,12 @@
 package database
 
 import (
-	"foodcook/internal/domain/models"
+	"context"
 	"log"
 
+	"foodcook/internal/domain/models"
+	"foodcook/internal/domain/repositories"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -13,24 +16,107 @@ func InitTables() error {
 		return nil
 	}
 
+	// 旧版本的菜品行没有单价快照，迁移后按菜品当前价格补齐
+	backfillUnitPrice := DB.Migrator().HasTable(&models.MealRecordDish{}) &&
+		!DB.Migrator().HasColumn(&models.MealRecordDish{}, "unit_price")
+
 	// 自动迁移表结构
 	err := DB.AutoMigrate(
 		&models.User{},
 		&models.Category{},
 		&models.Dish{},
 		&models.Ingredient{},
+		&models.IngredientUnitConversion{},
+		&models.IngredientPriceHistory{},
 		&models.DishIngredient{},
+		&models.DishStep{},
+		&models.Tag{},
+		&models.DishTag{},
+		&models.DishRating{},
+		&models.DishFavorite{},
 		&models.MealRecord{},
 		&models.MealRecordDish{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.Household{},
+		&models.HouseholdMember{},
+		&models.HouseholdInvitation{},
+		&models.MealRecordComment{},
+		&models.Role{},
+		&models.Upload{},
+		&models.MealRecordPhoto{},
+		&models.MealRecordParticipant{},
+		&models.MealPlan{},
+		&models.MealPlanSlot{},
+		&models.MealPlanSlotDish{},
+		&models.ShoppingList{},
+		&models.ShoppingListItem{},
+		&models.PantryItem{},
+		&models.Budget{},
+		&models.BudgetCategoryLimit{},
 	)
 	if err != nil {
 		return err
 	}
 
+	if backfillUnitPrice {
+		err := DB.Exec("UPDATE meal_record_dishes SET unit_price = " +
+			"(SELECT price FROM dishes WHERE dishes.id = meal_record_dishes.dish_id) " +
+			"WHERE EXISTS (SELECT 1 FROM dishes WHERE dishes.id = meal_record_dishes.dish_id)").Error
+		if err != nil {
+			return err
+		}
+		log.Println("Backfilled meal record dish unit prices")
+	}
+
+	if err := backfillMealRecordTimes(); err != nil {
+		return err
+	}
+	if err := backfillPriceHistory(); err != nil {
+		return err
+	}
+
 	log.Println("Database tables initialized successfully")
 	return nil
 }
 
+// backfillMealRecordTimes 旧记录没有用餐时间和餐次，按创建时间补齐
+func backfillMealRecordTimes() error {
+	var records []models.MealRecord
+	err := DB.Unscoped().Select("id", "created_at").Where("eaten_at IS NULL").Find(&records).Error
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		err := DB.Unscoped().Model(&models.MealRecord{}).Where("id = ?", record.ID).Updates(map[string]interface{}{
+			"eaten_at":  record.CreatedAt,
+			"meal_type": models.MealTypeAt(record.CreatedAt),
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	if len(records) > 0 {
+		log.Printf("Backfilled meal time for %d meal records", len(records))
+	}
+	return nil
+}
+
+// backfillPriceHistory 没有价格记录的食材（旧数据或直接插入的数据）以当前价格补一条初始记录
+func backfillPriceHistory() error {
+	result := DB.Exec("INSERT INTO ingredient_price_histories (ingredient_id, price, unit, created_at) " +
+		"SELECT id, price, unit, created_at FROM ingredients WHERE deleted_at IS NULL " +
+		"AND id NOT IN (SELECT ingredient_id FROM ingredient_price_histories)")
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Backfilled price history for %d ingredients", result.RowsAffected)
+	}
+	return nil
+}
+
 // SeedData 插入初始数据
 func SeedData() error {
 	if DB == nil {
@@ -45,102 +131,263 @@ func SeedData() error {
 		return nil
 	}
 
-	// 创建 root 用户
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("root123"), bcrypt.DefaultCost)
+	rootUser, err := newRootUser()
 	if err != nil {
 		log.Printf("Failed to hash password: %v", err)
 		return err
 	}
 
-	rootUser := models.User{
+	if err := DB.Create(rootUser).Error; err != nil {
+		log.Printf("Failed to create root user: %v", err)
+	}
+
+	// 插入默认分类
+	categoryIDs := seedCategories(func(category *models.Category) error {
+		return DB.Create(category).Error
+	})
+
+	// 插入示例菜品
+	for _, dish := range defaultDishes(categoryIDs) {
+		if err := DB.Create(&dish).Error; err != nil {
+			log.Printf("Failed to create dish %s: %v", dish.Name, err)
+		}
+	}
+
+	// 插入示例食材
+	for _, ingredient := range defaultIngredients() {
+		if err := DB.Create(&ingredient).Error; err != nil {
+			log.Printf("Failed to create ingredient %s: %v", ingredient.Name, err)
+		}
+	}
+	if err := backfillPriceHistory(); err != nil {
+		log.Printf("Failed to create ingredient price history: %v", err)
+	}
+
+	log.Println("Database seeded successfully")
+	return nil
+}
+
+// SeedRepositories 通过仓储接口插入初始数据，用于内存存储等不经过 GORM 的场景
+func SeedRepositories(
+	ctx context.Context,
+	userRepo repositories.UserRepository,
+	categoryRepo repositories.CategoryRepository,
+	dishRepo repositories.DishRepository,
+	ingredientRepo repositories.IngredientRepository,
+) error {
+	// 检查是否已有数据
+	existing, err := categoryRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		log.Println("Repositories already have data, skipping seed")
+		return nil
+	}
+
+	rootUser, err := newRootUser()
+	if err != nil {
+		log.Printf("Failed to hash password: %v", err)
+		return err
+	}
+
+	if err := userRepo.Create(ctx, rootUser); err != nil {
+		log.Printf("Failed to create root user: %v", err)
+	}
+
+	categoryIDs := seedCategories(func(category *models.Category) error {
+		return categoryRepo.Create(ctx, category)
+	})
+
+	for _, dish := range defaultDishes(categoryIDs) {
+		if err := dishRepo.Create(ctx, &dish); err != nil {
+			log.Printf("Failed to create dish %s: %v", dish.Name, err)
+		}
+	}
+
+	for _, ingredient := range defaultIngredients() {
+		if err := ingredientRepo.Create(ctx, &ingredient); err != nil {
+			log.Printf("Failed to create ingredient %s: %v", ingredient.Name, err)
+		}
+	}
+
+	log.Println("Repositories seeded successfully")
+	return nil
+}
+
+// SeedRoles 确保内置角色存在，root 角色始终拥有全部权限
+func SeedRoles(ctx context.Context, roleRepo repositories.RoleRepository) error {
+	for _, builtin := range models.BuiltinRoles() {
+		role, err := roleRepo.GetByName(ctx, builtin.Name)
+		if err != nil {
+			if err := roleRepo.Create(ctx, &builtin); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// 其他内置角色的权限允许管理员调整，保持原样
+		if builtin.Name == models.RoleRoot && !role.HasPermission(models.PermissionAll) {
+			role.Permissions = builtin.Permissions
+			if err := roleRepo.Update(ctx, role); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MigratePersonalHouseholds 为每个用户创建个人家庭，并把尚未归属家庭的用餐记录迁移进去
+func MigratePersonalHouseholds(
+	ctx context.Context,
+	userRepo repositories.UserRepository,
+	householdRepo repositories.HouseholdRepository,
+) error {
+	users, _, err := userRepo.List(ctx, 0, -1)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if _, err := householdRepo.EnsurePersonal(ctx, user.ID, models.PersonalHouseholdName(user.Username)); err != nil {
+			log.Printf("Failed to migrate household for user %s: %v", user.Username, err)
+		}
+	}
+	return nil
+}
+
+// MigrateMealRecordPhotos 把用餐记录旧的单张图片迁移为照片
+func MigrateMealRecordPhotos(ctx context.Context, mealRecordRepo repositories.MealRecordRepository) error {
+	migrated, err := mealRecordRepo.MigrateImageURLs(ctx)
+	if err != nil {
+		return err
+	}
+	if migrated > 0 {
+		log.Printf("Migrated %d meal record images into photos", migrated)
+	}
+	return nil
+}
+
+// newRootUser 创建默认 root 用户
+func newRootUser() (*models.User, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("root123"), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.User{
 		Username:     "root",
 		Email:        "root@foodcook.com",
 		PasswordHash: string(hashedPassword),
 		Role:         models.RoleRoot,
-	}
+	}, nil
+}
 
-	if err := DB.Create(&rootUser).Error; err != nil {
-		log.Printf("Failed to create root user: %v", err)
+// defaultCategories 默认分类，下级分类放在上级分类的 Children 中
+func defaultCategories() []*models.Category {
+	return []*models.Category{
+		{Name: "中餐", SortOrder: 1, Icon: "🥢", Children: []*models.Category{
+			{Name: "川菜", SortOrder: 1, Icon: "🌶️"},
+			{Name: "粤菜", SortOrder: 2},
+			{Name: "湘菜", SortOrder: 3},
+			{Name: "鲁菜", SortOrder: 4},
+			{Name: "苏菜", SortOrder: 5},
+			{Name: "浙菜", SortOrder: 6},
+			{Name: "闽菜", SortOrder: 7},
+			{Name: "徽菜", SortOrder: 8},
+			{Name: "东北菜", SortOrder: 9},
+			{Name: "西北菜", SortOrder: 10},
+			{Name: "西南菜", SortOrder: 11},
+		}},
+		{Name: "汤", SortOrder: 2, Icon: "🍲", Children: []*models.Category{
+			{Name: "炖汤", SortOrder: 1},
+		}},
+		{Name: "其他", SortOrder: 99},
 	}
+}
 
-	// 插入默认分类
-	categories := []models.Category{
-		{Name: "川菜"},
-		{Name: "粤菜"},
-		{Name: "湘菜"},
-		{Name: "鲁菜"},
-		{Name: "苏菜"},
-		{Name: "浙菜"},
-		{Name: "闽菜"},
-		{Name: "徽菜"},
-		{Name: "东北菜"},
-		{Name: "西北菜"},
-		{Name: "西南菜"},
-		{Name: "其他"},
-	}
-
-	for _, category := range categories {
-		if err := DB.Create(&category).Error; err != nil {
-			log.Printf("Failed to create category %s: %v", category.Name, err)
+// seedCategories 先创建上级分类，再按返回的ID创建下级分类，返回分类名称到ID的映射
+func seedCategories(create func(category *models.Category) error) map[string]uint {
+	ids := make(map[string]uint)
+	for _, parent := range defaultCategories() {
+		children := parent.Children
+		parent.Children = nil
+		if err := create(parent); err != nil {
+			log.Printf("Failed to create category %s: %v", parent.Name, err)
+			continue
+		}
+		ids[parent.Name] = parent.ID
+
+		for _, child := range children {
+			parentID := parent.ID
+			child.ParentID = &parentID
+			if err := create(child); err != nil {
+				log.Printf("Failed to create category %s: %v", child.Name, err)
+				continue
+			}
+			ids[child.Name] = child.ID
 		}
 	}
+	return ids
+}
 
-	// 插入示例菜品
-	dishes := []models.Dish{
+// defaultDishes 示例菜品，categoryIDs 为分类名称到ID的映射，分类创建失败时菜品不关联分类
+func defaultDishes(categoryIDs map[string]uint) []models.Dish {
+	category := func(name string) *uint {
+		if id, ok := categoryIDs[name]; ok {
+			return &id
+		}
+		return nil
+	}
+	return []models.Dish{
 		{
 			Name:        "麻婆豆腐",
 			Description: "四川传统名菜，麻辣鲜香",
 			Price:       28.00,
-			CategoryID:  &[]uint{1}[0], // 川菜
+			CategoryID:  category("川菜"),
 			ImageURL:    "https://example.com/mapo-tofu.jpg",
+			SpicyLevel:  models.SpicyHot,
 		},
 		{
 			Name:        "白切鸡",
 			Description: "广东名菜，皮爽肉嫩",
 			Price:       45.00,
-			CategoryID:  &[]uint{2}[0], // 粤菜
+			CategoryID:  category("粤菜"),
 			ImageURL:    "https://example.com/white-cut-chicken.jpg",
+			Halal:       true,
 		},
 		{
 			Name:        "剁椒鱼头",
 			Description: "湖南特色菜，酸辣开胃",
 			Price:       68.00,
-			CategoryID:  &[]uint{3}[0], // 湘菜
+			CategoryID:  category("湘菜"),
 			ImageURL:    "https://example.com/chopped-pepper-fish-head.jpg",
+			SpicyLevel:  models.SpicyMedium,
 		},
 		{
 			Name:        "糖醋里脊",
 			Description: "经典家常菜，酸甜可口",
 			Price:       32.00,
-			CategoryID:  &[]uint{4}[0], // 鲁菜
+			CategoryID:  category("鲁菜"),
 			ImageURL:    "https://example.com/sweet-sour-pork.jpg",
 		},
 	}
+}
 
-	for _, dish := range dishes {
-		if err := DB.Create(&dish).Error; err != nil {
-			log.Printf("Failed to create dish %s: %v", dish.Name, err)
-		}
-	}
-
-	// 插入示例食材
-	ingredients := []models.Ingredient{
-		{Name: "豆腐", Unit: "块", Price: 3.00},
-		{Name: "猪肉", Unit: "斤", Price: 25.00},
-		{Name: "鸡肉", Unit: "斤", Price: 18.00},
-		{Name: "鱼头", Unit: "个", Price: 15.00},
-		{Name: "辣椒", Unit: "斤", Price: 8.00},
-		{Name: "葱", Unit: "斤", Price: 5.00},
-		{Name: "姜", Unit: "斤", Price: 12.00},
-		{Name: "蒜", Unit: "斤", Price: 6.00},
-	}
-
-	for _, ingredient := range ingredients {
-		if err := DB.Create(&ingredient).Error; err != nil {
-			log.Printf("Failed to create ingredient %s: %v", ingredient.Name, err)
-		}
+// defaultIngredients 示例食材，营养成分为每 100 克可食部分的参考值
+func defaultIngredients() []models.Ingredient {
+	return []models.Ingredient{
+		{Name: "豆腐", Unit: "块", Price: 3.00, Conversions: []models.IngredientUnitConversion{
+			{Unit: "块", Quantity: 300, TargetUnit: "克"},
+		}, Nutrition: models.Nutrition{Calories: 82, Protein: 8.1, Fat: 3.7, Carbs: 4.2, Fiber: 0.4, Sodium: 7.2},
+			Allergens: []string{models.AllergenSoy}},
+		{Name: "猪肉", Unit: "斤", Price: 25.00, Nutrition: models.Nutrition{Calories: 395, Protein: 13.2, Fat: 37, Carbs: 2.4, Sodium: 59.4}},
+		{Name: "鸡肉", Unit: "斤", Price: 18.00, Nutrition: models.Nutrition{Calories: 167, Protein: 19.3, Fat: 9.4, Carbs: 1.3, Sodium: 63.3}},
+		{Name: "鱼头", Unit: "个", Price: 15.00, Nutrition: models.Nutrition{Calories: 100, Protein: 15.3, Fat: 2.2, Carbs: 4.7, Sodium: 60.6},
+			Allergens: []string{models.AllergenFish}},
+		{Name: "辣椒", Unit: "斤", Price: 8.00, Nutrition: models.Nutrition{Calories: 32, Protein: 1.3, Fat: 0.4, Carbs: 8.9, Fiber: 3.2, Sodium: 2.6}},
+		{Name: "葱", Unit: "斤", Price: 5.00, Nutrition: models.Nutrition{Calories: 30, Protein: 1.7, Fat: 0.3, Carbs: 6.5, Fiber: 1.3, Sodium: 4.8}},
+		{Name: "姜", Unit: "斤", Price: 12.00, Nutrition: models.Nutrition{Calories: 41, Protein: 1.3, Fat: 0.6, Carbs: 10.3, Fiber: 2.7, Sodium: 14.9}},
+		{Name: "蒜", Unit: "斤", Price: 6.00, Nutrition: models.Nutrition{Calories: 128, Protein: 4.5, Fat: 0.2, Carbs: 27.6, Fiber: 1.1, Sodium: 19.6}},
 	}
-
-	log.Println("Database seeded successfully")
-	return nil
 }
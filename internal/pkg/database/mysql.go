@@ -20,6 +20,17 @@ func InitDatabase() error {
 		return fmt.Errorf("config not loaded")
 	}
 
+	switch cfg.Database.Driver {
+	case config.DriverMemory:
+		log.Println("Using in-memory storage, database connection skipped")
+		return nil
+	case config.DriverSQLite:
+		return initSQLite(cfg)
+	case "", config.DriverMySQL:
+	default:
+		return fmt.Errorf("unsupported database driver: %s", cfg.Database.Driver)
+	}
+
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=%t&loc=%s",
 		cfg.Database.User,
 		cfg.Database.Password,
@@ -28,6 +28,8 @@ func InitRedis() error {
 	// 测试连接
 	ctx := context.Background()
 	if err := RedisClient.Ping(ctx).Err(); err != nil {
+		RedisClient.Close()
+		RedisClient = nil
 		return fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
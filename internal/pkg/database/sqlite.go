@@ -0,0 +1,51 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"foodcook/internal/pkg/config"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func initSQLite(cfg *config.Config) error {
+	path := cfg.Database.Path
+	if path == "" {
+		return fmt.Errorf("sqlite database path is empty")
+	}
+
+	// 确保数据库文件所在目录存在
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create sqlite directory: %w", err)
+		}
+	}
+
+	var err error
+	DB, err = gorm.Open(sqlite.Open(path+"?_foreign_keys=on"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Info),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+
+	// SQLite 同一时间只允许一个写连接，避免 database is locked
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := sqlDB.Ping(); err != nil {
+		return fmt.Errorf("failed to ping sqlite database: %w", err)
+	}
+
+	log.Printf("SQLite database opened at %s", path)
+	return nil
+}
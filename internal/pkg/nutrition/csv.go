@@ -0,0 +1,165 @@
+package nutrition
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"foodcook/internal/domain/models"
+)
+
+// ErrMissingColumns 表头中没有名称列或任何营养成分列
+var ErrMissingColumns = errors.New("CSV 缺少名称列或营养成分列")
+
+// Record CSV 中的一行，营养成分为每 100 克的含量
+type Record struct {
+	Line      int
+	Name      string
+	Nutrition models.Nutrition
+}
+
+// RowError 无法导入的行
+type RowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// 表头别名，按去掉括号中的单位、转为小写后匹配
+var columnAliases = map[string][]string{
+	"name":     {"name", "food", "名称", "食材", "食物名称", "食品名称"},
+	"calories": {"calories", "energy", "kcal", "能量", "热量"},
+	"protein":  {"protein", "蛋白质"},
+	"fat":      {"fat", "脂肪"},
+	"carbs":    {"carbs", "carbohydrate", "carbohydrates", "碳水化合物", "碳水"},
+	"fiber":    {"fiber", "fibre", "膳食纤维", "纤维"},
+	"sodium":   {"sodium", "钠"},
+}
+
+var unitSuffix = regexp.MustCompile(`[（(\[].*[)）\]]`)
+
+// 表示微量或未检出的写法，按 0 处理
+var traceValues = map[string]bool{"": true, "-": true, "—": true, "tr": true, "微量": true, "未检出": true}
+
+// kjPerKcal 能量列的单位为千焦时换算为千卡
+const kjPerKcal = 4.184
+
+// ParseCSV 解析营养成分数据集，第一行为表头，列的顺序不限，未提供的营养成分按 0 处理。
+// 格式错误或数值超出范围的行记录在 RowError 中，不影响其他行
+func ParseCSV(r io.Reader) ([]Record, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取表头失败: %w", err)
+	}
+
+	columns := make(map[string]int)
+	energyInKJ := false
+	for i, title := range header {
+		title = strings.TrimPrefix(title, "\ufeff")
+		key := strings.ToLower(strings.TrimSpace(unitSuffix.ReplaceAllString(title, "")))
+		for column, aliases := range columnAliases {
+			if _, seen := columns[column]; seen || !contains(aliases, key) {
+				continue
+			}
+			columns[column] = i
+			if column == "calories" && strings.Contains(strings.ToLower(title), "kj") {
+				energyInKJ = true
+			}
+		}
+	}
+	if _, ok := columns["name"]; !ok || len(columns) < 2 {
+		return nil, nil, ErrMissingColumns
+	}
+
+	var records []Record
+	var rowErrors []RowError
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, RowError{Line: parseErr.Line, Error: "CSV 格式错误"})
+				continue
+			}
+			return nil, nil, fmt.Errorf("读取 CSV 失败: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		record, err := parseRow(row, columns, energyInKJ)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Error: err.Error()})
+			continue
+		}
+		if record.Name == "" {
+			continue
+		}
+		record.Line = line
+		records = append(records, record)
+	}
+	return records, rowErrors, nil
+}
+
+func parseRow(row []string, columns map[string]int, energyInKJ bool) (Record, error) {
+	cell := func(column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var parseErr error
+	value := func(column string) float64 {
+		text := cell(column)
+		if traceValues[strings.ToLower(text)] || parseErr != nil {
+			return 0
+		}
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			parseErr = fmt.Errorf("无效的数值: %s", text)
+		}
+		return v
+	}
+
+	record := Record{
+		Name: cell("name"),
+		Nutrition: models.Nutrition{
+			Calories: value("calories"),
+			Protein:  value("protein"),
+			Fat:      value("fat"),
+			Carbs:    value("carbs"),
+			Fiber:    value("fiber"),
+			Sodium:   value("sodium"),
+		},
+	}
+	if parseErr != nil {
+		return record, parseErr
+	}
+	if energyInKJ {
+		record.Nutrition.Calories /= kjPerKcal
+	}
+	record.Nutrition = record.Nutrition.Round()
+	if !record.Nutrition.IsValid() {
+		return record, errors.New("营养成分超出合理范围")
+	}
+	return record, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package recipe
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// walk 深度优先遍历节点，visit 返回 false 时不再进入子节点
+func walk(n *html.Node, visit func(*html.Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, visit)
+	}
+}
+
+// attr 返回元素的属性值，不存在时为空
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// textContent 返回节点内的全部文本，块级元素之间补一个换行，忽略脚本和样式
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "noscript":
+				return
+			case "br", "p", "li", "div":
+				b.WriteString("\n")
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(n)
+	return b.String()
+}
+
+// findFirst 返回第一个满足条件的节点
+func findFirst(n *html.Node, match func(*html.Node) bool) *html.Node {
+	var found *html.Node
+	walk(n, func(node *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if match(node) {
+			found = node
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// isElement 判断节点是否为指定标签之一的元素
+func isElement(n *html.Node, tags ...string) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	for _, tag := range tags {
+		if n.Data == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// metaContent 按 name 或 property 查找 <meta> 的内容，例如 og:title
+func metaContent(doc *html.Node, key string) string {
+	node := findFirst(doc, func(n *html.Node) bool {
+		return isElement(n, "meta") && (attr(n, "property") == key || attr(n, "name") == key)
+	})
+	if node == nil {
+		return ""
+	}
+	return attr(node, "content")
+}
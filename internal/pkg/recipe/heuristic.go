@@ -0,0 +1,125 @@
+package recipe
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// 食材和步骤区域常见的标题
+	ingredientHeadings = []string{"食材", "用料", "材料", "配料", "原料", "ingredients"}
+	stepHeadings       = []string{"做法", "步骤", "制作方法", "制作过程", "instructions", "directions", "method"}
+)
+
+// maxHeuristicSteps 没有列表时最多把标题后面多少个段落当作步骤
+const maxHeuristicSteps = 50
+
+// parseHeuristic 没有结构化数据时，按页面标题、og 标签和「食材」「做法」标题后的列表推测菜谱
+func parseHeuristic(doc *html.Node) *Recipe {
+	recipe := &Recipe{
+		Name:        metaContent(doc, "og:title"),
+		Description: metaContent(doc, "og:description"),
+		ImageURL:    metaContent(doc, "og:image"),
+		Source:      SourceHeuristic,
+	}
+	if recipe.Name == "" {
+		if h1 := findFirst(doc, func(n *html.Node) bool { return isElement(n, "h1") }); h1 != nil {
+			recipe.Name = textContent(h1)
+		}
+	}
+	if recipe.Name == "" {
+		if title := findFirst(doc, func(n *html.Node) bool { return isElement(n, "title") }); title != nil {
+			recipe.Name = textContent(title)
+		}
+	}
+	// 菜谱站点的标题通常是「xx的做法」
+	recipe.Name = strings.TrimSuffix(strings.TrimSpace(recipe.Name), "的做法")
+	if recipe.Description == "" {
+		recipe.Description = metaContent(doc, "description")
+	}
+
+	var elements []*html.Node
+	walk(doc, func(n *html.Node) bool {
+		if n.Type == html.ElementNode {
+			elements = append(elements, n)
+		}
+		return true
+	})
+
+	for _, line := range sectionLines(elements, ingredientHeadings, "ingredient", false) {
+		recipe.Ingredients = append(recipe.Ingredients, ParseIngredient(line))
+	}
+	recipe.Steps = sectionLines(elements, stepHeadings, "step", true)
+	return recipe
+}
+
+// sectionLines 找到标题文字包含关键字的位置，返回其后第一个列表的各项；
+// 没有这样的标题时退而查找 class 包含 className 的列表。allowParagraphs 为 true 时，
+// 标题后没有列表则取到下一个标题为止的段落
+func sectionLines(elements []*html.Node, keywords []string, className string, allowParagraphs bool) []string {
+	for i, n := range elements {
+		if !isHeading(n) || !containsAny(textContent(n), keywords) {
+			continue
+		}
+
+		var paragraphs []string
+		for _, next := range elements[i+1:] {
+			if isElement(next, "h1", "h2", "h3", "h4", "h5", "h6") {
+				break
+			}
+			if isElement(next, "ul", "ol") {
+				if items := listItems(next); len(items) > 0 {
+					return items
+				}
+			}
+			if allowParagraphs && isElement(next, "p") && len(paragraphs) < maxHeuristicSteps {
+				paragraphs = append(paragraphs, textContent(next))
+			}
+		}
+		if len(paragraphs) > 0 {
+			return paragraphs
+		}
+	}
+
+	for _, n := range elements {
+		if isElement(n, "ul", "ol") && strings.Contains(strings.ToLower(attr(n, "class")), className) {
+			if items := listItems(n); len(items) > 0 {
+				return items
+			}
+		}
+	}
+	return nil
+}
+
+// isHeading 标题或单独成行的加粗文字
+func isHeading(n *html.Node) bool {
+	if isElement(n, "h2", "h3", "h4", "h5", "h6", "dt", "legend") {
+		return true
+	}
+	return isElement(n, "strong", "b") && len([]rune(strings.TrimSpace(textContent(n)))) <= 20
+}
+
+// listItems 返回列表的直接子项文本
+func listItems(list *html.Node) []string {
+	var items []string
+	for child := list.FirstChild; child != nil; child = child.NextSibling {
+		if !isElement(child, "li") {
+			continue
+		}
+		if text := collapseSpace(textContent(child)); text != "" {
+			items = append(items, text)
+		}
+	}
+	return items
+}
+
+func containsAny(text string, keywords []string) bool {
+	text = strings.ToLower(text)
+	for _, keyword := range keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package recipe
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"foodcook/internal/pkg/units"
+)
+
+// Ingredient 解析后的一行食材，Quantity 为 0 表示用量不确定（如「适量」），
+// Unit 为空表示页面没有写单位或单位不在支持的范围内
+type Ingredient struct {
+	Raw      string  `json:"raw"`
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+}
+
+var (
+	// 分数（1/2、1 1/2）、小数和范围（2-3，取较小值）
+	numberPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)(?:\s+(\d+)\s*/\s*(\d+)|\s*/\s*(\d+))?(?:\s*[-~～到]\s*\d+(?:\.\d+)?)?`)
+	// 中文数字只在后面紧跟单位时识别，例如 半斤、两个、一勺
+	chineseNumberPattern = regexp.MustCompile(`[一二两三四五六七八九十半]+`)
+	// 括号里的备注
+	notePattern = regexp.MustCompile(`[（(][^）)]*[）)]`)
+)
+
+var vulgarFractions = strings.NewReplacer("½", " 1/2", "¼", " 1/4", "¾", " 3/4", "⅓", " 1/3", "⅔", " 2/3")
+
+// 表示用量不确定的词，从名称中去掉
+var vagueWords = []string{"适量", "少许", "少量", "若干", "一点", "左右", "约"}
+
+// ParseIngredient 把一行食材文本拆分为名称、用量和单位，例如「猪肉末 200克」「2 tbsp 生抽」
+func ParseIngredient(line string) Ingredient {
+	raw := collapseSpace(line)
+	result := Ingredient{Raw: raw}
+
+	text := vulgarFractions.Replace(raw)
+	text = notePattern.ReplaceAllString(text, " ")
+
+	start, end := -1, -1
+	if loc := numberPattern.FindStringSubmatchIndex(text); loc != nil {
+		result.Quantity = parseNumber(text, loc)
+		start, end = loc[0], loc[1]
+	}
+	for _, loc := range chineseNumberPattern.FindAllStringIndex(text, -1) {
+		if start >= 0 && loc[0] > start {
+			break
+		}
+		if n := chineseQuantityEnd(text, loc); n > 0 {
+			result.Quantity = parseChineseNumber(text[loc[0]:n])
+			start, end = loc[0], n
+			break
+		}
+	}
+
+	if start >= 0 {
+		rest := strings.TrimLeft(text[end:], " ")
+		if unit, n := units.Prefix(rest); n > 0 {
+			result.Unit = unit
+			end = len(text) - len(rest) + n
+		}
+		text = text[:start] + " " + text[end:]
+	}
+
+	for _, word := range vagueWords {
+		text = strings.ReplaceAll(text, word, " ")
+	}
+	text = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(",，、:：;；*•·-", r) {
+			return ' '
+		}
+		return r
+	}, text)
+	result.Name = collapseSpace(text)
+	result.Quantity = math.Round(result.Quantity*100) / 100
+	return result
+}
+
+// parseNumber 解析 numberPattern 的匹配结果
+func parseNumber(text string, loc []int) float64 {
+	group := func(i int) string {
+		if loc[2*i] < 0 {
+			return ""
+		}
+		return text[loc[2*i]:loc[2*i+1]]
+	}
+
+	value, _ := strconv.ParseFloat(group(1), 64)
+	numerator, denominator := group(2), group(3)
+	if numerator == "" && group(4) != "" {
+		// 1/2 形式，第一个数字是分子
+		numerator, denominator = group(1), group(4)
+		value = 0
+	}
+	if numerator != "" {
+		n, _ := strconv.ParseFloat(numerator, 64)
+		d, _ := strconv.ParseFloat(denominator, 64)
+		if d > 0 {
+			value += n / d
+		}
+	}
+	return value
+}
+
+// chineseQuantityEnd 返回中文数字后紧跟单位时数字结束的位置，否则返回 0。
+// 「两」本身也是单位，「二两猪肉」应识别为 2 两，所以从长到短尝试
+func chineseQuantityEnd(text string, loc []int) int {
+	for end := loc[1]; end > loc[0]; {
+		if _, n := units.Prefix(text[end:]); n > 0 {
+			return end
+		}
+		_, size := utf8.DecodeLastRuneInString(text[loc[0]:end])
+		end -= size
+	}
+	return 0
+}
+
+// parseChineseNumber 解析一到九十九的中文数字，「半」为 0.5
+func parseChineseNumber(s string) float64 {
+	digits := map[rune]float64{'一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+
+	var value, current float64
+	for _, r := range s {
+		switch {
+		case r == '半':
+			value += current + 0.5
+			current = 0
+		case r == '十':
+			if current == 0 {
+				current = 1
+			}
+			value += current * 10
+			current = 0
+		default:
+			current = digits[r]
+		}
+	}
+	return value + current
+}
@@ -0,0 +1,200 @@
+package recipe
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// parseJSONLD 从 <script type="application/ld+json"> 中查找 schema.org Recipe
+func parseJSONLD(doc *html.Node) *Recipe {
+	var found *Recipe
+	walk(doc, func(n *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if n.Type != html.ElementNode || n.Data != "script" || !strings.EqualFold(strings.TrimSpace(attr(n, "type")), "application/ld+json") {
+			return true
+		}
+
+		if n.FirstChild == nil {
+			return false
+		}
+		var data interface{}
+		if err := json.Unmarshal([]byte(n.FirstChild.Data), &data); err != nil {
+			return false
+		}
+		if node := findRecipeObject(data); node != nil {
+			found = recipeFromJSONLD(node)
+		}
+		return false
+	})
+	return found
+}
+
+// findRecipeObject 在顶层对象、数组和 @graph 中查找 @type 为 Recipe 的对象
+func findRecipeObject(data interface{}) map[string]interface{} {
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if node := findRecipeObject(item); node != nil {
+				return node
+			}
+		}
+	case map[string]interface{}:
+		for _, t := range jsonStrings(v["@type"]) {
+			if t == "Recipe" || strings.HasSuffix(t, "/Recipe") {
+				return v
+			}
+		}
+		if graph, ok := v["@graph"]; ok {
+			return findRecipeObject(graph)
+		}
+		if entity, ok := v["mainEntity"]; ok {
+			return findRecipeObject(entity)
+		}
+	}
+	return nil
+}
+
+func recipeFromJSONLD(node map[string]interface{}) *Recipe {
+	recipe := &Recipe{
+		Name:        jsonString(node["name"]),
+		Description: html.UnescapeString(jsonString(node["description"])),
+		ImageURL:    jsonImage(node["image"]),
+		PrepMinutes: parseDuration(jsonString(node["prepTime"])),
+		CookMinutes: parseDuration(jsonString(node["cookTime"])),
+		Servings:    parseServings(node["recipeYield"]),
+		Source:      SourceJSONLD,
+	}
+	if recipe.PrepMinutes == 0 && recipe.CookMinutes == 0 {
+		recipe.CookMinutes = parseDuration(jsonString(node["totalTime"]))
+	}
+
+	lines := jsonStrings(node["recipeIngredient"])
+	if len(lines) == 0 {
+		lines = jsonStrings(node["ingredients"])
+	}
+	for _, line := range lines {
+		recipe.Ingredients = append(recipe.Ingredients, ParseIngredient(html.UnescapeString(line)))
+	}
+
+	recipe.Steps = jsonSteps(node["recipeInstructions"])
+	return recipe
+}
+
+// jsonSteps 步骤可以是一段文本、文本数组、HowToStep 数组或分组的 HowToSection
+func jsonSteps(data interface{}) []string {
+	var steps []string
+	switch v := data.(type) {
+	case string:
+		steps = append(steps, strings.Split(stripTags(v), "\n")...)
+	case []interface{}:
+		for _, item := range v {
+			steps = append(steps, jsonSteps(item)...)
+		}
+	case map[string]interface{}:
+		if items, ok := v["itemListElement"]; ok {
+			return jsonSteps(items)
+		}
+		text := jsonString(v["text"])
+		if text == "" {
+			text = jsonString(v["name"])
+		}
+		steps = append(steps, strings.Split(stripTags(text), "\n")...)
+	}
+	return steps
+}
+
+// jsonImage 图片可以是地址、地址数组或 ImageObject，取第一张
+func jsonImage(data interface{}) string {
+	switch v := data.(type) {
+	case string:
+		return v
+	case []interface{}:
+		for _, item := range v {
+			if image := jsonImage(item); image != "" {
+				return image
+			}
+		}
+	case map[string]interface{}:
+		return jsonString(v["url"])
+	}
+	return ""
+}
+
+func jsonString(data interface{}) string {
+	switch v := data.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		if len(v) > 0 {
+			return jsonString(v[0])
+		}
+	}
+	return ""
+}
+
+func jsonStrings(data interface{}) []string {
+	switch v := data.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s := jsonString(item); s != "" {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+var (
+	durationPattern = regexp.MustCompile(`(?i)^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:\d+S)?)?$`)
+	servingsPattern = regexp.MustCompile(`\d+`)
+	tagPattern      = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</li>`)
+)
+
+// parseDuration 把 ISO 8601 时长（如 PT1H30M）转换为分钟
+func parseDuration(s string) int {
+	m := durationPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0
+	}
+	days, _ := strconv.Atoi(m[1])
+	hours, _ := strconv.Atoi(m[2])
+	minutes, _ := strconv.Atoi(m[3])
+	return days*24*60 + hours*60 + minutes
+}
+
+// parseServings 从 recipeYield（如 "4"、"4 servings"、"2人份"）中取份数
+func parseServings(data interface{}) int {
+	for _, s := range jsonStrings(data) {
+		if n, err := strconv.Atoi(servingsPattern.FindString(s)); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// stripTags 部分站点在步骤文本中带有 HTML，按换行拆分后去掉标签
+func stripTags(s string) string {
+	s = tagPattern.ReplaceAllString(s, "\n")
+	nodes, err := html.ParseFragment(strings.NewReader(s), &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		return s
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(textContent(n))
+	}
+	return b.String()
+}
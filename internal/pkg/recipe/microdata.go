@@ -0,0 +1,119 @@
+package recipe
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// parseMicrodata 查找 itemtype 为 schema.org/Recipe 的 microdata 标注
+func parseMicrodata(doc *html.Node) *Recipe {
+	scope := findFirst(doc, func(n *html.Node) bool {
+		return n.Type == html.ElementNode && hasAttr(n, "itemscope") && isRecipeType(attr(n, "itemtype"))
+	})
+	if scope == nil {
+		return nil
+	}
+
+	props := itemProps(scope)
+	recipe := &Recipe{
+		Name:        first(props["name"]),
+		Description: first(props["description"]),
+		ImageURL:    first(props["image"]),
+		PrepMinutes: parseDuration(first(props["prepTime"])),
+		CookMinutes: parseDuration(first(props["cookTime"])),
+		Source:      SourceMicrodata,
+	}
+	if recipe.PrepMinutes == 0 && recipe.CookMinutes == 0 {
+		recipe.CookMinutes = parseDuration(first(props["totalTime"]))
+	}
+	if yield := props["recipeYield"]; len(yield) > 0 {
+		recipe.Servings = parseServings(yield[0])
+	}
+
+	lines := props["recipeIngredient"]
+	if len(lines) == 0 {
+		lines = props["ingredients"]
+	}
+	for _, line := range lines {
+		recipe.Ingredients = append(recipe.Ingredients, ParseIngredient(line))
+	}
+
+	// 步骤可以是多个 itemprop，也可以是一段包含多行的文本
+	for _, step := range props["recipeInstructions"] {
+		recipe.Steps = append(recipe.Steps, strings.Split(step, "\n")...)
+	}
+	return recipe
+}
+
+func isRecipeType(itemType string) bool {
+	for _, t := range strings.Fields(itemType) {
+		if strings.HasSuffix(t, "schema.org/Recipe") {
+			return true
+		}
+	}
+	return false
+}
+
+// itemProps 收集 scope 内属于它自己的属性，嵌套的 itemscope 作为一个整体取值
+func itemProps(scope *html.Node) map[string][]string {
+	props := make(map[string][]string)
+	for child := scope.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, func(n *html.Node) bool {
+			if n.Type != html.ElementNode {
+				return true
+			}
+			if names := strings.Fields(attr(n, "itemprop")); len(names) > 0 {
+				for _, name := range names {
+					if value := strings.TrimSpace(itemValue(n, name)); value != "" {
+						props[name] = append(props[name], value)
+					}
+				}
+			}
+			return !hasAttr(n, "itemscope")
+		})
+	}
+	return props
+}
+
+// itemValue 按 microdata 规则取属性值，嵌套的 HowToStep/ImageObject 取其中的 text/url
+func itemValue(n *html.Node, name string) string {
+	if hasAttr(n, "itemscope") {
+		nested := itemProps(n)
+		for _, key := range []string{"text", "url", "contentUrl"} {
+			if value := first(nested[key]); value != "" {
+				return value
+			}
+		}
+		if name == "image" {
+			return ""
+		}
+		return textContent(n)
+	}
+
+	switch n.Data {
+	case "meta":
+		return attr(n, "content")
+	case "img", "source", "video", "audio":
+		return attr(n, "src")
+	case "a", "link", "area":
+		return attr(n, "href")
+	case "time":
+		if datetime := attr(n, "datetime"); datetime != "" {
+			return datetime
+		}
+	case "data", "meter":
+		return attr(n, "value")
+	}
+	if content := attr(n, "content"); content != "" {
+		return content
+	}
+	return textContent(n)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,206 @@
+package recipe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Source 菜谱的解析来源
+type Source string
+
+const (
+	SourceJSONLD    Source = "json-ld"   // schema.org Recipe 的 JSON-LD 结构化数据
+	SourceMicrodata Source = "microdata" // schema.org Recipe 的 microdata 标注
+	SourceHeuristic Source = "heuristic" // 没有结构化数据时按页面标题和列表推测
+)
+
+const (
+	// DefaultTimeout 抓取页面的默认超时时间
+	DefaultTimeout = 10 * time.Second
+	// MaxPageSize 页面最多读取的字节数，超出部分忽略
+	MaxPageSize = 2 << 20
+)
+
+var (
+	// ErrInvalidURL 链接不是 http/https 地址
+	ErrInvalidURL = errors.New("无效的菜谱链接")
+	// ErrFetch 页面无法访问
+	ErrFetch = errors.New("获取菜谱页面失败")
+	// ErrNotFound 页面中没有识别到菜谱
+	ErrNotFound = errors.New("页面中没有识别到菜谱")
+	// ErrForbiddenAddress 链接指向本机、链路本地或内网地址
+	ErrForbiddenAddress = errors.New("不允许访问内网地址")
+)
+
+// Recipe 从页面中解析出的菜谱，时间为分钟，Servings 为 0 表示页面没有注明份数
+type Recipe struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	ImageURL    string       `json:"image_url"`
+	Ingredients []Ingredient `json:"ingredients"`
+	Steps       []string     `json:"steps"`
+	PrepMinutes int          `json:"prep_minutes"`
+	CookMinutes int          `json:"cook_minutes"`
+	Servings    int          `json:"servings"`
+	Source      Source       `json:"source"`
+}
+
+// Fetcher 抓取并解析菜谱页面
+type Fetcher struct {
+	client *http.Client
+	// allowLoopback 允许连接本机地址，仅用于测试
+	allowLoopback bool
+}
+
+// NewFetcher 创建抓取器，client 为空时使用带默认超时的客户端，
+// 该客户端在建立连接时拒绝本机、链路本地和内网地址（包括重定向和 DNS 解析后的地址）；
+// 传入的 client 由调用方自行负责访问限制
+func NewFetcher(client *http.Client) *Fetcher {
+	f := &Fetcher{client: client}
+	if f.client == nil {
+		f.client = f.defaultClient()
+	}
+	return f
+}
+
+// defaultClient 创建在拨号时检查目标地址的客户端，不使用环境变量中的代理，
+// 否则检查的是代理的地址而不是页面的地址
+func (f *Fetcher) defaultClient() *http.Client {
+	dialer := &net.Dialer{Timeout: DefaultTimeout, Control: f.checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: DefaultTimeout, Transport: transport}
+}
+
+// checkAddress 在连接前检查解析后的 IP，拒绝本机、链路本地、内网和未指定地址
+func (f *Fetcher) checkAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return ErrForbiddenAddress
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return ErrForbiddenAddress
+	case ip.IsLoopback():
+		if f.allowLoopback {
+			return nil
+		}
+		return ErrForbiddenAddress
+	case ip.IsPrivate(), ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast(), ip.IsMulticast(), ip.IsUnspecified():
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+// Fetch 抓取链接指向的页面并解析菜谱
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Recipe, error) {
+	pageURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") || pageURL.Host == "" {
+		return nil, ErrInvalidURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "foodcook-recipe-import/1.0")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrForbiddenAddress) {
+			return nil, ErrForbiddenAddress
+		}
+		return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: HTTP %d", ErrFetch, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+
+	// 以重定向后的地址为准解析相对链接
+	return Parse(body, resp.Request.URL)
+}
+
+// Parse 从 HTML 中解析菜谱，依次尝试 JSON-LD、microdata 和启发式解析，
+// pageURL 用于把图片的相对地址转换为绝对地址，可以为空
+func Parse(body []byte, pageURL *url.URL) (*Recipe, error) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+
+	for _, parse := range []func(*html.Node) *Recipe{parseJSONLD, parseMicrodata, parseHeuristic} {
+		recipe := parse(doc)
+		if recipe == nil || !recipe.usable() {
+			continue
+		}
+		recipe.clean(pageURL)
+		return recipe, nil
+	}
+	return nil, ErrNotFound
+}
+
+// usable 至少需要名称，以及食材或步骤之一
+func (r *Recipe) usable() bool {
+	return strings.TrimSpace(r.Name) != "" && (len(r.Ingredients) > 0 || len(r.Steps) > 0)
+}
+
+// clean 去掉多余空白和空行，并解析图片地址
+func (r *Recipe) clean(pageURL *url.URL) {
+	r.Name = collapseSpace(r.Name)
+	r.Description = collapseSpace(r.Description)
+	r.ImageURL = resolveURL(pageURL, strings.TrimSpace(r.ImageURL))
+
+	steps := make([]string, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		if step = collapseSpace(step); step != "" {
+			steps = append(steps, step)
+		}
+	}
+	r.Steps = steps
+
+	ingredients := make([]Ingredient, 0, len(r.Ingredients))
+	for _, ingredient := range r.Ingredients {
+		if ingredient.Name != "" {
+			ingredients = append(ingredients, ingredient)
+		}
+	}
+	r.Ingredients = ingredients
+}
+
+// resolveURL 把相对地址转换为绝对地址，无法解析时原样返回
+func resolveURL(base *url.URL, ref string) string {
+	if ref == "" || base == nil {
+		return ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
+
+// collapseSpace 合并连续空白并去掉首尾空白
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
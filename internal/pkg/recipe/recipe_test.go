@@ -0,0 +1,235 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("读取测试页面失败: %v", err)
+	}
+	return body
+}
+
+func TestParse(t *testing.T) {
+	pageURL, _ := url.Parse("https://recipes.example.com/dish/42")
+
+	tests := []struct {
+		fixture string
+		want    Recipe
+	}{
+		{
+			fixture: "jsonld.html",
+			want: Recipe{
+				Name:        "麻婆豆腐",
+				Description: `麻辣鲜香的"下饭菜"`,
+				ImageURL:    "https://recipes.example.com/images/mapo.jpg",
+				Ingredients: []Ingredient{
+					{Raw: "嫩豆腐 1块", Name: "嫩豆腐", Quantity: 1, Unit: "块"},
+					{Raw: "猪肉末 100克", Name: "猪肉末", Quantity: 100, Unit: "克"},
+					{Raw: "郫县豆瓣酱 2勺", Name: "郫县豆瓣酱", Quantity: 2, Unit: "勺"},
+					{Raw: "花椒粉 适量", Name: "花椒粉"},
+				},
+				Steps:       []string{"豆腐切块，焯水备用", "热锅炒香肉末和豆瓣酱", "加水烧开", "放入豆腐烧五分钟，勾芡出锅"},
+				PrepMinutes: 15,
+				CookMinutes: 65,
+				Servings:    3,
+				Source:      SourceJSONLD,
+			},
+		},
+		{
+			fixture: "microdata.html",
+			want: Recipe{
+				Name:        "番茄炒蛋",
+				Description: "家常快手菜",
+				ImageURL:    "https://img.example.com/tomato-egg.jpg",
+				Ingredients: []Ingredient{
+					{Raw: "番茄 2个", Name: "番茄", Quantity: 2, Unit: "个"},
+					{Raw: "鸡蛋 3个", Name: "鸡蛋", Quantity: 3, Unit: "个"},
+					{Raw: "盐 少许", Name: "盐"},
+				},
+				Steps:       []string{"鸡蛋打散炒熟盛出", "番茄炒出汁后倒入鸡蛋翻匀"},
+				PrepMinutes: 5,
+				CookMinutes: 10,
+				Servings:    2,
+				Source:      SourceMicrodata,
+			},
+		},
+		{
+			fixture: "heuristic.html",
+			want: Recipe{
+				Name:        "红烧肉",
+				Description: "肥而不腻的红烧肉",
+				ImageURL:    "https://recipes.example.com/upload/hongshaorou.jpg",
+				Ingredients: []Ingredient{
+					{Raw: "五花肉 500克", Name: "五花肉", Quantity: 500, Unit: "克"},
+					{Raw: "冰糖 30克", Name: "冰糖", Quantity: 30, Unit: "克"},
+					{Raw: "生抽 二勺", Name: "生抽", Quantity: 2, Unit: "勺"},
+				},
+				Steps:  []string{"五花肉切块焯水", "小火炒出糖色，放入五花肉翻炒上色", "加生抽和热水，小火炖一小时收汁"},
+				Source: SourceHeuristic,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := Parse(readFixture(t, tt.fixture), pageURL)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("解析结果不符\n got: %#v\nwant: %#v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNotFound(t *testing.T) {
+	body := []byte(`<html><head><title>关于我们</title></head><body><p>这里没有菜谱</p></body></html>`)
+	if _, err := Parse(body, nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("期望 ErrNotFound，实际为 %v", err)
+	}
+}
+
+// newTestFetcher 返回允许访问本机测试服务器的抓取器
+func newTestFetcher() *Fetcher {
+	f := NewFetcher(nil)
+	f.allowLoopback = true
+	return f
+}
+
+func TestFetch(t *testing.T) {
+	page := readFixture(t, "jsonld.html")
+	// 菜谱在页面末尾，前面的填充超过读取上限
+	oversized := append([]byte("<html><body>"+strings.Repeat("<p>广告</p>", MaxPageSize/len("<p>广告</p>")+1)), page...)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/recipe", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/recipes/new/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/recipes/new/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(page)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(oversized)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		path      string
+		wantErr   error
+		wantImage string
+	}{
+		{name: "正常页面", path: "/recipe", wantImage: server.URL + "/images/mapo.jpg"},
+		{name: "重定向后按新地址解析相对链接", path: "/old", wantImage: server.URL + "/images/mapo.jpg"},
+		{name: "非200状态", path: "/missing", wantErr: ErrFetch},
+		{name: "超出读取上限的部分被忽略", path: "/large", wantErr: ErrNotFound},
+	}
+
+	fetcher := newTestFetcher()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fetcher.Fetch(context.Background(), server.URL+tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("期望错误 %v，实际为 %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("抓取失败: %v", err)
+			}
+			if got.Name != "麻婆豆腐" || got.ImageURL != tt.wantImage {
+				t.Errorf("抓取结果不符: name=%q image=%q", got.Name, got.ImageURL)
+			}
+		})
+	}
+}
+
+func TestFetchInvalidURL(t *testing.T) {
+	for _, rawURL := range []string{"", "ftp://example.com/recipe", "http://", "not a url"} {
+		if _, err := NewFetcher(nil).Fetch(context.Background(), rawURL); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("%q: 期望 ErrInvalidURL，实际为 %v", rawURL, err)
+		}
+	}
+}
+
+func TestFetchForbiddenAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(readFixture(t, "jsonld.html"))
+	}))
+	defer server.Close()
+
+	// 公网页面重定向到本机地址同样被拒绝
+	redirector := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusFound))
+	defer redirector.Close()
+
+	fetcher := NewFetcher(nil)
+	for _, rawURL := range []string{
+		server.URL,
+		redirector.URL,
+		"http://localhost:1/recipe",
+		"http://10.0.0.1/recipe",
+		"http://192.168.1.1/recipe",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]:1/recipe",
+		"http://[fe80::1]/recipe",
+		"http://0.0.0.0/recipe",
+	} {
+		if _, err := fetcher.Fetch(context.Background(), rawURL); !errors.Is(err, ErrForbiddenAddress) {
+			t.Errorf("%s: 期望 ErrForbiddenAddress，实际为 %v", rawURL, err)
+		}
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	tests := []struct {
+		address       string
+		allowLoopback bool
+		wantErr       bool
+	}{
+		{address: "93.184.216.34:443"},
+		{address: "[2606:2800:220:1::1]:443"},
+		{address: "127.0.0.1:80", wantErr: true},
+		{address: "127.0.0.1:80", allowLoopback: true},
+		{address: "172.16.5.4:80", wantErr: true},
+		{address: "172.16.5.4:80", allowLoopback: true, wantErr: true},
+		{address: "[fd00::1]:80", wantErr: true},
+		{address: "[::ffff:10.0.0.1]:80", wantErr: true},
+		{address: "example.com:80", wantErr: true},
+	}
+	for _, tt := range tests {
+		f := &Fetcher{allowLoopback: tt.allowLoopback}
+		err := f.checkAddress("tcp", tt.address, nil)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s (allowLoopback=%v): 错误为 %v", tt.address, tt.allowLoopback, err)
+		}
+	}
+}
+
+func ExampleParseIngredient() {
+	fmt.Printf("%+v\n", ParseIngredient("猪肉末 200克"))
+	// Output: {Raw:猪肉末 200克 Name:猪肉末 Quantity:200 Unit:克}
+}
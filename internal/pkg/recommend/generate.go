@@ -0,0 +1,185 @@
+package recommend
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"foodcook/internal/domain/models"
+)
+
+// 生成菜单时的尝试次数上限，避免约束无解时长时间搜索
+const (
+	attemptsPerMenu = 20   // 每份菜单最多尝试的次数，重复的组合也计入
+	stepsPerAttempt = 2000 // 每次尝试最多考察的菜品数
+)
+
+// Slot 菜单中的一组菜，从 Candidates 中随机挑选 Count 道，如「2 个荤菜」
+type Slot struct {
+	Label      string
+	Count      int
+	Candidates []*models.Dish
+}
+
+// GenerateOptions 生成菜单的约束条件
+type GenerateOptions struct {
+	MaxPrice     float64 // 菜品价格合计的上限，0 表示不限
+	Alternatives int     // 最多生成的菜单数
+	Seed         int64
+}
+
+// MenuDish 菜单中的一道菜及其所属的组
+type MenuDish struct {
+	Slot string       `json:"slot"`
+	Dish *models.Dish `json:"dish"`
+}
+
+// GeneratedMenu 一份满足全部约束的菜单
+type GeneratedMenu struct {
+	Dishes     []MenuDish `json:"dishes"`
+	TotalPrice float64    `json:"total_price"`
+}
+
+// Generate 按组随机挑选菜品，同一道菜只出现一次，价格合计不超过上限。
+// 返回至多 Alternatives 份互不相同的菜单，同一种子的结果不变；约束无解或没有要挑选的菜时返回空
+func Generate(slots []Slot, opts GenerateOptions) []GeneratedMenu {
+	count := 0
+	for _, slot := range slots {
+		count += slot.Count
+	}
+	if count == 0 {
+		return nil
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	// 候选按ID排序后再打乱，保证结果与仓储返回的顺序无关
+	for i := range slots {
+		sorted := append([]*models.Dish(nil), slots[i].Candidates...)
+		sort.Slice(sorted, func(a, b int) bool { return sorted[a].ID < sorted[b].ID })
+		slots[i].Candidates = sorted
+	}
+	minRest := minPrices(slots)
+	// minAfter[i] 为第 i 组之后各组的最低价格合计
+	minAfter := make([]float64, len(slots))
+	for i := len(slots) - 2; i >= 0; i-- {
+		minAfter[i] = minAfter[i+1] + minRest[i+1][slots[i+1].Count]
+	}
+
+	var menus []GeneratedMenu
+	seen := make(map[string]bool)
+	for attempt := 0; attempt < opts.Alternatives*attemptsPerMenu && len(menus) < opts.Alternatives; attempt++ {
+		g := &generator{
+			slots:    slots,
+			minRest:  minRest,
+			minAfter: minAfter,
+			limit:    opts.MaxPrice,
+			rng:      rng,
+			used:     make(map[uint]bool),
+			steps:    stepsPerAttempt,
+		}
+		if !g.fill(0, 0, 0) {
+			// 随机顺序下都找不到解时，再试也不会成功
+			if g.steps > 0 {
+				break
+			}
+			continue
+		}
+
+		key := menuKey(g.picked)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		menu := GeneratedMenu{Dishes: g.picked}
+		for _, item := range g.picked {
+			menu.TotalPrice += item.Dish.Price
+		}
+		menu.TotalPrice = models.RoundPrice(menu.TotalPrice)
+		menus = append(menus, menu)
+	}
+	return menus
+}
+
+type generator struct {
+	slots    []Slot
+	minRest  [][]float64 // minRest[i][k] 为第 i 组最便宜的 k 道菜的价格合计
+	minAfter []float64
+	limit    float64
+	rng      *rand.Rand
+	used     map[uint]bool
+	picked   []MenuDish
+	steps    int
+}
+
+// fill 为第 slot 组挑选第 chosen+1 道菜，total 为已选菜品的价格合计
+func (g *generator) fill(slot, chosen int, total float64) bool {
+	if slot == len(g.slots) {
+		return true
+	}
+	if chosen == g.slots[slot].Count {
+		return g.fill(slot+1, 0, total)
+	}
+
+	candidates := g.slots[slot].Candidates
+	order := g.rng.Perm(len(candidates))
+	for _, i := range order {
+		if g.steps <= 0 {
+			return false
+		}
+		g.steps--
+
+		dish := candidates[i]
+		if g.used[dish.ID] {
+			continue
+		}
+		// 剩余各组即使都选最便宜的菜也会超出上限时跳过
+		rest := g.slots[slot].Count - chosen - 1
+		if g.limit > 0 && total+dish.Price+g.minRest[slot][rest]+g.minAfter[slot] > g.limit {
+			continue
+		}
+
+		g.used[dish.ID] = true
+		g.picked = append(g.picked, MenuDish{Slot: g.slots[slot].Label, Dish: dish})
+		if g.fill(slot, chosen+1, total+dish.Price) {
+			return true
+		}
+		g.picked = g.picked[:len(g.picked)-1]
+		delete(g.used, dish.ID)
+	}
+	return false
+}
+
+// minPrices 计算各组最便宜的若干道菜的价格合计，不考虑组之间的重复，作为剪枝的下界
+func minPrices(slots []Slot) [][]float64 {
+	result := make([][]float64, len(slots))
+	for i, slot := range slots {
+		prices := make([]float64, 0, len(slot.Candidates))
+		for _, dish := range slot.Candidates {
+			prices = append(prices, dish.Price)
+		}
+		sort.Float64s(prices)
+
+		sums := make([]float64, slot.Count+1)
+		for k := 1; k <= slot.Count && k <= len(prices); k++ {
+			sums[k] = sums[k-1] + prices[k-1]
+		}
+		result[i] = sums
+	}
+	return result
+}
+
+// menuKey 按菜品ID生成菜单的唯一标识，用于去重
+func menuKey(dishes []MenuDish) string {
+	ids := make([]int, 0, len(dishes))
+	for _, item := range dishes {
+		ids = append(ids, int(item.Dish.ID))
+	}
+	sort.Ints(ids)
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return strings.Join(parts, ",")
+}
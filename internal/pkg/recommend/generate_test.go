@@ -0,0 +1,153 @@
+package recommend
+
+import (
+	"reflect"
+	"testing"
+
+	"foodcook/internal/domain/models"
+)
+
+// testSlots 荤菜 5 道（价格 20-60）、素菜 4 道（价格 8-20）、汤 2 道（价格 15、25）
+func testSlots() []Slot {
+	meat := []*models.Dish{testDish(1, 1, 20), testDish(2, 1, 30), testDish(3, 1, 40), testDish(4, 1, 50), testDish(5, 1, 60)}
+	veg := []*models.Dish{testDish(6, 2, 8), testDish(7, 2, 12), testDish(8, 2, 16), testDish(9, 2, 20)}
+	soup := []*models.Dish{testDish(10, 3, 15), testDish(11, 3, 25)}
+	return []Slot{
+		{Label: "荤菜", Count: 2, Candidates: meat},
+		{Label: "素菜", Count: 1, Candidates: veg},
+		{Label: "汤", Count: 1, Candidates: soup},
+	}
+}
+
+func menuIDs(menus []GeneratedMenu) [][]uint {
+	result := make([][]uint, 0, len(menus))
+	for _, menu := range menus {
+		ids := make([]uint, 0, len(menu.Dishes))
+		for _, item := range menu.Dishes {
+			ids = append(ids, item.Dish.ID)
+		}
+		result = append(result, ids)
+	}
+	return result
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name      string
+		slots     func() []Slot
+		opts      GenerateOptions
+		wantCount int
+	}{
+		{name: "不限价格", slots: testSlots, opts: GenerateOptions{Alternatives: 5, Seed: 1}, wantCount: 5},
+		{name: "价格上限", slots: testSlots, opts: GenerateOptions{MaxPrice: 90, Alternatives: 5, Seed: 2}, wantCount: 5},
+		// 最便宜的组合 20+30+8+15=73，73 元以内只有这一种
+		{name: "价格上限只允许一种组合", slots: testSlots, opts: GenerateOptions{MaxPrice: 73, Alternatives: 3, Seed: 3}, wantCount: 1},
+		{name: "价格上限无解", slots: testSlots, opts: GenerateOptions{MaxPrice: 72, Alternatives: 3, Seed: 4}, wantCount: 0},
+		{
+			name: "候选不够凑齐一组",
+			slots: func() []Slot {
+				slots := testSlots()
+				slots[2].Count = 3
+				return slots
+			},
+			opts:      GenerateOptions{Alternatives: 3, Seed: 5},
+			wantCount: 0,
+		},
+		{
+			name: "同一道菜在多个组中只出现一次",
+			slots: func() []Slot {
+				dish := testDish(1, 1, 20)
+				return []Slot{
+					{Label: "荤菜", Count: 1, Candidates: []*models.Dish{dish}},
+					{Label: "招牌菜", Count: 1, Candidates: []*models.Dish{dish}},
+				}
+			},
+			opts:      GenerateOptions{Alternatives: 3, Seed: 6},
+			wantCount: 0,
+		},
+		{name: "没有组", slots: func() []Slot { return nil }, opts: GenerateOptions{Alternatives: 3, Seed: 7}, wantCount: 0},
+		{
+			name:      "各组数量为 0",
+			slots:     func() []Slot { return []Slot{{Label: "荤菜", Candidates: testSlots()[0].Candidates}} },
+			opts:      GenerateOptions{Alternatives: 3, Seed: 8},
+			wantCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slots := tt.slots()
+			menus := Generate(slots, tt.opts)
+			if len(menus) != tt.wantCount {
+				t.Fatalf("生成了 %d 份菜单，期望 %d: %v", len(menus), tt.wantCount, menuIDs(menus))
+			}
+
+			seen := make(map[string]bool)
+			for _, menu := range menus {
+				key := menuKey(menu.Dishes)
+				if seen[key] {
+					t.Errorf("菜单重复: %s", key)
+				}
+				seen[key] = true
+
+				var total float64
+				used := make(map[uint]bool)
+				counts := make(map[string]int)
+				for _, item := range menu.Dishes {
+					if used[item.Dish.ID] {
+						t.Errorf("菜单 %s 中菜品 %d 重复", key, item.Dish.ID)
+					}
+					used[item.Dish.ID] = true
+					counts[item.Slot]++
+					total += item.Dish.Price
+				}
+				if models.RoundPrice(total) != menu.TotalPrice {
+					t.Errorf("菜单 %s 的 TotalPrice %.2f 与合计 %.2f 不符", key, menu.TotalPrice, total)
+				}
+				if tt.opts.MaxPrice > 0 && menu.TotalPrice > tt.opts.MaxPrice {
+					t.Errorf("菜单 %s 价格 %.2f 超出上限 %.2f", key, menu.TotalPrice, tt.opts.MaxPrice)
+				}
+				for _, slot := range slots {
+					if counts[slot.Label] != slot.Count {
+						t.Errorf("菜单 %s 中「%s」有 %d 道，期望 %d", key, slot.Label, counts[slot.Label], slot.Count)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateSameSeed(t *testing.T) {
+	opts := GenerateOptions{MaxPrice: 100, Alternatives: 4, Seed: 42}
+	first := menuIDs(Generate(testSlots(), opts))
+
+	// 候选顺序不影响结果
+	reversed := testSlots()
+	for _, slot := range reversed {
+		for i, j := 0, len(slot.Candidates)-1; i < j; i, j = i+1, j-1 {
+			slot.Candidates[i], slot.Candidates[j] = slot.Candidates[j], slot.Candidates[i]
+		}
+	}
+	if got := menuIDs(Generate(reversed, opts)); !reflect.DeepEqual(got, first) {
+		t.Errorf("同一种子两次生成的菜单不同\n%v\n%v", first, got)
+	}
+}
+
+func TestGenerateExhaustsCombinations(t *testing.T) {
+	// 2 道荤菜 × 2 道素菜只有 4 种组合，要求 10 份时返回全部 4 份且不重复
+	slots := []Slot{
+		{Label: "荤菜", Count: 1, Candidates: []*models.Dish{testDish(1, 1, 20), testDish(2, 1, 30)}},
+		{Label: "素菜", Count: 1, Candidates: []*models.Dish{testDish(3, 2, 8), testDish(4, 2, 12)}},
+	}
+	menus := Generate(slots, GenerateOptions{Alternatives: 10, Seed: 9})
+	if len(menus) != 4 {
+		t.Fatalf("生成了 %d 份菜单，期望 4: %v", len(menus), menuIDs(menus))
+	}
+	seen := make(map[string]bool)
+	for _, menu := range menus {
+		seen[menuKey(menu.Dishes)] = true
+	}
+	if len(seen) != 4 {
+		t.Errorf("菜单有重复: %v", menuIDs(menus))
+	}
+}
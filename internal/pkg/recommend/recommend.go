@@ -0,0 +1,227 @@
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"foodcook/internal/domain/models"
+)
+
+// 打分权重。随机扰动保证同样的数据在不同种子下给出不同的组合，
+// 同一种子下结果不变
+const (
+	ratingWeight     = 0.5 // 平均评分每高于 3 星加的分
+	favoriteWeight   = 1.0
+	popularityWeight = 0.2 // 按吃过次数的对数加分
+	freshnessWeight  = 0.5 // 越久没吃加分越多，HistoryDays 天以上满分
+	stockWeight      = 1.0 // 食材全部有库存时加的分
+	jitterWeight     = 1.0
+	categoryPenalty  = 1.5 // 同一分类每多选一道菜扣的分
+)
+
+// Candidate 参与推荐的菜品及当前用户和家庭的相关数据
+type Candidate struct {
+	Dish           *models.Dish // 需要填充评分汇总
+	Favorite       bool         // 当前用户收藏了这道菜
+	DaysSinceEaten int          // 家庭上次吃这道菜距今的天数，HistoryDays 天内没吃过时为 -1
+	StockCoverage  float64      // 有库存的食材比例 0-1，没有食材数据时为 -1
+}
+
+// Options 推荐的约束条件
+type Options struct {
+	Count         int     // 推荐的菜品数
+	Budget        float64 // 菜品价格合计的上限，0 表示不限
+	AvoidDays     int     // 不推荐最近这么多天内吃过的菜
+	HistoryDays   int     // 查询用餐历史的天数，不小于 AvoidDays
+	PreferInStock bool    // 优先推荐食材有库存的菜品
+	Seed          int64
+}
+
+// Pick 推荐的一道菜及推荐理由
+type Pick struct {
+	Dish    *models.Dish `json:"dish"`
+	Score   float64      `json:"score"`
+	Reasons []string     `json:"reasons"`
+}
+
+// Menu 推荐结果
+type Menu struct {
+	Picks         []Pick
+	TotalPrice    float64
+	SkippedRecent int // 因最近吃过而排除的菜品数
+	OverBudget    int // 单价就超出预算而排除的菜品数
+}
+
+type scored struct {
+	Candidate
+	score   float64
+	reasons []string
+}
+
+// Recommend 按评分、收藏、吃过的次数、库存和随机扰动为菜品打分，
+// 再按分数依次挑选，同一分类重复时降低分数，并保证剩余预算够买其余的菜
+func Recommend(candidates []Candidate, opts Options) Menu {
+	var menu Menu
+	pool := make([]*scored, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.DaysSinceEaten >= 0 && candidate.DaysSinceEaten < opts.AvoidDays {
+			menu.SkippedRecent++
+			continue
+		}
+		if opts.Budget > 0 && candidate.Dish.Price > opts.Budget {
+			menu.OverBudget++
+			continue
+		}
+		pool = append(pool, &scored{Candidate: candidate})
+	}
+
+	// 按ID排序后再抽取随机数，保证同一种子的结果稳定
+	sort.Slice(pool, func(i, j int) bool { return pool[i].Dish.ID < pool[j].Dish.ID })
+	rng := rand.New(rand.NewSource(opts.Seed))
+	for _, item := range pool {
+		item.score, item.reasons = score(item.Candidate, opts)
+		item.score += rng.Float64() * jitterWeight
+	}
+
+	remaining := opts.Budget
+	categoryCounts := make(map[uint]int)
+	for len(menu.Picks) < opts.Count && len(pool) > 0 {
+		slotsLeft := opts.Count - len(menu.Picks)
+		best := -1
+		var bestScore float64
+		for i, item := range pool {
+			if opts.Budget > 0 && item.Dish.Price > remaining-cheapest(pool, i, slotsLeft-1) {
+				continue
+			}
+			effective := item.score - categoryPenalty*float64(categoryCounts[categoryOf(item.Dish)])
+			if best < 0 || effective > bestScore {
+				best, bestScore = i, effective
+			}
+		}
+		// 预算不够凑齐全部菜品时，退而选择剩余预算内买得起的菜
+		if best < 0 {
+			for i, item := range pool {
+				if item.Dish.Price <= remaining && (best < 0 || item.score > pool[best].score) {
+					best = i
+				}
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		item := pool[best]
+		pool = append(pool[:best], pool[best+1:]...)
+		category := categoryOf(item.Dish)
+		reasons := item.reasons
+		if len(menu.Picks) > 0 && categoryCounts[category] == 0 && item.Dish.Category != nil {
+			reasons = append(reasons, fmt.Sprintf("与已选的菜分类不同（%s）", item.Dish.Category.Name))
+		}
+		categoryCounts[category]++
+		remaining -= item.Dish.Price
+		menu.TotalPrice += item.Dish.Price
+		menu.Picks = append(menu.Picks, Pick{
+			Dish:    item.Dish,
+			Score:   math.Round(item.score*100) / 100,
+			Reasons: reasons,
+		})
+	}
+	menu.TotalPrice = models.RoundPrice(menu.TotalPrice)
+	return menu
+}
+
+// score 计算不含随机扰动的分数和推荐理由
+func score(c Candidate, opts Options) (float64, []string) {
+	var total float64
+	var reasons []string
+	dish := c.Dish
+
+	if dish.RatingCount > 0 {
+		total += (dish.AverageRating - 3) * ratingWeight
+		reasons = append(reasons, fmt.Sprintf("平均评分 %.1f 星（%d 条评价）", dish.AverageRating, dish.RatingCount))
+	}
+	if c.Favorite {
+		total += favoriteWeight
+		reasons = append(reasons, "在你的收藏中")
+	}
+
+	total += math.Log1p(float64(dish.TimesEaten)) * popularityWeight
+	switch {
+	case dish.TimesEaten == 0:
+		reasons = append(reasons, "还没有吃过，可以尝尝新菜")
+	case dish.TimesEaten >= 3:
+		reasons = append(reasons, fmt.Sprintf("吃过 %d 次，是家里常做的菜", dish.TimesEaten))
+	}
+
+	if c.DaysSinceEaten < 0 {
+		total += freshnessWeight
+		if dish.TimesEaten > 0 && opts.HistoryDays > 0 {
+			reasons = append(reasons, fmt.Sprintf("最近 %d 天没有吃过", opts.HistoryDays))
+		}
+	} else {
+		total += freshnessWeight * math.Min(float64(c.DaysSinceEaten)/float64(max(opts.HistoryDays, 1)), 1)
+		if c.DaysSinceEaten == 0 {
+			reasons = append(reasons, "今天吃过")
+		} else {
+			reasons = append(reasons, fmt.Sprintf("上次吃是 %d 天前", c.DaysSinceEaten))
+		}
+	}
+
+	if opts.PreferInStock && c.StockCoverage >= 0 {
+		total += c.StockCoverage * stockWeight
+		switch {
+		case c.StockCoverage >= 1:
+			reasons = append(reasons, "食材都有库存")
+		case c.StockCoverage > 0:
+			reasons = append(reasons, fmt.Sprintf("%.0f%% 的食材有库存", c.StockCoverage*100))
+		}
+	}
+	return total, reasons
+}
+
+// cheapest 返回除 pool[skip] 外最便宜的 n 道菜的价格合计
+func cheapest(pool []*scored, skip, n int) float64 {
+	if n <= 0 {
+		return 0
+	}
+	prices := make([]float64, 0, len(pool))
+	for i, item := range pool {
+		if i != skip {
+			prices = append(prices, item.Dish.Price)
+		}
+	}
+	sort.Float64s(prices)
+	var sum float64
+	for i := 0; i < n && i < len(prices); i++ {
+		sum += prices[i]
+	}
+	return sum
+}
+
+func categoryOf(dish *models.Dish) uint {
+	if dish.CategoryID == nil {
+		return 0
+	}
+	return *dish.CategoryID
+}
+
+// StockCoverage 按 servings 份的用量计算有足够库存的食材比例，需要预加载食材。
+// 菜品没有食材时返回 -1
+func StockCoverage(dish *models.Dish, servings int, stock map[uint]float64) float64 {
+	if len(dish.Ingredients) == 0 {
+		return -1
+	}
+	factor := 1.0
+	if servings > 0 && dish.Servings > 0 {
+		factor = float64(servings) / float64(dish.Servings)
+	}
+	covered := 0
+	for _, di := range dish.Ingredients {
+		if stock[di.IngredientID] >= models.RoundQuantity(di.Quantity*factor) {
+			covered++
+		}
+	}
+	return float64(covered) / float64(len(dish.Ingredients))
+}
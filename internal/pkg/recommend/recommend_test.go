@@ -0,0 +1,215 @@
+package recommend
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"foodcook/internal/domain/models"
+)
+
+// testDish 创建指定分类和价格的菜品，categoryID 为 0 表示未分类
+func testDish(id uint, categoryID uint, price float64) *models.Dish {
+	dish := &models.Dish{ID: id, Name: "菜品", Price: price}
+	if categoryID > 0 {
+		dish.CategoryID = &categoryID
+		dish.Category = &models.Category{ID: categoryID, Name: "分类"}
+	}
+	return dish
+}
+
+// testCandidates 三个分类各 4 道菜，价格 10-120，都没有最近吃过的记录
+func testCandidates() []Candidate {
+	var candidates []Candidate
+	for id := uint(1); id <= 12; id++ {
+		candidates = append(candidates, Candidate{
+			Dish:           testDish(id, (id-1)%3+1, float64(id*10)),
+			DaysSinceEaten: -1,
+			StockCoverage:  -1,
+		})
+	}
+	return candidates
+}
+
+func pickedIDs(menu Menu) []uint {
+	ids := make([]uint, 0, len(menu.Picks))
+	for _, pick := range menu.Picks {
+		ids = append(ids, pick.Dish.ID)
+	}
+	return ids
+}
+
+func TestRecommendSameSeed(t *testing.T) {
+	for _, seed := range []int64{1, 42, 20240601} {
+		opts := Options{Count: 4, Seed: seed}
+		first := pickedIDs(Recommend(testCandidates(), opts))
+
+		// 候选顺序不影响结果
+		reversed := testCandidates()
+		for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+			reversed[i], reversed[j] = reversed[j], reversed[i]
+		}
+		for _, candidates := range [][]Candidate{testCandidates(), reversed} {
+			if got := pickedIDs(Recommend(candidates, opts)); !reflect.DeepEqual(got, first) {
+				t.Errorf("种子 %d: 两次推荐结果不同 %v / %v", seed, first, got)
+			}
+		}
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates func() []Candidate
+		opts       Options
+		check      func(t *testing.T, menu Menu)
+	}{
+		{
+			name: "排除最近吃过的菜",
+			candidates: func() []Candidate {
+				candidates := testCandidates()
+				candidates[0].DaysSinceEaten = 0  // 今天吃过
+				candidates[1].DaysSinceEaten = 2  // 避开天数内
+				candidates[2].DaysSinceEaten = 3  // 刚好超过避开天数
+				candidates[3].DaysSinceEaten = 10 // 很久以前
+				return candidates
+			},
+			opts: Options{Count: 12, AvoidDays: 3, HistoryDays: 14, Seed: 7},
+			check: func(t *testing.T, menu Menu) {
+				if menu.SkippedRecent != 2 {
+					t.Errorf("SkippedRecent = %d，期望 2", menu.SkippedRecent)
+				}
+				for _, id := range pickedIDs(menu) {
+					if id == 1 || id == 2 {
+						t.Errorf("推荐了最近吃过的菜 %d", id)
+					}
+				}
+				if len(menu.Picks) != 10 {
+					t.Errorf("推荐了 %d 道菜，期望 10", len(menu.Picks))
+				}
+			},
+		},
+		{
+			name:       "不超过预算",
+			candidates: testCandidates,
+			opts:       Options{Count: 3, Budget: 100, Seed: 3},
+			check: func(t *testing.T, menu Menu) {
+				var total float64
+				for _, pick := range menu.Picks {
+					total += pick.Dish.Price
+				}
+				if len(menu.Picks) != 3 {
+					t.Errorf("推荐了 %d 道菜，期望 3", len(menu.Picks))
+				}
+				if total > 100 || menu.TotalPrice != total {
+					t.Errorf("价格合计 %.2f（TotalPrice %.2f）超出预算 100", total, menu.TotalPrice)
+				}
+				// 单价 110、120 的菜超出预算
+				if menu.OverBudget != 2 {
+					t.Errorf("OverBudget = %d，期望 2", menu.OverBudget)
+				}
+			},
+		},
+		{
+			name:       "预算不够凑齐时只选买得起的菜",
+			candidates: testCandidates,
+			opts:       Options{Count: 4, Budget: 50, Seed: 5},
+			check: func(t *testing.T, menu Menu) {
+				if menu.TotalPrice > 50 {
+					t.Errorf("价格合计 %.2f 超出预算 50", menu.TotalPrice)
+				}
+				if len(menu.Picks) == 0 || len(menu.Picks) >= 4 {
+					t.Errorf("推荐了 %d 道菜，期望 1-3 道", len(menu.Picks))
+				}
+			},
+		},
+		{
+			name:       "分类交错",
+			candidates: testCandidates,
+			opts:       Options{Count: 3, Seed: 11},
+			check: func(t *testing.T, menu Menu) {
+				categories := make(map[uint]bool)
+				for _, pick := range menu.Picks {
+					categories[*pick.Dish.CategoryID] = true
+				}
+				if len(categories) != 3 {
+					t.Errorf("3 道菜来自 %d 个分类，期望各不相同: %v", len(categories), pickedIDs(menu))
+				}
+			},
+		},
+		{
+			name: "收藏和库存优先",
+			candidates: func() []Candidate {
+				candidates := testCandidates()
+				candidates[4].Favorite = true
+				candidates[4].StockCoverage = 1
+				return candidates
+			},
+			opts: Options{Count: 1, PreferInStock: true, Seed: 9},
+			check: func(t *testing.T, menu Menu) {
+				if ids := pickedIDs(menu); !reflect.DeepEqual(ids, []uint{5}) {
+					t.Fatalf("推荐了 %v，期望 [5]", ids)
+				}
+				reasons := menu.Picks[0].Reasons
+				if !contains(reasons, "在你的收藏中") || !contains(reasons, "食材都有库存") {
+					t.Errorf("推荐理由缺少收藏或库存: %v", reasons)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, Recommend(tt.candidates(), tt.opts))
+		})
+	}
+}
+
+func TestRecommendSeedsDiffer(t *testing.T) {
+	// 分数相同的候选只靠随机扰动区分，不同种子应给出不同的组合
+	distinct := make(map[string]bool)
+	for seed := int64(0); seed < 20; seed++ {
+		ids := pickedIDs(Recommend(testCandidates(), Options{Count: 3, Seed: seed}))
+		distinct[fmt.Sprint(ids)] = true
+	}
+	if len(distinct) < 2 {
+		t.Errorf("20 个种子只给出了 %d 种组合", len(distinct))
+	}
+}
+
+func TestStockCoverage(t *testing.T) {
+	dish := &models.Dish{
+		Servings: 2,
+		Ingredients: []models.DishIngredient{
+			{IngredientID: 1, Quantity: 300},
+			{IngredientID: 2, Quantity: 0.5},
+		},
+	}
+	tests := []struct {
+		servings int
+		stock    map[uint]float64
+		want     float64
+	}{
+		{servings: 2, stock: map[uint]float64{1: 300, 2: 0.5}, want: 1},
+		{servings: 4, stock: map[uint]float64{1: 300, 2: 1}, want: 0.5},
+		{servings: 1, stock: map[uint]float64{1: 150}, want: 0.5},
+		{servings: 2, stock: nil, want: 0},
+	}
+	for _, tt := range tests {
+		if got := StockCoverage(dish, tt.servings, tt.stock); got != tt.want {
+			t.Errorf("%d 份, 库存 %v: StockCoverage = %v，期望 %v", tt.servings, tt.stock, got, tt.want)
+		}
+	}
+	if got := StockCoverage(&models.Dish{}, 1, nil); got != -1 {
+		t.Errorf("没有食材时 StockCoverage = %v，期望 -1", got)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif" // 注册 GIF 解码器
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // 注册 WebP 解码器
+)
+
+// ExtensionFor 返回 MIME 类型对应的文件扩展名
+func ExtensionFor(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+	return ""
+}
+
+// Thumbnail 按最大宽度等比缩放图片，返回缩略图数据、缩略图类型以及原图尺寸。
+// PNG 保留透明通道，其他格式统一输出 JPEG
+func Thumbnail(data []byte, maxWidth int) ([]byte, string, image.Point, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", image.Point{}, err
+	}
+
+	size := src.Bounds().Size()
+	width, height := size.X, size.Y
+	if width > maxWidth {
+		height = height * maxWidth / width
+		width = maxWidth
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+	var buf bytes.Buffer
+	if format == "png" {
+		err = png.Encode(&buf, dst)
+		return buf.Bytes(), "image/png", size, err
+	}
+	err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+	return buf.Bytes(), "image/jpeg", size, err
+}
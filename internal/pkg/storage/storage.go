@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo 存储对象的元信息
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage 文件存储接口，本地磁盘和 S3 兼容存储（包括 MinIO）都实现该接口。
+// key 为 / 分隔的相对路径，例如 2024/01/abc.jpg
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 返回对象内容，调用方负责关闭。对象不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	Backend() string
+}
@@ -0,0 +1,211 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind 单位的量纲
+type Kind string
+
+const (
+	Mass   Kind = "mass"   // 质量，基准单位为克
+	Volume Kind = "volume" // 体积，基准单位为毫升
+	Count  Kind = "count"  // 计数，不同计数单位之间不能直接换算
+)
+
+var (
+	// ErrUnknownUnit 单位未注册
+	ErrUnknownUnit = errors.New("未知的单位")
+	// ErrIncompatible 两个单位之间没有换算关系
+	ErrIncompatible = errors.New("单位无法换算")
+)
+
+// Unit 已注册的单位，Factor 为一个该单位等于多少基准单位，计数单位为 1
+type Unit struct {
+	Name   string  `json:"name"`
+	Kind   Kind    `json:"kind"`
+	Factor float64 `json:"factor"`
+}
+
+// Conversion 食材特有的换算关系：1 Unit 约等于 Quantity 个 Target，
+// 例如一块豆腐约 300 克为 {Unit: "块", Quantity: 300, Target: "克"}
+type Conversion struct {
+	Unit     string
+	Quantity float64
+	Target   string
+}
+
+var registry = map[string]Unit{}
+
+// aliases 常见的别名和英文缩写
+var aliases = map[string]string{
+	"g":    "克",
+	"kg":   "千克",
+	"公斤":   "千克",
+	"ml":   "毫升",
+	"l":    "升",
+	"L":    "升",
+	"汤勺":   "勺",
+	"汤匙":   "勺",
+	"大勺":   "勺",
+	"小勺":   "茶匙",
+	"tbsp": "勺",
+	"tsp":  "茶匙",
+}
+
+func init() {
+	register(Mass, map[string]float64{
+		"克":  1,
+		"千克": 1000,
+		"斤":  500,
+		"两":  50,
+	})
+	register(Volume, map[string]float64{
+		"毫升": 1,
+		"升":  1000,
+		"勺":  15,
+		"茶匙": 5,
+		"杯":  250,
+		"碗":  300,
+	})
+	register(Count, map[string]float64{
+		"个": 1, "块": 1, "只": 1, "根": 1, "颗": 1, "片": 1, "把": 1, "瓣": 1,
+		"条": 1, "份": 1, "盒": 1, "袋": 1, "包": 1, "瓶": 1, "罐": 1, "棵": 1, "头": 1,
+	})
+}
+
+func register(kind Kind, factors map[string]float64) {
+	for name, factor := range factors {
+		registry[name] = Unit{Name: name, Kind: kind, Factor: factor}
+	}
+}
+
+// Lookup 按名称或别名查找单位
+func Lookup(name string) (Unit, bool) {
+	name = strings.TrimSpace(name)
+	if canonical, ok := aliases[name]; ok {
+		name = canonical
+	}
+	unit, ok := registry[name]
+	return unit, ok
+}
+
+// Normalize 返回单位的规范名称，例如 kg 返回 千克
+func Normalize(name string) (string, error) {
+	unit, ok := Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownUnit, name)
+	}
+	return unit.Name, nil
+}
+
+// All 返回全部已注册的单位，按量纲和大小排序
+func All() []Unit {
+	order := map[Kind]int{Mass: 0, Volume: 1, Count: 2}
+	list := make([]Unit, 0, len(registry))
+	for _, unit := range registry {
+		list = append(list, unit)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return order[list[i].Kind] < order[list[j].Kind]
+		}
+		if list[i].Factor != list[j].Factor {
+			return list[i].Factor < list[j].Factor
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Prefix 返回 s 开头最长的单位名称或别名及其字节长度，没有匹配时长度为 0。
+// 英文缩写后面紧跟字母时不算匹配，避免把 large 识别为 l
+func Prefix(s string) (string, int) {
+	best := ""
+	for name := range registry {
+		if len(name) > len(best) && strings.HasPrefix(s, name) {
+			best = name
+		}
+	}
+	for alias := range aliases {
+		if len(alias) <= len(best) || !strings.HasPrefix(s, alias) {
+			continue
+		}
+		if alias[0] < utf8.RuneSelf && len(s) > len(alias) && isLetter(s[len(alias)]) {
+			continue
+		}
+		best = alias
+	}
+	if best == "" {
+		return "", 0
+	}
+	unit, _ := Lookup(best)
+	return unit.Name, len(best)
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// dimension 同一量纲内的单位可以直接换算，每个计数单位自成一类
+func (u Unit) dimension() string {
+	if u.Kind == Count {
+		return string(Count) + ":" + u.Name
+	}
+	return string(u.Kind)
+}
+
+// Convert 把 quantity 从 from 单位换算为 to 单位。同一量纲内直接换算，
+// 跨量纲（如 块 → 克、勺 → 克）需要通过 conversions 中的换算关系，可以串联多个
+func Convert(quantity float64, from, to string, conversions []Conversion) (float64, error) {
+	source, ok := Lookup(from)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownUnit, from)
+	}
+	target, ok := Lookup(to)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownUnit, to)
+	}
+
+	// 每条换算关系连接两个量纲，两个方向都可以换算
+	type edge struct {
+		to    string
+		ratio float64
+	}
+	graph := make(map[string][]edge)
+	for _, conv := range conversions {
+		a, okA := Lookup(conv.Unit)
+		b, okB := Lookup(conv.Target)
+		if !okA || !okB || conv.Quantity <= 0 {
+			continue
+		}
+		ratio := conv.Quantity * b.Factor / a.Factor
+		graph[a.dimension()] = append(graph[a.dimension()], edge{to: b.dimension(), ratio: ratio})
+		graph[b.dimension()] = append(graph[b.dimension()], edge{to: a.dimension(), ratio: 1 / ratio})
+	}
+
+	// ratios[d] 为源量纲的一个基准单位等于多少 d 量纲的基准单位，按换算关系广度优先搜索
+	ratios := map[string]float64{source.dimension(): 1}
+	queue := []string{source.dimension()}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, e := range graph[current] {
+			if _, seen := ratios[e.to]; seen {
+				continue
+			}
+			ratios[e.to] = ratios[current] * e.ratio
+			queue = append(queue, e.to)
+		}
+	}
+
+	ratio, ok := ratios[target.dimension()]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s → %s", ErrIncompatible, source.Name, target.Name)
+	}
+	return quantity * source.Factor * ratio / target.Factor, nil
+}
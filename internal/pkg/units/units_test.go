@@ -0,0 +1,126 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	// 一块豆腐约 300 克，一盒 2 块；一勺酱油约 18 克
+	conversions := []Conversion{
+		{Unit: "块", Quantity: 300, Target: "克"},
+		{Unit: "盒", Quantity: 2, Target: "块"},
+		{Unit: "勺", Quantity: 18, Target: "克"},
+	}
+
+	tests := []struct {
+		name        string
+		quantity    float64
+		from, to    string
+		conversions []Conversion
+		want        float64
+		wantErr     error
+	}{
+		{name: "斤换算为克", quantity: 0.3, from: "斤", to: "克", want: 150},
+		{name: "克换算为斤", quantity: 250, from: "克", to: "斤", want: 0.5},
+		{name: "两换算为斤", quantity: 10, from: "两", to: "斤", want: 1},
+		{name: "斤换算为两", quantity: 1.5, from: "斤", to: "两", want: 15},
+		{name: "千克换算为两", quantity: 1, from: "千克", to: "两", want: 20},
+		{name: "相同单位", quantity: 3, from: "克", to: "克", want: 3},
+		{name: "别名", quantity: 2, from: "kg", to: "g", want: 2000},
+		{name: "别名前后有空格", quantity: 1, from: " 公斤 ", to: "斤", want: 2},
+		{name: "体积单位", quantity: 2, from: "勺", to: "毫升", want: 30},
+		{name: "块通过换算关系换算为克", quantity: 2, from: "块", to: "克", conversions: conversions, want: 600},
+		{name: "块通过换算关系换算为斤", quantity: 1, from: "块", to: "斤", conversions: conversions, want: 0.6},
+		{name: "换算关系反向使用", quantity: 150, from: "克", to: "块", conversions: conversions, want: 0.5},
+		{name: "换算关系串联", quantity: 1, from: "盒", to: "两", conversions: conversions, want: 12},
+		{name: "体积通过换算关系换算为质量", quantity: 1, from: "茶匙", to: "克", conversions: conversions, want: 6},
+		{name: "质量和体积没有换算关系", quantity: 1, from: "克", to: "毫升", wantErr: ErrIncompatible},
+		{name: "只有其他单位的换算关系", quantity: 1, from: "斤", to: "杯", conversions: conversions[:2], wantErr: ErrIncompatible},
+		{name: "不同计数单位", quantity: 1, from: "个", to: "块", wantErr: ErrIncompatible},
+		{
+			name: "数量不为正的换算关系被忽略", quantity: 1, from: "根", to: "克",
+			conversions: []Conversion{{Unit: "根", Quantity: 0, Target: "克"}}, wantErr: ErrIncompatible,
+		},
+		{
+			name: "未知单位的换算关系被忽略", quantity: 1, from: "根", to: "克",
+			conversions: []Conversion{{Unit: "根", Quantity: 2, Target: "磅"}}, wantErr: ErrIncompatible,
+		},
+		{name: "未知的源单位", quantity: 1, from: "磅", to: "克", wantErr: ErrUnknownUnit},
+		{name: "未知的目标单位", quantity: 1, from: "克", to: "盎司", wantErr: ErrUnknownUnit},
+		{name: "空单位", quantity: 1, from: "", to: "克", wantErr: ErrUnknownUnit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.quantity, tt.from, tt.to, tt.conversions)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("期望错误 %v，实际为 %v（结果 %v）", tt.wantErr, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("换算失败: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("%v %s → %s = %v，期望 %v", tt.quantity, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "克", want: "克"},
+		{name: "g", want: "克"},
+		{name: "kg", want: "千克"},
+		{name: "公斤", want: "千克"},
+		{name: "L", want: "升"},
+		{name: "汤匙", want: "勺"},
+		{name: "tsp", want: "茶匙"},
+		{name: " 斤 ", want: "斤"},
+		{name: "磅", wantErr: true},
+		{name: "KG", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.name)
+		if tt.wantErr {
+			if !errors.Is(err, ErrUnknownUnit) {
+				t.Errorf("%q: 期望 ErrUnknownUnit，实际为 %q, %v", tt.name, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%q: 规范名称为 %q, %v，期望 %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	tests := []struct {
+		s        string
+		wantName string
+		wantLen  int
+	}{
+		{s: "克猪肉", wantName: "克", wantLen: len("克")},
+		{s: "千克牛肉", wantName: "千克", wantLen: len("千克")},
+		{s: "汤匙生抽", wantName: "勺", wantLen: len("汤匙")},
+		{s: "kg 鸡腿", wantName: "千克", wantLen: 2},
+		{s: "ml", wantName: "毫升", wantLen: 2},
+		{s: "large eggs", wantLen: 0},
+		{s: "猪肉", wantLen: 0},
+	}
+	for _, tt := range tests {
+		name, n := Prefix(tt.s)
+		if name != tt.wantName || n != tt.wantLen {
+			t.Errorf("Prefix(%q) = %q, %d，期望 %q, %d", tt.s, name, n, tt.wantName, tt.wantLen)
+		}
+	}
+}
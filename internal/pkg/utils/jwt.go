@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -10,29 +14,45 @@ import (
 )
 
 type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
+	UserID      uint   `json:"user_id"`
+	Username    string `json:"username"`
+	FamilyID    string `json:"fid"`           // 所属刷新令牌家族，用于整体吊销
+	HouseholdID uint   `json:"hid,omitempty"` // 当前切换到的家庭
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint, username string) (string, error) {
+// GenerateAccessToken 生成短期访问令牌，jti 用于单独吊销
+func GenerateAccessToken(userID uint, username, familyID string, householdID uint) (string, *Claims, error) {
 	cfg := config.GetConfig()
 	if cfg == nil {
-		return "", errors.New("config not loaded")
+		return "", nil, errors.New("config not loaded")
 	}
 
-	claims := Claims{
-		UserID:   userID,
-		Username: username,
+	jti, err := NewTokenID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		UserID:      userID,
+		Username:    username,
+		FamilyID:    familyID,
+		HouseholdID: householdID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(cfg.JWT.ExpireHours) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL())),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.JWT.Secret))
+	signed, err := token.SignedString([]byte(cfg.JWT.Secret))
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
 }
 
 func ParseToken(tokenString string) (*Claims, error) {
@@ -43,7 +63,7 @@ func ParseToken(tokenString string) (*Claims, error) {
 
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(cfg.JWT.Secret), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
@@ -55,3 +75,56 @@ func ParseToken(tokenString string) (*Claims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+// AccessTokenTTL 访问令牌有效期
+func AccessTokenTTL() time.Duration {
+	cfg := config.GetConfig()
+	if cfg == nil || cfg.JWT.AccessExpireMinutes <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(cfg.JWT.AccessExpireMinutes) * time.Minute
+}
+
+// RefreshTokenTTL 刷新令牌有效期
+func RefreshTokenTTL() time.Duration {
+	cfg := config.GetConfig()
+	if cfg == nil || cfg.JWT.RefreshExpireHours <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(cfg.JWT.RefreshExpireHours) * time.Hour
+}
+
+// GenerateRefreshToken 生成不透明的刷新令牌，返回明文和用于存储的哈希
+func GenerateRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken 计算刷新令牌的 SHA-256 哈希
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewTokenID 生成随机的令牌ID
+func NewTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// TokenRevocationKey 单个访问令牌的吊销键
+func TokenRevocationKey(jti string) string {
+	return "jti:" + jti
+}
+
+// FamilyRevocationKey 令牌家族的吊销键
+func FamilyRevocationKey(familyID string) string {
+	return "family:" + familyID
+}
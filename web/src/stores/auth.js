@@ -18,6 +18,7 @@ export const useAuthStore = defineStore('auth', () => {
       user.value = response.user
       token.value = response.token
       localStorage.setItem('token', response.token)
+      localStorage.setItem('refresh_token', response.refresh_token)
       localStorage.setItem('user', JSON.stringify(response.user))
       ElMessage.success('登录成功')
       return response
@@ -33,6 +34,7 @@ export const useAuthStore = defineStore('auth', () => {
       user.value = response.user
       token.value = response.token
       localStorage.setItem('token', response.token)
+      localStorage.setItem('refresh_token', response.refresh_token)
       localStorage.setItem('user', JSON.stringify(response.user))
       ElMessage.success('注册成功')
       return response
@@ -54,10 +56,16 @@ export const useAuthStore = defineStore('auth', () => {
   }
 
   // 登出
-  const logout = () => {
+  const logout = async () => {
+    try {
+      await authAPI.logout()
+    } catch (error) {
+      // 令牌已失效时忽略，本地状态照常清理
+    }
     user.value = null
     token.value = ''
     localStorage.removeItem('token')
+    localStorage.removeItem('refresh_token')
     localStorage.removeItem('user')
     ElMessage.success('已退出登录')
   }
@@ -25,13 +25,46 @@ api.interceptors.request.use(
   }
 )
 
+// 使用刷新令牌换取新的访问令牌，并发请求共用同一次刷新
+let refreshPromise = null
+const refreshAccessToken = () => {
+  if (!refreshPromise) {
+    const refreshToken = localStorage.getItem('refresh_token')
+    refreshPromise = (refreshToken
+      ? axios.post('/api/auth/refresh', { refresh_token: refreshToken })
+      : Promise.reject(new Error('no refresh token'))
+    )
+      .then(({ data }) => {
+        localStorage.setItem('token', data.token)
+        localStorage.setItem('refresh_token', data.refresh_token)
+        return data.token
+      })
+      .finally(() => {
+        refreshPromise = null
+      })
+  }
+  return refreshPromise
+}
+
 // 响应拦截器
 api.interceptors.response.use(
   (response) => {
     return response.data
   },
-  (error) => {
-    const { response } = error
+  async (error) => {
+    const { response, config } = error
+
+    // 访问令牌过期时尝试刷新一次
+    if (response?.status === 401 && config && !config._retried && !config.url.startsWith('/auth/')) {
+      config._retried = true
+      try {
+        const token = await refreshAccessToken()
+        config.headers.Authorization = `Bearer ${token}`
+        return api(config)
+      } catch (e) {
+        // 刷新失败，按登录过期处理
+      }
+    }
     
     if (response) {
       const { status, data } = response
@@ -40,6 +73,7 @@ api.interceptors.response.use(
         case 401:
           ElMessage.error('登录已过期，请重新登录')
           localStorage.removeItem('token')
+          localStorage.removeItem('refresh_token')
           localStorage.removeItem('user')
           window.location.href = '/login'
           break
@@ -72,7 +106,13 @@ export const authAPI = {
   login: (data) => api.post('/auth/login', data),
   
   // 获取用户信息
-  getProfile: () => api.get('/auth/profile')
+  getProfile: () => api.get('/auth/profile'),
+
+  // 退出登录
+  logout: () => api.post('/auth/logout'),
+
+  // 退出所有设备
+  logoutAll: () => api.post('/auth/logout-all')
 }
 
 export const dishesAPI = {